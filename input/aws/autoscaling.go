@@ -0,0 +1,138 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package aws
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/elastic/assetbeat/input/internal"
+	stateless "github.com/elastic/beats/v7/filebeat/input/v2/input-stateless"
+
+	"github.com/elastic/elastic-agent-libs/logp"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	"github.com/aws/aws-sdk-go-v2/service/autoscaling"
+	"github.com/aws/aws-sdk-go-v2/service/autoscaling/types"
+)
+
+func collectAutoScalingAssets(ctx context.Context, client autoscaling.DescribeAutoScalingGroupsAPIClient, region string, log *logp.Logger, publisher stateless.Publisher) error {
+	groups, err := describeAutoScalingGroups(ctx, client)
+	if err != nil {
+		return err
+	}
+
+	assetType := "aws.autoscaling.group"
+	assetKind := "host_group"
+	for _, group := range groups {
+		var parents []string
+		for _, subnetID := range getAutoScalingGroupSubnets(group) {
+			parents = append(parents, "network:"+subnetID)
+		}
+		var children []string
+		for _, instance := range group.Instances {
+			children = append(children, "host:"+*instance.InstanceId)
+		}
+
+		groupARN, _ := arn.Parse(*group.AutoScalingGroupARN)
+		options := []internal.AssetOption{
+			internal.WithAssetCloudProvider("aws"),
+			internal.WithAssetRegion(region),
+			internal.WithAssetAccountID(groupARN.AccountID),
+			internal.WithAssetKindAndID(assetKind, *group.AutoScalingGroupARN),
+			internal.WithAssetName(*group.AutoScalingGroupName),
+			internal.WithAssetType(assetType),
+			WithAssetTags(flattenAutoScalingTags(group.Tags)),
+			internal.WithAssetMetadata(getAutoScalingGroupMetadata(group)),
+		}
+		if parents != nil {
+			options = append(options, internal.WithAssetParents(parents))
+		}
+		if children != nil {
+			options = append(options, internal.WithAssetChildren(children))
+		}
+		internal.Publish(publisher, nil,
+			options...,
+		)
+	}
+
+	return nil
+}
+
+func describeAutoScalingGroups(ctx context.Context, client autoscaling.DescribeAutoScalingGroupsAPIClient) ([]types.AutoScalingGroup, error) {
+	groups := make([]types.AutoScalingGroup, 0, 100)
+	paginator := autoscaling.NewDescribeAutoScalingGroupsPaginator(client, &autoscaling.DescribeAutoScalingGroupsInput{})
+	for paginator.HasMorePages() {
+		resp, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error describing Autoscaling groups: %w", err)
+		}
+
+		groups = append(groups, resp.AutoScalingGroups...)
+	}
+
+	return groups, nil
+}
+
+func getAutoScalingGroupMetadata(group types.AutoScalingGroup) mapstr.M {
+	metadata := mapstr.M{
+		"min_size":          aws.ToInt32(group.MinSize),
+		"max_size":          aws.ToInt32(group.MaxSize),
+		"desired_capacity":  aws.ToInt32(group.DesiredCapacity),
+		"health_check_type": aws.ToString(group.HealthCheckType),
+	}
+	if group.LaunchConfigurationName != nil {
+		metadata["launch_configuration_name"] = *group.LaunchConfigurationName
+	}
+	launchTemplate := group.LaunchTemplate
+	if launchTemplate == nil && group.MixedInstancesPolicy != nil && group.MixedInstancesPolicy.LaunchTemplate != nil {
+		launchTemplate = group.MixedInstancesPolicy.LaunchTemplate.LaunchTemplateSpecification
+	}
+	if launchTemplate != nil {
+		metadata["launch_template"] = mapstr.M{
+			"id":      aws.ToString(launchTemplate.LaunchTemplateId),
+			"name":    aws.ToString(launchTemplate.LaunchTemplateName),
+			"version": aws.ToString(launchTemplate.Version),
+		}
+	}
+	return metadata
+}
+
+// getAutoScalingGroupSubnets returns the IDs of the subnets the group launches instances into.
+// AWS returns them as a single comma-separated string.
+func getAutoScalingGroupSubnets(group types.AutoScalingGroup) []string {
+	var subnets []string
+	for _, subnetID := range strings.Split(aws.ToString(group.VPCZoneIdentifier), ",") {
+		if subnetID = strings.TrimSpace(subnetID); subnetID != "" {
+			subnets = append(subnets, subnetID)
+		}
+	}
+	return subnets
+}
+
+// flattenAutoScalingTags converts the Autoscaling tag format to a simple `map[string]string`
+func flattenAutoScalingTags(tags []types.TagDescription) mapstr.M {
+	out := mapstr.M{}
+	for _, t := range tags {
+		out[*t.Key] = *t.Value
+	}
+	return out
+}
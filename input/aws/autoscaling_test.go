@@ -0,0 +1,132 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package aws
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/autoscaling"
+	"github.com/aws/aws-sdk-go-v2/service/autoscaling/types"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/elastic/assetbeat/input/internal"
+	"github.com/elastic/assetbeat/input/testutil"
+	"github.com/elastic/beats/v7/libbeat/beat"
+	"github.com/elastic/elastic-agent-libs/logp"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+const asgARN = "arn:aws:autoscaling:eu-west-1:11111111111111:autoScalingGroup:1111:autoScalingGroupName/my-asg"
+
+func TestAssetsAWS_collectAutoScalingAssets(t *testing.T) {
+	for _, tt := range []struct {
+		name           string
+		region         string
+		client         func(t *testing.T) autoscaling.DescribeAutoScalingGroupsAPIClient
+		expectedEvents []beat.Event
+	}{{
+		name:   "Test with an Autoscaling group using a launch template",
+		region: "eu-west-1",
+		client: func(t *testing.T) autoscaling.DescribeAutoScalingGroupsAPIClient {
+			return mockDescribeAutoscalingGroupsAPI(func(ctx context.Context, params *autoscaling.DescribeAutoScalingGroupsInput, optFns ...func(*autoscaling.Options)) (*autoscaling.DescribeAutoScalingGroupsOutput, error) {
+				t.Helper()
+				return &autoscaling.DescribeAutoScalingGroupsOutput{
+					AutoScalingGroups: []types.AutoScalingGroup{
+						{
+							AutoScalingGroupARN:  aws.String(asgARN),
+							AutoScalingGroupName: aws.String("my-asg"),
+							MinSize:              aws.Int32(1),
+							MaxSize:              aws.Int32(4),
+							DesiredCapacity:      aws.Int32(2),
+							HealthCheckType:      aws.String("EC2"),
+							LaunchTemplate: &types.LaunchTemplateSpecification{
+								LaunchTemplateId:   aws.String("lt-1111111"),
+								LaunchTemplateName: aws.String("my-template"),
+								Version:            aws.String("$Latest"),
+							},
+							VPCZoneIdentifier: aws.String(subnetID1 + ",mysubnetid2"),
+							Instances: []types.Instance{
+								{InstanceId: &instanceID1},
+								{InstanceId: &instanceID2},
+							},
+							Tags: []types.TagDescription{{Key: &tag_1_k, Value: &tag_1_v}},
+						},
+					},
+				}, nil
+			})
+		},
+		expectedEvents: []beat.Event{
+			{
+				Fields: mapstr.M{
+					"asset.ean":                              "host_group:" + asgARN,
+					"asset.id":                               asgARN,
+					"asset.name":                             "my-asg",
+					"asset.type":                             "aws.autoscaling.group",
+					"asset.kind":                             "host_group",
+					"asset.parents":                          []string{"network:" + subnetID1, "network:mysubnetid2"},
+					"asset.children":                         []string{"host:" + instanceID1, "host:" + instanceID2},
+					"asset.metadata.min_size":                int32(1),
+					"asset.metadata.max_size":                int32(4),
+					"asset.metadata.desired_capacity":        int32(2),
+					"asset.metadata.health_check_type":       "EC2",
+					"asset.metadata.launch_template.id":      "lt-1111111",
+					"asset.metadata.launch_template.name":    "my-template",
+					"asset.metadata.launch_template.version": "$Latest",
+					"asset.metadata.tags." + tag_1_k:         tag_1_v,
+					"cloud.account.id":                       "11111111111111",
+					"cloud.provider":                         "aws",
+					"cloud.region":                           "eu-west-1",
+				},
+				Meta: mapstr.M{
+					"index": internal.GetDefaultIndexName(),
+				},
+			},
+		},
+	},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			publisher := testutil.NewInMemoryPublisher()
+
+			ctx := context.Background()
+			logger := logp.NewLogger("test")
+
+			err := collectAutoScalingAssets(ctx, tt.client(t), tt.region, logger, publisher)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedEvents, publisher.Events)
+		})
+	}
+}
+
+func TestGetAutoScalingGroupMetadata(t *testing.T) {
+	group := types.AutoScalingGroup{
+		MinSize:                 aws.Int32(0),
+		MaxSize:                 aws.Int32(2),
+		DesiredCapacity:         aws.Int32(1),
+		HealthCheckType:         aws.String("ELB"),
+		LaunchConfigurationName: aws.String("my-launch-config"),
+	}
+	assert.Equal(t, mapstr.M{
+		"min_size":                  int32(0),
+		"max_size":                  int32(2),
+		"desired_capacity":          int32(1),
+		"health_check_type":         "ELB",
+		"launch_configuration_name": "my-launch-config",
+	}, getAutoScalingGroupMetadata(group))
+}
@@ -19,9 +19,25 @@ package aws
 
 import (
 	"context"
+	"fmt"
 	"time"
 
+	"github.com/aws/aws-sdk-go-v2/service/autoscaling"
+	"github.com/aws/aws-sdk-go-v2/service/cloudfront"
+	"github.com/aws/aws-sdk-go-v2/service/configservice"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/aws/aws-sdk-go-v2/service/elasticache"
+	"github.com/aws/aws-sdk-go-v2/service/elasticloadbalancingv2"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/aws/aws-sdk-go-v2/service/resourcegroupstaggingapi"
+	"github.com/aws/aws-sdk-go-v2/service/route53"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
 
 	stateless "github.com/elastic/beats/v7/filebeat/input/v2/input-stateless"
 
@@ -67,6 +83,27 @@ type config struct {
 	AccessKeyId         string   `config:"access_key_id"`
 	SecretAccessKey     string   `config:"secret_access_key"`
 	SessionToken        string   `config:"session_token"`
+	TaggedResourceTypes []string `config:"tagged_resource_types"`
+	Backend             string   `config:"backend"`
+	ConfigAggregator    string   `config:"config_aggregator"`
+	SQSQueueURL         string   `config:"sqs_queue_url"`
+	// Route53RecordRelationships lists the records of every hosted zone, to relate the zones
+	// to the load balancers and Elastic IPs their records point at.
+	Route53RecordRelationships bool `config:"route53_record_relationships"`
+}
+
+// Validate checks the collection backend settings.
+func (c *config) Validate() error {
+	switch c.Backend {
+	case backendDescribe:
+	case backendConfigAggregator:
+		if c.ConfigAggregator == "" {
+			return fmt.Errorf("config_aggregator is required when using the %s backend", backendConfigAggregator)
+		}
+	default:
+		return fmt.Errorf("unknown backend %q", c.Backend)
+	}
+	return nil
 }
 
 func defaultConfig() config {
@@ -75,10 +112,16 @@ func defaultConfig() config {
 			Period:     time.Second * 600,
 			AssetTypes: nil,
 		},
-		Regions:         []string{"eu-west-2"},
-		AccessKeyId:     "",
-		SecretAccessKey: "",
-		SessionToken:    "",
+		Regions:             []string{"eu-west-2"},
+		AccessKeyId:         "",
+		SecretAccessKey:     "",
+		SessionToken:        "",
+		TaggedResourceTypes: nil,
+		Backend:             backendDescribe,
+		ConfigAggregator:    "",
+		SQSQueueURL:         "",
+
+		Route53RecordRelationships: false,
 	}
 }
 
@@ -102,6 +145,10 @@ func (s *assetsAWS) Run(inputCtx input.Context, publisher stateless.Publisher) e
 	cfg := s.Config
 	period := cfg.Period
 
+	if cfg.SQSQueueURL != "" {
+		startAWSEventsConsumer(ctx, log, cfg, publisher)
+	}
+
 	ticker := time.NewTicker(period)
 	select {
 	case <-ctx.Done():
@@ -135,7 +182,27 @@ func getAWSConfigForRegion(ctx context.Context, cfg config, region string) (aws.
 	return aws_config.LoadDefaultConfig(ctx, options...)
 }
 
+type stsGetCallerIdentityAPIClient interface {
+	GetCallerIdentity(ctx context.Context, params *sts.GetCallerIdentityInput, optFns ...func(*sts.Options)) (*sts.GetCallerIdentityOutput, error)
+}
+
+// getAWSAccountID returns the ID of the account the configured credentials belong to.
+func getAWSAccountID(ctx context.Context, client stsGetCallerIdentityAPIClient) (string, error) {
+	resp, err := client.GetCallerIdentity(ctx, &sts.GetCallerIdentityInput{})
+	if err != nil {
+		return "", fmt.Errorf("error retrieving AWS caller identity: %w", err)
+	}
+	return aws.ToString(resp.Account), nil
+}
+
 func collectAWSAssets(ctx context.Context, log *logp.Logger, cfg config, publisher stateless.Publisher) {
+	if cfg.Backend == backendConfigAggregator {
+		collectAWSConfigAggregatorAssets(ctx, log, cfg, publisher)
+		return
+	}
+
+	collectAWSAccountAssets(ctx, log, cfg, publisher)
+
 	for _, region := range cfg.Regions {
 		awsCfg, err := getAWSConfigForRegion(ctx, cfg, region)
 		if err != nil {
@@ -144,9 +211,11 @@ func collectAWSAssets(ctx context.Context, log *logp.Logger, cfg config, publish
 		}
 
 		// these strings need careful documentation
-		if internal.IsTypeEnabled(cfg.AssetTypes, "k8s.cluster") {
+		if internal.IsTypeEnabled(cfg.AssetTypes, "k8s.cluster") ||
+			internal.IsTypeEnabled(cfg.AssetTypes, "aws.eks.nodegroup") ||
+			internal.IsTypeEnabled(cfg.AssetTypes, "aws.eks.fargate_profile") {
 			go func() {
-				err := collectEKSAssets(ctx, awsCfg, log, publisher)
+				err := collectEKSAssets(ctx, awsCfg, cfg.AssetTypes, log, publisher)
 				if err != nil {
 					log.Errorf("error collecting EKS assets: %w", err)
 				}
@@ -162,6 +231,21 @@ func collectAWSAssets(ctx context.Context, log *logp.Logger, cfg config, publish
 				}
 			}()
 		}
+		if internal.IsTypeEnabled(cfg.AssetTypes, "aws.ebs.volume") {
+			ebsRegion := region
+			go func() {
+				accountID, err := getAWSAccountID(ctx, sts.NewFromConfig(awsCfg))
+				if err != nil {
+					log.Errorf("error collecting EBS assets: %v", err)
+					return
+				}
+				client := ec2.NewFromConfig(awsCfg)
+				err = collectEBSAssets(ctx, client, ebsRegion, accountID, log, publisher)
+				if err != nil {
+					log.Errorf("error collecting EBS assets: %v", err)
+				}
+			}()
+		}
 		if internal.IsTypeEnabled(cfg.AssetTypes, "aws.vpc") {
 			vpcRegion := region
 			go func() {
@@ -182,5 +266,336 @@ func collectAWSAssets(ctx context.Context, log *logp.Logger, cfg config, publish
 				}
 			}()
 		}
+		if internal.IsTypeEnabled(cfg.AssetTypes, "aws.ec2.security_group") {
+			sgRegion := region
+			go func() {
+				client := ec2.NewFromConfig(awsCfg)
+				err := collectSecurityGroupAssets(ctx, client, sgRegion, log, publisher)
+				if err != nil {
+					log.Errorf("error collecting Security group assets: %v", err)
+				}
+			}()
+		}
+		if internal.IsTypeEnabled(cfg.AssetTypes, "aws.internet_gateway") {
+			igwRegion := region
+			go func() {
+				client := ec2.NewFromConfig(awsCfg)
+				err := collectInternetGatewayAssets(ctx, client, igwRegion, log, publisher)
+				if err != nil {
+					log.Errorf("error collecting Internet gateway assets: %v", err)
+				}
+			}()
+		}
+		if internal.IsTypeEnabled(cfg.AssetTypes, "aws.nat_gateway") {
+			natRegion := region
+			go func() {
+				accountID, err := getAWSAccountID(ctx, sts.NewFromConfig(awsCfg))
+				if err != nil {
+					log.Errorf("error collecting NAT gateway assets: %v", err)
+					return
+				}
+				client := ec2.NewFromConfig(awsCfg)
+				err = collectNatGatewayAssets(ctx, client, natRegion, accountID, log, publisher)
+				if err != nil {
+					log.Errorf("error collecting NAT gateway assets: %v", err)
+				}
+			}()
+		}
+		if internal.IsTypeEnabled(cfg.AssetTypes, "aws.eip") {
+			eipRegion := region
+			go func() {
+				accountID, err := getAWSAccountID(ctx, sts.NewFromConfig(awsCfg))
+				if err != nil {
+					log.Errorf("error collecting Elastic IP assets: %v", err)
+					return
+				}
+				client := ec2.NewFromConfig(awsCfg)
+				err = collectElasticIPAssets(ctx, client, eipRegion, accountID, log, publisher)
+				if err != nil {
+					log.Errorf("error collecting Elastic IP assets: %v", err)
+				}
+			}()
+		}
+		if internal.IsTypeEnabled(cfg.AssetTypes, "aws.transit_gateway") ||
+			internal.IsTypeEnabled(cfg.AssetTypes, "aws.transit_gateway.attachment") {
+			tgwRegion := region
+			go func() {
+				client := ec2.NewFromConfig(awsCfg)
+				err := collectTransitGatewayAssets(ctx, client, tgwRegion, cfg.AssetTypes, log, publisher)
+				if err != nil {
+					log.Errorf("error collecting Transit gateway assets: %v", err)
+				}
+			}()
+		}
+		if internal.IsTypeEnabled(cfg.AssetTypes, "aws.vpc_peering_connection") {
+			peeringRegion := region
+			go func() {
+				client := ec2.NewFromConfig(awsCfg)
+				err := collectVPCPeeringAssets(ctx, client, peeringRegion, log, publisher)
+				if err != nil {
+					log.Errorf("error collecting VPC peering connection assets: %v", err)
+				}
+			}()
+		}
+		if internal.IsTypeEnabled(cfg.AssetTypes, "aws.ecs.cluster") ||
+			internal.IsTypeEnabled(cfg.AssetTypes, "aws.ecs.service") ||
+			internal.IsTypeEnabled(cfg.AssetTypes, "aws.ecs.task") {
+			ecsRegion := region
+			go func() {
+				client := ecs.NewFromConfig(awsCfg)
+				err := collectECSAssets(ctx, client, ecsRegion, cfg.AssetTypes, log, publisher)
+				if err != nil {
+					log.Errorf("error collecting ECS assets: %v", err)
+				}
+			}()
+		}
+		if internal.IsTypeEnabled(cfg.AssetTypes, "aws.autoscaling.group") {
+			asgRegion := region
+			go func() {
+				client := autoscaling.NewFromConfig(awsCfg)
+				err := collectAutoScalingAssets(ctx, client, asgRegion, log, publisher)
+				if err != nil {
+					log.Errorf("error collecting Autoscaling group assets: %v", err)
+				}
+			}()
+		}
+		if internal.IsTypeEnabled(cfg.AssetTypes, "aws.dynamodb.table") {
+			dynamoDBRegion := region
+			go func() {
+				client := dynamodb.NewFromConfig(awsCfg)
+				err := collectDynamoDBAssets(ctx, client, dynamoDBRegion, log, publisher)
+				if err != nil {
+					log.Errorf("error collecting DynamoDB assets: %v", err)
+				}
+			}()
+		}
+		if internal.IsTypeEnabled(cfg.AssetTypes, "aws.elasticache.cluster") {
+			elastiCacheRegion := region
+			go func() {
+				client := elasticache.NewFromConfig(awsCfg)
+				err := collectElastiCacheAssets(ctx, client, elastiCacheRegion, log, publisher)
+				if err != nil {
+					log.Errorf("error collecting ElastiCache assets: %v", err)
+				}
+			}()
+		}
+		if internal.IsTypeEnabled(cfg.AssetTypes, "aws.sqs.queue") {
+			sqsRegion := region
+			go func() {
+				client := sqs.NewFromConfig(awsCfg)
+				err := collectSQSAssets(ctx, client, sqsRegion, log, publisher)
+				if err != nil {
+					log.Errorf("error collecting SQS assets: %v", err)
+				}
+			}()
+		}
+		if internal.IsTypeEnabled(cfg.AssetTypes, "aws.sns.topic") {
+			snsRegion := region
+			go func() {
+				client := sns.NewFromConfig(awsCfg)
+				err := collectSNSAssets(ctx, client, snsRegion, log, publisher)
+				if err != nil {
+					log.Errorf("error collecting SNS assets: %v", err)
+				}
+			}()
+		}
+		// the tagged resources overlap with all the other asset types, so they are only collected on request
+		if len(cfg.AssetTypes) > 0 && internal.IsTypeEnabled(cfg.AssetTypes, "aws.tagged_resource") {
+			taggedResourceRegion := region
+			go func() {
+				client := resourcegroupstaggingapi.NewFromConfig(awsCfg)
+				err := collectTaggedResourceAssets(ctx, client, taggedResourceRegion, cfg.TaggedResourceTypes, false, log, publisher)
+				if err != nil {
+					log.Errorf("error collecting tagged resource assets: %v", err)
+				}
+			}()
+		}
+		if internal.IsTypeEnabled(cfg.AssetTypes, "aws.lambda.function") {
+			lambdaRegion := region
+			go func() {
+				client := lambda.NewFromConfig(awsCfg)
+				err := collectLambdaAssets(ctx, client, lambdaRegion, log, publisher)
+				if err != nil {
+					log.Errorf("error collecting Lambda assets: %v", err)
+				}
+			}()
+		}
+	}
+}
+
+// collectAWSAccountAssets collects the assets of global services, and the S3 buckets which are listed globally,
+// so this is meant to be called once per account rather than once per configured region.
+func collectAWSAccountAssets(ctx context.Context, log *logp.Logger, cfg config, publisher stateless.Publisher) {
+	collectS3 := internal.IsTypeEnabled(cfg.AssetTypes, "aws.s3.bucket")
+	collectIAM := internal.IsTypeEnabled(cfg.AssetTypes, "aws.iam.role")
+	collectRoute53 := internal.IsTypeEnabled(cfg.AssetTypes, "aws.route53.hosted_zone")
+	collectCloudFront := internal.IsTypeEnabled(cfg.AssetTypes, "aws.cloudfront.distribution")
+	// the tagged resources overlap with all the other asset types, so they are only collected on request
+	collectTaggedResources := len(cfg.AssetTypes) > 0 && internal.IsTypeEnabled(cfg.AssetTypes, "aws.tagged_resource")
+	if len(cfg.Regions) == 0 {
+		return
+	}
+
+	if collectTaggedResources {
+		go func() {
+			awsCfg, err := getAWSConfigForRegion(ctx, cfg, globalResourcesRegion)
+			if err != nil {
+				log.Errorf("failed to create AWS config for global tagged resources: %v", err)
+				return
+			}
+			client := resourcegroupstaggingapi.NewFromConfig(awsCfg)
+			err = collectTaggedResourceAssets(ctx, client, globalResourcesRegion, cfg.TaggedResourceTypes, true, log, publisher)
+			if err != nil {
+				log.Errorf("error collecting global tagged resource assets: %v", err)
+			}
+		}()
+	}
+	if !(collectS3 || collectIAM || collectRoute53 || collectCloudFront) {
+		return
+	}
+
+	awsCfg, err := getAWSConfigForRegion(ctx, cfg, cfg.Regions[0])
+	if err != nil {
+		log.Errorf("failed to create AWS config for global services: %v", err)
+		return
+	}
+
+	go func() {
+		accountID, err := getAWSAccountID(ctx, sts.NewFromConfig(awsCfg))
+		if err != nil {
+			log.Errorf("error collecting global AWS assets: %v", err)
+			return
+		}
+		if collectS3 {
+			go func() {
+				client := s3.NewFromConfig(awsCfg)
+				err := collectS3Assets(ctx, client, accountID, log, publisher)
+				if err != nil {
+					log.Errorf("error collecting S3 assets: %v", err)
+				}
+			}()
+		}
+		if collectIAM {
+			go func() {
+				client := iam.NewFromConfig(awsCfg)
+				err := collectIAMRoleAssets(ctx, client, accountID, log, publisher)
+				if err != nil {
+					log.Errorf("error collecting IAM assets: %v", err)
+				}
+			}()
+		}
+		if !(collectRoute53 || collectCloudFront) {
+			return
+		}
+
+		// Route 53 records and CloudFront origins point at regional resources, which are looked up
+		// in every configured region. Elastic IPs are only needed to relate Route 53 records.
+		withRecords := collectRoute53 && cfg.Route53RecordRelationships
+		targets := getAWSTargetIndex(ctx, log, cfg, withRecords)
+		if collectRoute53 {
+			go func() {
+				var zoneTargets *awsTargetIndex
+				if withRecords {
+					zoneTargets = targets
+				}
+				client := route53.NewFromConfig(awsCfg)
+				err := collectRoute53Assets(ctx, client, accountID, zoneTargets, log, publisher)
+				if err != nil {
+					log.Errorf("error collecting Route 53 assets: %v", err)
+				}
+			}()
+		}
+		if collectCloudFront {
+			go func() {
+				client := cloudfront.NewFromConfig(awsCfg)
+				err := collectCloudFrontAssets(ctx, client, accountID, targets, log, publisher)
+				if err != nil {
+					log.Errorf("error collecting CloudFront assets: %v", err)
+				}
+			}()
+		}
+	}()
+}
+
+// getAWSTargetIndex indexes the load balancers, and optionally the Elastic IPs, of every configured region.
+// Regions that fail to be indexed are skipped, so that global assets are published with partial relationships.
+func getAWSTargetIndex(ctx context.Context, log *logp.Logger, cfg config, withElasticIPs bool) *awsTargetIndex {
+	targets := newAWSTargetIndex()
+	for _, region := range cfg.Regions {
+		awsCfg, err := getAWSConfigForRegion(ctx, cfg, region)
+		if err != nil {
+			log.Warnf("failed to create AWS config for %s: %v", region, err)
+			continue
+		}
+		if err := targets.indexLoadBalancers(ctx, elasticloadbalancingv2.NewFromConfig(awsCfg)); err != nil {
+			log.Warnf("error indexing load balancers in %s: %v", region, err)
+		}
+		if withElasticIPs {
+			if err := targets.indexElasticIPs(ctx, ec2.NewFromConfig(awsCfg)); err != nil {
+				log.Warnf("error indexing Elastic IPs in %s: %v", region, err)
+			}
+		}
+	}
+	return targets
+}
+
+// collectAWSConfigAggregatorAssets collects the assets through the AWS Config aggregator,
+// which is queried in the first configured region.
+func collectAWSConfigAggregatorAssets(ctx context.Context, log *logp.Logger, cfg config, publisher stateless.Publisher) {
+	if len(cfg.Regions) == 0 {
+		return
+	}
+
+	awsCfg, err := getAWSConfigForRegion(ctx, cfg, cfg.Regions[0])
+	if err != nil {
+		log.Errorf("failed to create AWS config for the Config aggregator: %v", err)
+		return
+	}
+
+	go func() {
+		client := configservice.NewFromConfig(awsCfg)
+		err := collectConfigAggregatorAssets(ctx, client, cfg.ConfigAggregator, cfg.AssetTypes, log, publisher)
+		if err != nil {
+			log.Errorf("error collecting AWS Config aggregator assets: %v", err)
+		}
+	}()
+}
+
+// startAWSEventsConsumer updates the assets affected by the events of the configured SQS queue, between full collections.
+func startAWSEventsConsumer(ctx context.Context, log *logp.Logger, cfg config, publisher stateless.Publisher) {
+	var sqsOptions []func(*sqs.Options)
+	region := getSQSQueueRegion(cfg.SQSQueueURL)
+	if region == "" {
+		// queues outside of AWS, such as ElasticMQ ones, are reached through the host of their URL
+		endpoint, err := getSQSQueueEndpoint(cfg.SQSQueueURL)
+		if err != nil {
+			log.Errorf("invalid SQS queue URL: %v", err)
+			return
+		}
+		sqsOptions = append(sqsOptions, func(o *sqs.Options) {
+			o.EndpointResolver = sqs.EndpointResolverFromURL(endpoint)
+		})
+		if len(cfg.Regions) > 0 {
+			region = cfg.Regions[0]
+		}
+	}
+	awsCfg, err := getAWSConfigForRegion(ctx, cfg, region)
+	if err != nil {
+		log.Errorf("failed to create AWS config for the SQS queue: %v", err)
+		return
+	}
+
+	handler := &awsEventHandler{
+		assetTypes: cfg.AssetTypes,
+		ec2Client: func(ctx context.Context, region string) (ec2EventsAPIClient, error) {
+			awsCfg, err := getAWSConfigForRegion(ctx, cfg, region)
+			if err != nil {
+				return nil, err
+			}
+			return ec2.NewFromConfig(awsCfg), nil
+		},
+		log:       log,
+		publisher: publisher,
 	}
+	go consumeAWSEvents(ctx, sqs.NewFromConfig(awsCfg, sqsOptions...), cfg.SQSQueueURL, handler)
 }
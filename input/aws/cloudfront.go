@@ -0,0 +1,155 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package aws
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/elastic/assetbeat/input/internal"
+	stateless "github.com/elastic/beats/v7/filebeat/input/v2/input-stateless"
+
+	"github.com/elastic/elastic-agent-libs/logp"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudfront"
+	"github.com/aws/aws-sdk-go-v2/service/cloudfront/types"
+)
+
+type cloudFrontClient interface {
+	cloudfront.ListDistributionsAPIClient
+	ListTagsForResource(ctx context.Context, params *cloudfront.ListTagsForResourceInput, optFns ...func(*cloudfront.Options)) (*cloudfront.ListTagsForResourceOutput, error)
+}
+
+// collectCloudFrontAssets publishes every CloudFront distribution of the account.
+// Origins served from S3 buckets, or from load balancers found in the target index,
+// are published as children of the distribution.
+func collectCloudFrontAssets(ctx context.Context, client cloudFrontClient, accountID string, targets *awsTargetIndex, log *logp.Logger, publisher stateless.Publisher) error {
+	distributions, err := listCloudFrontDistributions(ctx, client)
+	if err != nil {
+		return err
+	}
+
+	assetType := "aws.cloudfront.distribution"
+	assetKind := "distribution"
+	for _, distribution := range distributions {
+		var tags []types.Tag
+		tagsResp, err := client.ListTagsForResource(ctx, &cloudfront.ListTagsForResourceInput{Resource: distribution.ARN})
+		if err != nil {
+			log.Warnf("error getting tags of CloudFront distribution %s: %v", aws.ToString(distribution.Id), err)
+		} else if tagsResp.Tags != nil {
+			tags = tagsResp.Tags.Items
+		}
+
+		var origins, children []string
+		if distribution.Origins != nil {
+			for _, origin := range distribution.Origins.Items {
+				domainName := aws.ToString(origin.DomainName)
+				origins = append(origins, domainName)
+				if bucket, ok := getS3BucketFromDomainName(domainName); ok {
+					children = append(children, "bucket:"+bucket)
+				} else if ean, ok := targets.lookupLoadBalancer(domainName); ok {
+					children = append(children, ean)
+				}
+			}
+		}
+
+		options := []internal.AssetOption{
+			internal.WithAssetCloudProvider("aws"),
+			internal.WithAssetAccountID(accountID),
+			internal.WithAssetKindAndID(assetKind, aws.ToString(distribution.ARN)),
+			internal.WithAssetType(assetType),
+			internal.WithAssetName(aws.ToString(distribution.Id)),
+			WithAssetTags(flattenCloudFrontTags(tags)),
+			internal.WithAssetMetadata(getCloudFrontDistributionMetadata(distribution, origins)),
+		}
+		if len(children) > 0 {
+			options = append(options, internal.WithAssetChildren(children))
+		}
+		internal.Publish(publisher, nil, options...)
+	}
+
+	return nil
+}
+
+func getCloudFrontDistributionMetadata(distribution types.DistributionSummary, origins []string) mapstr.M {
+	metadata := mapstr.M{
+		"domain_name":  aws.ToString(distribution.DomainName),
+		"status":       aws.ToString(distribution.Status),
+		"enabled":      aws.ToBool(distribution.Enabled),
+		"price_class":  string(distribution.PriceClass),
+		"http_version": string(distribution.HttpVersion),
+		"origins":      origins,
+	}
+	if distribution.Aliases != nil && len(distribution.Aliases.Items) > 0 {
+		metadata["aliases"] = distribution.Aliases.Items
+	}
+	if aws.ToString(distribution.WebACLId) != "" {
+		metadata["web_acl_id"] = *distribution.WebACLId
+	}
+	if aws.ToString(distribution.Comment) != "" {
+		metadata["comment"] = *distribution.Comment
+	}
+	return metadata
+}
+
+func listCloudFrontDistributions(ctx context.Context, client cloudfront.ListDistributionsAPIClient) ([]types.DistributionSummary, error) {
+	distributions := make([]types.DistributionSummary, 0, 100)
+	paginator := cloudfront.NewListDistributionsPaginator(client, &cloudfront.ListDistributionsInput{})
+	for paginator.HasMorePages() {
+		resp, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error listing CloudFront distributions: %w", err)
+		}
+
+		if resp.DistributionList != nil {
+			distributions = append(distributions, resp.DistributionList.Items...)
+		}
+	}
+
+	return distributions, nil
+}
+
+// getS3BucketFromDomainName returns the bucket an S3 endpoint domain name belongs to,
+// for both REST endpoints, e.g. `bucket.s3.eu-west-1.amazonaws.com`,
+// and website endpoints, e.g. `bucket.s3-website-eu-west-1.amazonaws.com`.
+func getS3BucketFromDomainName(domainName string) (string, bool) {
+	host, ok := strings.CutSuffix(strings.ToLower(domainName), ".amazonaws.com")
+	if !ok {
+		return "", false
+	}
+	i := strings.LastIndex(host, ".s3")
+	if i <= 0 {
+		return "", false
+	}
+	if rest := host[i+len(".s3"):]; rest != "" && rest[0] != '.' && rest[0] != '-' {
+		return "", false
+	}
+	return host[:i], true
+}
+
+// flattenCloudFrontTags converts the CloudFront tag format to a simple `map[string]string`
+func flattenCloudFrontTags(tags []types.Tag) mapstr.M {
+	out := mapstr.M{}
+	for _, t := range tags {
+		out[*t.Key] = aws.ToString(t.Value)
+	}
+	return out
+}
@@ -0,0 +1,137 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package aws
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudfront"
+	"github.com/aws/aws-sdk-go-v2/service/cloudfront/types"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/elastic/assetbeat/input/internal"
+	"github.com/elastic/assetbeat/input/testutil"
+	"github.com/elastic/beats/v7/libbeat/beat"
+	"github.com/elastic/elastic-agent-libs/logp"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+var cloudFrontDistributionARN_1 = "arn:aws:cloudfront::11111111111111:distribution/E1111111111111"
+
+type mockCloudFrontClient struct {
+	distributions []types.DistributionSummary
+	tags          map[string][]types.Tag
+}
+
+func (m mockCloudFrontClient) ListDistributions(ctx context.Context, params *cloudfront.ListDistributionsInput, optFns ...func(*cloudfront.Options)) (*cloudfront.ListDistributionsOutput, error) {
+	return &cloudfront.ListDistributionsOutput{
+		DistributionList: &types.DistributionList{Items: m.distributions, IsTruncated: aws.Bool(false)},
+	}, nil
+}
+
+func (m mockCloudFrontClient) ListTagsForResource(ctx context.Context, params *cloudfront.ListTagsForResourceInput, optFns ...func(*cloudfront.Options)) (*cloudfront.ListTagsForResourceOutput, error) {
+	return &cloudfront.ListTagsForResourceOutput{Tags: &types.Tags{Items: m.tags[*params.Resource]}}, nil
+}
+
+func TestAssetsAWS_collectCloudFrontAssets(t *testing.T) {
+	client := mockCloudFrontClient{
+		distributions: []types.DistributionSummary{
+			{
+				ARN:         &cloudFrontDistributionARN_1,
+				Id:          aws.String("E1111111111111"),
+				DomainName:  aws.String("d111111abcdef8.cloudfront.net"),
+				Status:      aws.String("Deployed"),
+				Enabled:     aws.Bool(true),
+				PriceClass:  types.PriceClassPriceClassAll,
+				HttpVersion: types.HttpVersionHttp2,
+				Aliases:     &types.Aliases{Items: []string{"www.example.com"}},
+				WebACLId:    aws.String(""),
+				Comment:     aws.String("my website"),
+				Origins: &types.Origins{Items: []types.Origin{
+					{Id: aws.String("assets"), DomainName: aws.String("my-assets.s3.eu-west-1.amazonaws.com")},
+					{Id: aws.String("api"), DomainName: aws.String(loadBalancerDNSName)},
+					{Id: aws.String("legacy"), DomainName: aws.String("legacy.example.com")},
+				}},
+			},
+		},
+		tags: map[string][]types.Tag{
+			cloudFrontDistributionARN_1: {{Key: &tag_1_k, Value: &tag_1_v}},
+		},
+	}
+
+	targets := newAWSTargetIndex()
+	targets.loadBalancers[loadBalancerDNSName] = "resource:" + loadBalancerARN_1
+
+	expectedEvents := []beat.Event{
+		{
+			Fields: mapstr.M{
+				"asset.ean":                      "distribution:" + cloudFrontDistributionARN_1,
+				"asset.id":                       cloudFrontDistributionARN_1,
+				"asset.name":                     "E1111111111111",
+				"asset.type":                     "aws.cloudfront.distribution",
+				"asset.kind":                     "distribution",
+				"asset.children":                 []string{"bucket:my-assets", "resource:" + loadBalancerARN_1},
+				"asset.metadata.domain_name":     "d111111abcdef8.cloudfront.net",
+				"asset.metadata.status":          "Deployed",
+				"asset.metadata.enabled":         true,
+				"asset.metadata.price_class":     "PriceClass_All",
+				"asset.metadata.http_version":    "http2",
+				"asset.metadata.aliases":         []string{"www.example.com"},
+				"asset.metadata.comment":         "my website",
+				"asset.metadata.origins":         []string{"my-assets.s3.eu-west-1.amazonaws.com", loadBalancerDNSName, "legacy.example.com"},
+				"asset.metadata.tags." + tag_1_k: tag_1_v,
+				"cloud.account.id":               ownerID_1,
+				"cloud.provider":                 "aws",
+			},
+			Meta: mapstr.M{
+				"index": internal.GetDefaultIndexName(),
+			},
+		},
+	}
+
+	publisher := testutil.NewInMemoryPublisher()
+	log := logp.NewLogger("mylogger")
+	err := collectCloudFrontAssets(context.Background(), client, ownerID_1, targets, log, publisher)
+	assert.NoError(t, err)
+	assert.Equal(t, expectedEvents, publisher.Events)
+}
+
+func TestGetS3BucketFromDomainName(t *testing.T) {
+	for _, tt := range []struct {
+		domainName string
+
+		expectedBucket string
+		expectedOK     bool
+	}{
+		{domainName: "my-bucket.s3.amazonaws.com", expectedBucket: "my-bucket", expectedOK: true},
+		{domainName: "my-bucket.s3.eu-west-1.amazonaws.com", expectedBucket: "my-bucket", expectedOK: true},
+		{domainName: "my.dotted.bucket.s3-eu-west-1.amazonaws.com", expectedBucket: "my.dotted.bucket", expectedOK: true},
+		{domainName: "my-bucket.s3-website-eu-west-1.amazonaws.com", expectedBucket: "my-bucket", expectedOK: true},
+		{domainName: "my-bucket.s3-website.eu-west-1.amazonaws.com", expectedBucket: "my-bucket", expectedOK: true},
+		{domainName: loadBalancerDNSName, expectedOK: false},
+		{domainName: "my-bucket.s3.example.com", expectedOK: false},
+	} {
+		t.Run(tt.domainName, func(t *testing.T) {
+			bucket, ok := getS3BucketFromDomainName(tt.domainName)
+			assert.Equal(t, tt.expectedOK, ok)
+			assert.Equal(t, tt.expectedBucket, bucket)
+		})
+	}
+}
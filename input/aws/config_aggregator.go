@@ -0,0 +1,180 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/elastic/assetbeat/input/internal"
+	stateless "github.com/elastic/beats/v7/filebeat/input/v2/input-stateless"
+
+	"github.com/elastic/elastic-agent-libs/logp"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/configservice"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+const (
+	backendDescribe         = "describe"
+	backendConfigAggregator = "config_aggregator"
+)
+
+// configAggregatorResourceTypes maps the asset types supported by the config_aggregator backend
+// to the AWS Config resource types they are built from.
+var configAggregatorResourceTypes = []struct {
+	assetType    string
+	resourceType string
+}{
+	{assetType: "aws.ec2.instance", resourceType: "AWS::EC2::Instance"},
+	{assetType: "aws.ebs.volume", resourceType: "AWS::EC2::Volume"},
+	{assetType: "aws.vpc", resourceType: "AWS::EC2::VPC"},
+	{assetType: "aws.subnet", resourceType: "AWS::EC2::Subnet"},
+}
+
+type configAggregatorResult struct {
+	ResourceID    string          `json:"resourceId"`
+	ResourceType  string          `json:"resourceType"`
+	AccountID     string          `json:"accountId"`
+	AWSRegion     string          `json:"awsRegion"`
+	Configuration json.RawMessage `json:"configuration"`
+}
+
+// collectConfigAggregatorAssets collects the assets of all the accounts and regions of an AWS Config aggregator,
+// publishing them with the same fields as the Describe* based collectors.
+func collectConfigAggregatorAssets(ctx context.Context, client configservice.SelectAggregateResourceConfigAPIClient, aggregatorName string, assetTypes []string, log *logp.Logger, publisher stateless.Publisher) error {
+	expression := getConfigAggregatorExpression(assetTypes)
+	if expression == "" {
+		return nil
+	}
+
+	results, err := selectAggregateResourceConfig(ctx, client, aggregatorName, expression)
+	if err != nil {
+		return err
+	}
+
+	for _, result := range results {
+		var item configAggregatorResult
+		if err := json.Unmarshal([]byte(result), &item); err != nil {
+			log.Warnf("skipping invalid AWS Config result: %v", err)
+			continue
+		}
+		if err := publishConfigAggregatorResult(publisher, item); err != nil {
+			log.Warnf("skipping AWS Config resource %s %s: %v", item.ResourceType, item.ResourceID, err)
+		}
+	}
+
+	return nil
+}
+
+func getConfigAggregatorExpression(assetTypes []string) string {
+	var resourceTypes []string
+	for _, t := range configAggregatorResourceTypes {
+		if internal.IsTypeEnabled(assetTypes, t.assetType) {
+			resourceTypes = append(resourceTypes, "'"+t.resourceType+"'")
+		}
+	}
+	if resourceTypes == nil {
+		return ""
+	}
+	return "SELECT resourceId, resourceType, accountId, awsRegion, configuration WHERE resourceType IN (" + strings.Join(resourceTypes, ", ") + ")"
+}
+
+func publishConfigAggregatorResult(publisher stateless.Publisher, item configAggregatorResult) error {
+	switch item.ResourceType {
+	case "AWS::EC2::Instance":
+		var instance types.Instance
+		if err := decodeConfigItemConfiguration(item.Configuration, &instance); err != nil {
+			return err
+		}
+		if instance.InstanceId == nil || instance.State == nil {
+			return errors.New("missing instance details")
+		}
+		publishEC2InstanceAsset(publisher, item.AWSRegion, newEC2Instance(item.AccountID, instance))
+	case "AWS::EC2::Volume":
+		var volume types.Volume
+		if err := decodeConfigItemConfiguration(item.Configuration, &volume); err != nil {
+			return err
+		}
+		if volume.VolumeId == nil {
+			volume.VolumeId = aws.String(item.ResourceID)
+		}
+		publishEBSVolumeAsset(publisher, item.AWSRegion, item.AccountID, volume)
+	case "AWS::EC2::VPC":
+		var vpc types.Vpc
+		if err := decodeConfigItemConfiguration(item.Configuration, &vpc); err != nil {
+			return err
+		}
+		if vpc.VpcId == nil {
+			vpc.VpcId = aws.String(item.ResourceID)
+		}
+		if vpc.OwnerId == nil {
+			vpc.OwnerId = aws.String(item.AccountID)
+		}
+		publishVPCAsset(publisher, item.AWSRegion, vpc)
+	case "AWS::EC2::Subnet":
+		var subnet types.Subnet
+		if err := decodeConfigItemConfiguration(item.Configuration, &subnet); err != nil {
+			return err
+		}
+		if subnet.SubnetId == nil {
+			subnet.SubnetId = aws.String(item.ResourceID)
+		}
+		if subnet.OwnerId == nil {
+			subnet.OwnerId = aws.String(item.AccountID)
+		}
+		publishSubnetAsset(publisher, item.AWSRegion, subnet)
+	default:
+		return errors.New("unsupported resource type")
+	}
+	return nil
+}
+
+// decodeConfigItemConfiguration decodes the configuration of an AWS Config item into the corresponding EC2 API type.
+// AWS Config records the camelCase fields of the EC2 API, but a few of them differ in type;
+// those are left empty rather than failing the whole item.
+func decodeConfigItemConfiguration(configuration json.RawMessage, v any) error {
+	err := json.Unmarshal(configuration, v)
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		return nil
+	}
+	return err
+}
+
+func selectAggregateResourceConfig(ctx context.Context, client configservice.SelectAggregateResourceConfigAPIClient, aggregatorName string, expression string) ([]string, error) {
+	results := make([]string, 0, 100)
+	paginator := configservice.NewSelectAggregateResourceConfigPaginator(client, &configservice.SelectAggregateResourceConfigInput{
+		ConfigurationAggregatorName: aws.String(aggregatorName),
+		Expression:                  aws.String(expression),
+	})
+	for paginator.HasMorePages() {
+		resp, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error selecting aggregate resource config: %w", err)
+		}
+
+		results = append(results, resp.Results...)
+	}
+
+	return results, nil
+}
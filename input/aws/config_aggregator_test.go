@@ -0,0 +1,241 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/configservice"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/assetbeat/input/internal"
+	"github.com/elastic/assetbeat/input/testutil"
+	"github.com/elastic/beats/v7/libbeat/beat"
+	"github.com/elastic/elastic-agent-libs/logp"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+// newFakeConfigService starts a local fake of the AWS Config API, returning one page of results per element of pages.
+func newFakeConfigService(t *testing.T, aggregatorName string, expression string, pages [][]string) *configservice.Client {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "StarlingDoveService.SelectAggregateResourceConfig", r.Header.Get("X-Amz-Target"))
+		var input struct {
+			ConfigurationAggregatorName string
+			Expression                  string
+			NextToken                   string
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&input))
+		assert.Equal(t, aggregatorName, input.ConfigurationAggregatorName)
+		assert.Equal(t, expression, input.Expression)
+
+		page := 0
+		if input.NextToken != "" {
+			page = int(input.NextToken[0] - '0')
+		}
+		output := map[string]interface{}{
+			"Results": pages[page],
+		}
+		if page+1 < len(pages) {
+			output["NextToken"] = string(rune('0' + page + 1))
+		}
+		w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+		require.NoError(t, json.NewEncoder(w).Encode(output))
+	}))
+	t.Cleanup(server.Close)
+
+	return configservice.New(configservice.Options{
+		Region:           "eu-west-1",
+		Credentials:      credentials.NewStaticCredentialsProvider("accesskey", "secretkey", ""),
+		EndpointResolver: configservice.EndpointResolverFromURL(server.URL),
+	})
+}
+
+func TestAssetsAWS_collectConfigAggregatorAssets(t *testing.T) {
+	launchTime := time.Date(2023, 9, 1, 10, 0, 0, 0, time.UTC)
+	pages := [][]string{
+		{
+			`{"resourceId":"` + instanceID_1 + `","resourceType":"AWS::EC2::Instance","accountId":"` + ownerID_1 + `","awsRegion":"eu-west-1","configuration":{
+				"instanceId":"` + instanceID_1 + `","instanceType":"t3.micro","imageId":"ami-1111111","platformDetails":"Linux/UNIX",
+				"state":{"code":16,"name":"running"},"placement":{"availabilityZone":"eu-west-1a"},"launchTime":"2023-09-01T10:00:00.000Z",
+				"privateIpAddress":"10.0.0.1","privateDnsName":"ip-10-0-0-1.eu-west-1.compute.internal","subnetId":"` + subnetID1 + `","vpcId":"` + vpcID1 + `",
+				"securityGroups":[{"groupId":"` + securityGroupID_1 + `","groupName":"web"}],
+				"blockDeviceMappings":[{"deviceName":"/dev/xvda","ebs":{"volumeId":"` + volumeID_1 + `","status":"attached"}}],
+				"tags":[{"key":"` + nameTagKey + `","value":"` + instanceName_1 + `"}],
+				"cpuOptions":"unexpected"}}`,
+			`{"resourceId":"` + vpcId1 + `","resourceType":"AWS::EC2::VPC","accountId":"` + ownerID_1 + `","awsRegion":"eu-west-1","configuration":{
+				"vpcId":"` + vpcId1 + `","ownerId":"` + ownerID_1 + `","isDefault":true,"tags":[{"key":"` + tag_1_k + `","value":"` + tag_1_v + `"}]}}`,
+		},
+		{
+			`{"resourceId":"` + subnetID1 + `","resourceType":"AWS::EC2::Subnet","accountId":"` + ownerID_1 + `","awsRegion":"us-east-1","configuration":{
+				"subnetId":"` + subnetID1 + `","vpcId":"` + vpcId1 + `","state":"available"}}`,
+			`{"resourceId":"` + volumeID_1 + `","resourceType":"AWS::EC2::Volume","accountId":"` + ownerID_1 + `","awsRegion":"eu-west-1","configuration":{
+				"volumeId":"` + volumeID_1 + `","size":8,"volumeType":"gp3","iops":3000,"encrypted":true,"state":"in-use","availabilityZone":"eu-west-1a",
+				"attachments":[{"instanceId":"` + instanceID_1 + `","state":"attached"}]}}`,
+			`not json`,
+		},
+	}
+	isDefault := true
+	expression := "SELECT resourceId, resourceType, accountId, awsRegion, configuration WHERE resourceType IN ('AWS::EC2::Instance', 'AWS::EC2::Volume', 'AWS::EC2::VPC', 'AWS::EC2::Subnet')"
+	client := newFakeConfigService(t, "my-aggregator", expression, pages)
+
+	expectedEvents := []beat.Event{
+		{
+			Fields: mapstr.M{
+				"asset.ean":                         "host:" + instanceID_1,
+				"asset.id":                          instanceID_1,
+				"asset.name":                        instanceName_1,
+				"asset.metadata.state":              "running",
+				"asset.metadata.instance_type":      "t3.micro",
+				"asset.metadata.image_id":           "ami-1111111",
+				"asset.metadata.platform":           "Linux/UNIX",
+				"asset.metadata.availability_zone":  "eu-west-1a",
+				"asset.metadata.launch_time":        launchTime,
+				"asset.metadata.private_ip_address": "10.0.0.1",
+				"asset.metadata.private_dns_name":   "ip-10-0-0-1.eu-west-1.compute.internal",
+				"asset.metadata.lifecycle":          "on-demand",
+				"asset.type":                        "aws.ec2.instance",
+				"asset.kind":                        "host",
+				"asset.parents": []string{
+					"network:" + subnetID1,
+					"network:" + vpcID1,
+					"security_group:" + securityGroupID_1,
+				},
+				"asset.children": []string{
+					"volume:" + volumeID_1,
+				},
+				"asset.metadata.tags." + nameTagKey: instanceName_1,
+				"cloud.account.id":                  ownerID_1,
+				"cloud.provider":                    "aws",
+				"cloud.region":                      "eu-west-1",
+			},
+			Meta: mapstr.M{
+				"index": internal.GetDefaultIndexName(),
+			},
+		},
+		{
+			Fields: mapstr.M{
+				"asset.ean":                      "network:" + vpcId1,
+				"asset.id":                       vpcId1,
+				"asset.type":                     "aws.vpc",
+				"asset.kind":                     "network",
+				"asset.metadata.isDefault":       &isDefault,
+				"asset.metadata.tags." + tag_1_k: tag_1_v,
+				"cloud.account.id":               ownerID_1,
+				"cloud.provider":                 "aws",
+				"cloud.region":                   "eu-west-1",
+			},
+			Meta: mapstr.M{
+				"index": internal.GetDefaultIndexName(),
+			},
+		},
+		{
+			Fields: mapstr.M{
+				"asset.ean":            "network:" + subnetID1,
+				"asset.id":             subnetID1,
+				"asset.type":           "aws.subnet",
+				"asset.kind":           "network",
+				"asset.parents":        []string{"network:" + vpcId1},
+				"asset.metadata.state": "available",
+				"cloud.account.id":     ownerID_1,
+				"cloud.provider":       "aws",
+				"cloud.region":         "us-east-1",
+			},
+			Meta: mapstr.M{
+				"index": internal.GetDefaultIndexName(),
+			},
+		},
+		{
+			Fields: mapstr.M{
+				"asset.ean":                        "volume:" + volumeID_1,
+				"asset.id":                         volumeID_1,
+				"asset.type":                       "aws.ebs.volume",
+				"asset.kind":                       "volume",
+				"asset.parents":                    []string{"host:" + instanceID_1},
+				"asset.metadata.size":              int32(8),
+				"asset.metadata.volume_type":       "gp3",
+				"asset.metadata.iops":              int32(3000),
+				"asset.metadata.encrypted":         true,
+				"asset.metadata.state":             "in-use",
+				"asset.metadata.availability_zone": "eu-west-1a",
+				"cloud.account.id":                 ownerID_1,
+				"cloud.provider":                   "aws",
+				"cloud.region":                     "eu-west-1",
+			},
+			Meta: mapstr.M{
+				"index": internal.GetDefaultIndexName(),
+			},
+		},
+	}
+
+	publisher := testutil.NewInMemoryPublisher()
+	err := collectConfigAggregatorAssets(context.Background(), client, "my-aggregator", []string{}, logp.NewLogger("test"), publisher)
+	assert.NoError(t, err)
+	assert.Equal(t, expectedEvents, publisher.Events)
+}
+
+func TestGetConfigAggregatorExpression(t *testing.T) {
+	assert.Equal(t,
+		"SELECT resourceId, resourceType, accountId, awsRegion, configuration WHERE resourceType IN ('AWS::EC2::VPC', 'AWS::EC2::Subnet')",
+		getConfigAggregatorExpression([]string{"aws.vpc", "aws.subnet", "k8s.cluster"}),
+	)
+	assert.Equal(t, "", getConfigAggregatorExpression([]string{"k8s.cluster"}))
+}
+
+func TestConfig_Validate(t *testing.T) {
+	for _, tt := range []struct {
+		name        string
+		cfg         config
+		expectedErr string
+	}{
+		{
+			name: "describe backend",
+			cfg:  config{Backend: backendDescribe},
+		},
+		{
+			name: "config aggregator backend",
+			cfg:  config{Backend: backendConfigAggregator, ConfigAggregator: "my-aggregator"},
+		},
+		{
+			name:        "config aggregator backend without aggregator",
+			cfg:         config{Backend: backendConfigAggregator},
+			expectedErr: "config_aggregator is required when using the config_aggregator backend",
+		},
+		{
+			name:        "unknown backend",
+			cfg:         config{Backend: "other"},
+			expectedErr: `unknown backend "other"`,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cfg.Validate()
+			if tt.expectedErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.expectedErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,156 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package aws
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/elastic/assetbeat/input/internal"
+	stateless "github.com/elastic/beats/v7/filebeat/input/v2/input-stateless"
+
+	"github.com/elastic/elastic-agent-libs/logp"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+type dynamoDBClient interface {
+	dynamodb.ListTablesAPIClient
+	dynamodb.DescribeTableAPIClient
+	ListTagsOfResource(ctx context.Context, params *dynamodb.ListTagsOfResourceInput, optFns ...func(*dynamodb.Options)) (*dynamodb.ListTagsOfResourceOutput, error)
+}
+
+func collectDynamoDBAssets(ctx context.Context, client dynamoDBClient, region string, log *logp.Logger, publisher stateless.Publisher) error {
+	tables, err := describeDynamoDBTables(ctx, client, log)
+	if err != nil {
+		return err
+	}
+
+	assetType := "aws.dynamodb.table"
+	assetKind := "table"
+	for _, table := range tables {
+		tableARN, err := arn.Parse(*table.TableArn)
+		if err != nil {
+			log.Warnf("skipping DynamoDB table with invalid ARN %s: %v", *table.TableArn, err)
+			continue
+		}
+		tags, err := getDynamoDBTableTags(ctx, client, *table.TableArn)
+		if err != nil {
+			log.Warnf("error getting tags of DynamoDB table %s: %v", *table.TableName, err)
+		}
+		internal.Publish(publisher, nil,
+			internal.WithAssetCloudProvider("aws"),
+			internal.WithAssetRegion(region),
+			internal.WithAssetAccountID(tableARN.AccountID),
+			internal.WithAssetKindAndID(assetKind, *table.TableArn),
+			internal.WithAssetType(assetType),
+			internal.WithAssetName(aws.ToString(table.TableName)),
+			WithAssetTags(flattenDynamoDBTags(tags)),
+			internal.WithAssetMetadata(getDynamoDBTableMetadata(table)),
+		)
+	}
+
+	return nil
+}
+
+func getDynamoDBTableMetadata(table types.TableDescription) mapstr.M {
+	// the billing mode summary is only returned for tables that have been switched to on-demand at some point
+	billingMode := types.BillingModeProvisioned
+	if table.BillingModeSummary != nil && table.BillingModeSummary.BillingMode != "" {
+		billingMode = table.BillingModeSummary.BillingMode
+	}
+	metadata := mapstr.M{
+		"status":                      string(table.TableStatus),
+		"item_count":                  aws.ToInt64(table.ItemCount),
+		"size_bytes":                  aws.ToInt64(table.TableSizeBytes),
+		"billing_mode":                string(billingMode),
+		"global_table":                len(table.Replicas) > 0,
+		"stream_enabled":              table.StreamSpecification != nil && aws.ToBool(table.StreamSpecification.StreamEnabled),
+		"deletion_protection_enabled": aws.ToBool(table.DeletionProtectionEnabled),
+	}
+	if billingMode == types.BillingModeProvisioned && table.ProvisionedThroughput != nil {
+		metadata["provisioned_throughput"] = mapstr.M{
+			"read_capacity_units":  aws.ToInt64(table.ProvisionedThroughput.ReadCapacityUnits),
+			"write_capacity_units": aws.ToInt64(table.ProvisionedThroughput.WriteCapacityUnits),
+		}
+	}
+	if table.CreationDateTime != nil {
+		metadata["creation_date"] = *table.CreationDateTime
+	}
+	if table.TableClassSummary != nil && table.TableClassSummary.TableClass != "" {
+		metadata["table_class"] = string(table.TableClassSummary.TableClass)
+	}
+	if table.SSEDescription != nil && table.SSEDescription.SSEType != "" {
+		metadata["sse_type"] = string(table.SSEDescription.SSEType)
+	}
+	return metadata
+}
+
+func describeDynamoDBTables(ctx context.Context, client dynamoDBClient, log *logp.Logger) ([]types.TableDescription, error) {
+	tables := make([]types.TableDescription, 0, 100)
+	paginator := dynamodb.NewListTablesPaginator(client, &dynamodb.ListTablesInput{})
+	for paginator.HasMorePages() {
+		resp, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error listing DynamoDB tables: %w", err)
+		}
+
+		for _, name := range resp.TableNames {
+			table, err := client.DescribeTable(ctx, &dynamodb.DescribeTableInput{TableName: aws.String(name)})
+			if err != nil {
+				log.Warnf("error describing DynamoDB table %s: %v", name, err)
+				continue
+			}
+			tables = append(tables, *table.Table)
+		}
+	}
+
+	return tables, nil
+}
+
+func getDynamoDBTableTags(ctx context.Context, client dynamoDBClient, tableARN string) ([]types.Tag, error) {
+	var tags []types.Tag
+	var nextToken *string
+	for {
+		resp, err := client.ListTagsOfResource(ctx, &dynamodb.ListTagsOfResourceInput{
+			ResourceArn: aws.String(tableARN),
+			NextToken:   nextToken,
+		})
+		if err != nil {
+			return nil, err
+		}
+		tags = append(tags, resp.Tags...)
+		if resp.NextToken == nil {
+			return tags, nil
+		}
+		nextToken = resp.NextToken
+	}
+}
+
+// flattenDynamoDBTags converts the DynamoDB tag format to a simple `map[string]string`
+func flattenDynamoDBTags(tags []types.Tag) mapstr.M {
+	out := mapstr.M{}
+	for _, t := range tags {
+		out[*t.Key] = *t.Value
+	}
+	return out
+}
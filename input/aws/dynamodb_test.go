@@ -0,0 +1,166 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package aws
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/elastic/assetbeat/input/internal"
+	"github.com/elastic/assetbeat/input/testutil"
+	"github.com/elastic/beats/v7/libbeat/beat"
+	"github.com/elastic/elastic-agent-libs/logp"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+var dynamoDBTableName = "orders"
+var dynamoDBTableARN_1 = "arn:aws:dynamodb:eu-west-1:11111111111111:table/orders"
+
+type mockDynamoDBClient struct {
+	tables         map[string]types.TableDescription
+	describeErrors map[string]error
+	tags           map[string][]types.Tag
+}
+
+func (m mockDynamoDBClient) ListTables(ctx context.Context, params *dynamodb.ListTablesInput, optFns ...func(*dynamodb.Options)) (*dynamodb.ListTablesOutput, error) {
+	var names []string
+	for name := range m.describeErrors {
+		names = append(names, name)
+	}
+	for name := range m.tables {
+		names = append(names, name)
+	}
+	return &dynamodb.ListTablesOutput{TableNames: names}, nil
+}
+
+func (m mockDynamoDBClient) DescribeTable(ctx context.Context, params *dynamodb.DescribeTableInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DescribeTableOutput, error) {
+	if err, ok := m.describeErrors[*params.TableName]; ok {
+		return nil, err
+	}
+	table := m.tables[*params.TableName]
+	return &dynamodb.DescribeTableOutput{Table: &table}, nil
+}
+
+func (m mockDynamoDBClient) ListTagsOfResource(ctx context.Context, params *dynamodb.ListTagsOfResourceInput, optFns ...func(*dynamodb.Options)) (*dynamodb.ListTagsOfResourceOutput, error) {
+	return &dynamodb.ListTagsOfResourceOutput{Tags: m.tags[*params.ResourceArn]}, nil
+}
+
+func TestAssetsAWS_collectDynamoDBAssets(t *testing.T) {
+	creationDate := time.Date(2023, 9, 1, 10, 0, 0, 0, time.UTC)
+	client := mockDynamoDBClient{
+		tables: map[string]types.TableDescription{
+			dynamoDBTableName: {
+				TableArn:         &dynamoDBTableARN_1,
+				TableName:        &dynamoDBTableName,
+				TableStatus:      types.TableStatusActive,
+				ItemCount:        aws.Int64(42),
+				TableSizeBytes:   aws.Int64(1024),
+				CreationDateTime: &creationDate,
+				ProvisionedThroughput: &types.ProvisionedThroughputDescription{
+					ReadCapacityUnits:  aws.Int64(5),
+					WriteCapacityUnits: aws.Int64(10),
+				},
+				StreamSpecification: &types.StreamSpecification{StreamEnabled: aws.Bool(true)},
+				SSEDescription:      &types.SSEDescription{SSEType: types.SSETypeKms},
+			},
+		},
+		tags: map[string][]types.Tag{
+			dynamoDBTableARN_1: {{Key: &tag_1_k, Value: &tag_1_v}},
+		},
+	}
+
+	expectedEvents := []beat.Event{
+		{
+			Fields: mapstr.M{
+				"asset.ean":                                  "table:" + dynamoDBTableARN_1,
+				"asset.id":                                   dynamoDBTableARN_1,
+				"asset.name":                                 dynamoDBTableName,
+				"asset.type":                                 "aws.dynamodb.table",
+				"asset.kind":                                 "table",
+				"asset.metadata.status":                      "ACTIVE",
+				"asset.metadata.item_count":                  int64(42),
+				"asset.metadata.size_bytes":                  int64(1024),
+				"asset.metadata.billing_mode":                "PROVISIONED",
+				"asset.metadata.global_table":                false,
+				"asset.metadata.stream_enabled":              true,
+				"asset.metadata.deletion_protection_enabled": false,
+				"asset.metadata.provisioned_throughput.read_capacity_units":  int64(5),
+				"asset.metadata.provisioned_throughput.write_capacity_units": int64(10),
+				"asset.metadata.creation_date":                               creationDate,
+				"asset.metadata.sse_type":                                    "KMS",
+				"asset.metadata.tags." + tag_1_k:                             tag_1_v,
+				"cloud.account.id":                                           ownerID_1,
+				"cloud.provider":                                             "aws",
+				"cloud.region":                                               "eu-west-1",
+			},
+			Meta: mapstr.M{
+				"index": internal.GetDefaultIndexName(),
+			},
+		},
+	}
+
+	publisher := testutil.NewInMemoryPublisher()
+	err := collectDynamoDBAssets(context.Background(), client, "eu-west-1", logp.NewLogger("test"), publisher)
+	assert.NoError(t, err)
+	assert.Equal(t, expectedEvents, publisher.Events)
+}
+
+func TestGetDynamoDBTableMetadata_onDemand(t *testing.T) {
+	metadata := getDynamoDBTableMetadata(types.TableDescription{
+		TableStatus:           types.TableStatusActive,
+		BillingModeSummary:    &types.BillingModeSummary{BillingMode: types.BillingModePayPerRequest},
+		ProvisionedThroughput: &types.ProvisionedThroughputDescription{ReadCapacityUnits: aws.Int64(0)},
+		Replicas:              []types.ReplicaDescription{{RegionName: aws.String("us-east-1")}},
+	})
+	assert.Equal(t, mapstr.M{
+		"status":                      "ACTIVE",
+		"item_count":                  int64(0),
+		"size_bytes":                  int64(0),
+		"billing_mode":                "PAY_PER_REQUEST",
+		"global_table":                true,
+		"stream_enabled":              false,
+		"deletion_protection_enabled": false,
+	}, metadata)
+}
+
+func TestAssetsAWS_collectDynamoDBAssets_describeError(t *testing.T) {
+	client := mockDynamoDBClient{
+		tables: map[string]types.TableDescription{
+			dynamoDBTableName: {
+				TableArn:  &dynamoDBTableARN_1,
+				TableName: &dynamoDBTableName,
+			},
+		},
+		describeErrors: map[string]error{
+			"deleted": errors.New("ResourceNotFoundException: Requested resource not found"),
+		},
+	}
+
+	publisher := testutil.NewInMemoryPublisher()
+	err := collectDynamoDBAssets(context.Background(), client, "eu-west-1", logp.NewLogger("test"), publisher)
+	assert.NoError(t, err)
+	assert.Len(t, publisher.Events, 1)
+	assert.Equal(t, dynamoDBTableARN_1, publisher.Events[0].Fields["asset.id"])
+}
@@ -0,0 +1,94 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package aws
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/elastic/assetbeat/input/internal"
+	stateless "github.com/elastic/beats/v7/filebeat/input/v2/input-stateless"
+
+	"github.com/elastic/elastic-agent-libs/logp"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+func collectEBSAssets(ctx context.Context, client ec2.DescribeVolumesAPIClient, region string, accountID string, log *logp.Logger, publisher stateless.Publisher) error {
+	volumes, err := describeEBSVolumes(ctx, client)
+	if err != nil {
+		return err
+	}
+
+	for _, volume := range volumes {
+		publishEBSVolumeAsset(publisher, region, accountID, volume)
+	}
+
+	return nil
+}
+
+func publishEBSVolumeAsset(publisher stateless.Publisher, region string, accountID string, volume types.Volume) {
+	assetType := "aws.ebs.volume"
+	assetKind := "volume"
+	var parents []string
+	for _, attachment := range volume.Attachments {
+		if attachment.InstanceId != nil {
+			parents = append(parents, "host:"+*attachment.InstanceId)
+		}
+	}
+	options := []internal.AssetOption{
+		internal.WithAssetCloudProvider("aws"),
+		internal.WithAssetRegion(region),
+		internal.WithAssetAccountID(accountID),
+		internal.WithAssetKindAndID(assetKind, *volume.VolumeId),
+		internal.WithAssetType(assetType),
+		WithAssetTags(flattenEC2Tags(volume.Tags)),
+		internal.WithAssetMetadata(mapstr.M{
+			"size":              aws.ToInt32(volume.Size),
+			"volume_type":       string(volume.VolumeType),
+			"iops":              aws.ToInt32(volume.Iops),
+			"encrypted":         aws.ToBool(volume.Encrypted),
+			"state":             string(volume.State),
+			"availability_zone": aws.ToString(volume.AvailabilityZone),
+		}),
+	}
+	if parents != nil {
+		options = append(options, internal.WithAssetParents(parents))
+	}
+	internal.Publish(publisher, nil,
+		options...,
+	)
+}
+
+func describeEBSVolumes(ctx context.Context, client ec2.DescribeVolumesAPIClient) ([]types.Volume, error) {
+	volumes := make([]types.Volume, 0, 100)
+	paginator := ec2.NewDescribeVolumesPaginator(client, &ec2.DescribeVolumesInput{})
+	for paginator.HasMorePages() {
+		resp, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error describing EBS volumes: %w", err)
+		}
+
+		volumes = append(volumes, resp.Volumes...)
+	}
+
+	return volumes, nil
+}
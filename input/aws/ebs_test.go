@@ -0,0 +1,140 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package aws
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/elastic/assetbeat/input/internal"
+	"github.com/elastic/assetbeat/input/testutil"
+	"github.com/elastic/beats/v7/libbeat/beat"
+	"github.com/elastic/elastic-agent-libs/logp"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+var volumeID_2 = "vol-2222222"
+
+type mockDescribeVolumesAPI func(ctx context.Context, params *ec2.DescribeVolumesInput, optFns ...func(*ec2.Options)) (*ec2.DescribeVolumesOutput, error)
+
+func (m mockDescribeVolumesAPI) DescribeVolumes(ctx context.Context, params *ec2.DescribeVolumesInput, optFns ...func(*ec2.Options)) (*ec2.DescribeVolumesOutput, error) {
+	return m(ctx, params, optFns...)
+}
+
+func TestAssetsAWS_collectEBSAssets(t *testing.T) {
+	for _, tt := range []struct {
+		name           string
+		region         string
+		client         func(t *testing.T) ec2.DescribeVolumesAPIClient
+		expectedEvents []beat.Event
+	}{{
+		name:   "Test with attached and detached EBS volumes returned",
+		region: "eu-west-1",
+		client: func(t *testing.T) ec2.DescribeVolumesAPIClient {
+			return mockDescribeVolumesAPI(func(ctx context.Context, params *ec2.DescribeVolumesInput, optFns ...func(*ec2.Options)) (*ec2.DescribeVolumesOutput, error) {
+				t.Helper()
+				return &ec2.DescribeVolumesOutput{
+					Volumes: []types.Volume{
+						{
+							VolumeId:         &volumeID_1,
+							Size:             aws.Int32(8),
+							VolumeType:       types.VolumeTypeGp3,
+							Iops:             aws.Int32(3000),
+							Encrypted:        aws.Bool(true),
+							State:            types.VolumeStateInUse,
+							AvailabilityZone: aws.String("eu-west-1a"),
+							Attachments: []types.VolumeAttachment{
+								{InstanceId: &instanceID_1, VolumeId: &volumeID_1},
+							},
+							Tags: []types.Tag{{Key: &tag_1_k, Value: &tag_1_v}},
+						},
+						{
+							VolumeId:         &volumeID_2,
+							Size:             aws.Int32(100),
+							VolumeType:       types.VolumeTypeSt1,
+							Encrypted:        aws.Bool(false),
+							State:            types.VolumeStateAvailable,
+							AvailabilityZone: aws.String("eu-west-1b"),
+						},
+					},
+				}, nil
+			})
+		},
+		expectedEvents: []beat.Event{
+			{
+				Fields: mapstr.M{
+					"asset.ean":                        "volume:" + volumeID_1,
+					"asset.id":                         volumeID_1,
+					"asset.type":                       "aws.ebs.volume",
+					"asset.kind":                       "volume",
+					"asset.parents":                    []string{"host:" + instanceID_1},
+					"asset.metadata.size":              int32(8),
+					"asset.metadata.volume_type":       "gp3",
+					"asset.metadata.iops":              int32(3000),
+					"asset.metadata.encrypted":         true,
+					"asset.metadata.state":             "in-use",
+					"asset.metadata.availability_zone": "eu-west-1a",
+					"asset.metadata.tags." + tag_1_k:   tag_1_v,
+					"cloud.account.id":                 ownerID_1,
+					"cloud.provider":                   "aws",
+					"cloud.region":                     "eu-west-1",
+				},
+				Meta: mapstr.M{
+					"index": internal.GetDefaultIndexName(),
+				},
+			},
+			{
+				Fields: mapstr.M{
+					"asset.ean":                        "volume:" + volumeID_2,
+					"asset.id":                         volumeID_2,
+					"asset.type":                       "aws.ebs.volume",
+					"asset.kind":                       "volume",
+					"asset.metadata.size":              int32(100),
+					"asset.metadata.volume_type":       "st1",
+					"asset.metadata.iops":              int32(0),
+					"asset.metadata.encrypted":         false,
+					"asset.metadata.state":             "available",
+					"asset.metadata.availability_zone": "eu-west-1b",
+					"cloud.account.id":                 ownerID_1,
+					"cloud.provider":                   "aws",
+					"cloud.region":                     "eu-west-1",
+				},
+				Meta: mapstr.M{
+					"index": internal.GetDefaultIndexName(),
+				},
+			},
+		},
+	},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			publisher := testutil.NewInMemoryPublisher()
+
+			ctx := context.Background()
+			logger := logp.NewLogger("test")
+
+			err := collectEBSAssets(ctx, tt.client(t), tt.region, ownerID_1, logger, publisher)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedEvents, publisher.Events)
+		})
+	}
+}
@@ -28,16 +28,21 @@ import (
 	"github.com/elastic/elastic-agent-libs/logp"
 	"github.com/elastic/elastic-agent-libs/mapstr"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
 	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
 )
 
 type EC2Instance struct {
-	InstanceID string
-	OwnerID    string
-	SubnetID   string
-	Tags       []types.Tag
-	Metadata   mapstr.M
+	InstanceID       string
+	Name             string
+	OwnerID          string
+	SubnetID         string
+	VpcID            string
+	VolumeIDs        []string
+	SecurityGroupIDs []string
+	Tags             []types.Tag
+	Metadata         mapstr.M
 }
 
 func collectEC2Assets(ctx context.Context, client ec2.DescribeInstancesAPIClient, region string, log *logp.Logger, publisher stateless.Publisher) error {
@@ -47,32 +52,52 @@ func collectEC2Assets(ctx context.Context, client ec2.DescribeInstancesAPIClient
 	}
 
 	for _, instance := range instances {
-		var parents []string
-		if instance.SubnetID != "" {
-			parents = []string{"network:" + instance.SubnetID}
-		}
-		assetType := "aws.ec2.instance"
-		assetKind := "host"
-		options := []internal.AssetOption{
-			internal.WithAssetCloudProvider("aws"),
-			internal.WithAssetRegion(region),
-			internal.WithAssetAccountID(instance.OwnerID),
-			internal.WithAssetKindAndID(assetKind, instance.InstanceID),
-			internal.WithAssetType(assetType),
-			WithAssetTags(flattenEC2Tags(instance.Tags)),
-			internal.WithAssetMetadata(instance.Metadata),
-		}
-		if parents != nil {
-			options = append(options, internal.WithAssetParents(parents))
-		}
-		internal.Publish(publisher, nil,
-			options...,
-		)
+		publishEC2InstanceAsset(publisher, region, instance)
 	}
 
 	return nil
 }
 
+func publishEC2InstanceAsset(publisher stateless.Publisher, region string, instance EC2Instance) {
+	var parents []string
+	if instance.SubnetID != "" {
+		parents = append(parents, "network:"+instance.SubnetID)
+	}
+	if instance.VpcID != "" {
+		parents = append(parents, "network:"+instance.VpcID)
+	}
+	for _, groupID := range instance.SecurityGroupIDs {
+		parents = append(parents, "security_group:"+groupID)
+	}
+	var children []string
+	for _, volumeID := range instance.VolumeIDs {
+		children = append(children, "volume:"+volumeID)
+	}
+	assetType := "aws.ec2.instance"
+	assetKind := "host"
+	options := []internal.AssetOption{
+		internal.WithAssetCloudProvider("aws"),
+		internal.WithAssetRegion(region),
+		internal.WithAssetAccountID(instance.OwnerID),
+		internal.WithAssetKindAndID(assetKind, instance.InstanceID),
+		internal.WithAssetType(assetType),
+		WithAssetTags(flattenEC2Tags(instance.Tags)),
+		internal.WithAssetMetadata(instance.Metadata),
+	}
+	if instance.Name != "" {
+		options = append(options, internal.WithAssetName(instance.Name))
+	}
+	if parents != nil {
+		options = append(options, internal.WithAssetParents(parents))
+	}
+	if children != nil {
+		options = append(options, internal.WithAssetChildren(children))
+	}
+	internal.Publish(publisher, nil,
+		options...,
+	)
+}
+
 func describeEC2Instances(ctx context.Context, client ec2.DescribeInstancesAPIClient) ([]EC2Instance, error) {
 	instances := make([]EC2Instance, 0, 100)
 	paginator := ec2.NewDescribeInstancesPaginator(client, &ec2.DescribeInstancesInput{})
@@ -83,24 +108,77 @@ func describeEC2Instances(ctx context.Context, client ec2.DescribeInstancesAPICl
 		}
 		for _, reservation := range resp.Reservations {
 			instances = append(instances, util.Map(func(i types.Instance) EC2Instance {
-				inst := EC2Instance{
-					InstanceID: *i.InstanceId,
-					OwnerID:    *reservation.OwnerId,
-					Tags:       i.Tags,
-					Metadata: mapstr.M{
-						"state": string(i.State.Name),
-					},
-				}
-				if i.SubnetId != nil {
-					inst.SubnetID = *i.SubnetId
-				}
-				return inst
+				return newEC2Instance(*reservation.OwnerId, i)
 			}, reservation.Instances)...)
 		}
 	}
 	return instances, nil
 }
 
+// newEC2Instance extracts the details of an instance that are published as asset fields.
+func newEC2Instance(ownerID string, i types.Instance) EC2Instance {
+	inst := EC2Instance{
+		InstanceID: *i.InstanceId,
+		OwnerID:    ownerID,
+		Tags:       i.Tags,
+		Metadata: mapstr.M{
+			"state":              string(i.State.Name),
+			"instance_type":      string(i.InstanceType),
+			"image_id":           aws.ToString(i.ImageId),
+			"platform":           aws.ToString(i.PlatformDetails),
+			"private_ip_address": aws.ToString(i.PrivateIpAddress),
+			"private_dns_name":   aws.ToString(i.PrivateDnsName),
+			"lifecycle":          getEC2InstanceLifecycle(i),
+		},
+	}
+	if i.SubnetId != nil {
+		inst.SubnetID = *i.SubnetId
+	}
+	if i.VpcId != nil {
+		inst.VpcID = *i.VpcId
+	}
+	for _, tag := range i.Tags {
+		if aws.ToString(tag.Key) == "Name" {
+			inst.Name = aws.ToString(tag.Value)
+		}
+	}
+	if i.Placement != nil && i.Placement.AvailabilityZone != nil {
+		inst.Metadata["availability_zone"] = *i.Placement.AvailabilityZone
+	}
+	if i.LaunchTime != nil {
+		inst.Metadata["launch_time"] = *i.LaunchTime
+	}
+	if i.PublicIpAddress != nil {
+		inst.Metadata["public_ip_address"] = *i.PublicIpAddress
+	}
+	if i.IamInstanceProfile != nil && i.IamInstanceProfile.Arn != nil {
+		inst.Metadata["iam_instance_profile"] = *i.IamInstanceProfile.Arn
+	}
+	if i.KeyName != nil {
+		inst.Metadata["key_name"] = *i.KeyName
+	}
+	for _, blockDevice := range i.BlockDeviceMappings {
+		if blockDevice.Ebs != nil && blockDevice.Ebs.VolumeId != nil {
+			inst.VolumeIDs = append(inst.VolumeIDs, *blockDevice.Ebs.VolumeId)
+		}
+	}
+	for _, group := range i.SecurityGroups {
+		if group.GroupId != nil {
+			inst.SecurityGroupIDs = append(inst.SecurityGroupIDs, *group.GroupId)
+		}
+	}
+	return inst
+}
+
+// getEC2InstanceLifecycle returns whether the instance is a spot, scheduled or on-demand instance.
+// AWS only sets the instance lifecycle for spot and scheduled instances.
+func getEC2InstanceLifecycle(i types.Instance) string {
+	if i.InstanceLifecycle == "" {
+		return "on-demand"
+	}
+	return string(i.InstanceLifecycle)
+}
+
 // flattenEC2Tags converts the EC2 tag format to a simple `map[string]string`
 func flattenEC2Tags(tags []types.Tag) mapstr.M {
 	out := mapstr.M{}
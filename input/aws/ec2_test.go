@@ -21,7 +21,9 @@ import (
 	"context"
 	"github.com/elastic/assetbeat/input/internal"
 	"testing"
+	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
 	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
 	"github.com/stretchr/testify/assert"
@@ -38,6 +40,11 @@ var tag_1_k = "mykey"
 var tag_1_v = "myvalue"
 var subnetID1 = "mysubnetid1"
 var instanceID_2 = "i-2222222"
+var volumeID_1 = "vol-1111111"
+var vpcID1 = "myvpcid1"
+var nameTagKey = "Name"
+var instanceName_1 = "my-instance"
+var securityGroupID_1 = "sg-1111111"
 
 type mockDescribeInstancesAPI func(ctx context.Context, params *ec2.DescribeInstancesInput, optFns ...func(*ec2.Options)) (*ec2.DescribeInstancesOutput, error)
 
@@ -46,6 +53,7 @@ func (m mockDescribeInstancesAPI) DescribeInstances(ctx context.Context, params
 }
 
 func TestAssetsAWS_collectEC2Assets(t *testing.T) {
+	launchTime := time.Date(2023, 9, 1, 10, 0, 0, 0, time.UTC)
 	for _, tt := range []struct {
 		name           string
 		region         string
@@ -64,15 +72,39 @@ func TestAssetsAWS_collectEC2Assets(t *testing.T) {
 							OwnerId: &ownerID_1,
 							Instances: []types.Instance{
 								{
-									InstanceId: &instanceID_1,
-									State:      &types.InstanceState{Name: "running"},
+									InstanceId:         &instanceID_1,
+									State:              &types.InstanceState{Name: "running"},
+									InstanceType:       types.InstanceTypeT3Micro,
+									ImageId:            aws.String("ami-1111111"),
+									PlatformDetails:    aws.String("Linux/UNIX"),
+									Placement:          &types.Placement{AvailabilityZone: aws.String("eu-west-1a")},
+									LaunchTime:         &launchTime,
+									PrivateIpAddress:   aws.String("10.0.0.1"),
+									PublicIpAddress:    aws.String("52.0.0.1"),
+									PrivateDnsName:     aws.String("ip-10-0-0-1.eu-west-1.compute.internal"),
+									IamInstanceProfile: &types.IamInstanceProfile{Arn: aws.String("arn:aws:iam::11111111111111:instance-profile/my-profile")},
+									KeyName:            aws.String("my-key"),
+									InstanceLifecycle:  types.InstanceLifecycleTypeSpot,
 									Tags: []types.Tag{
 										{
 											Key:   &tag_1_k,
 											Value: &tag_1_v,
 										},
+										{
+											Key:   &nameTagKey,
+											Value: &instanceName_1,
+										},
 									},
 									SubnetId: &subnetID1,
+									VpcId:    &vpcID1,
+									SecurityGroups: []types.GroupIdentifier{
+										{GroupId: &securityGroupID_1},
+									},
+									BlockDeviceMappings: []types.InstanceBlockDeviceMapping{
+										{
+											Ebs: &types.EbsInstanceBlockDevice{VolumeId: &volumeID_1},
+										},
+									},
 								},
 								{
 									InstanceId: &instanceID_2,
@@ -88,18 +120,36 @@ func TestAssetsAWS_collectEC2Assets(t *testing.T) {
 		expectedEvents: []beat.Event{
 			{
 				Fields: mapstr.M{
-					"asset.ean":            "host:" + instanceID_1,
-					"asset.id":             instanceID_1,
-					"asset.metadata.state": "running",
-					"asset.type":           "aws.ec2.instance",
-					"asset.kind":           "host",
+					"asset.ean":                           "host:" + instanceID_1,
+					"asset.id":                            instanceID_1,
+					"asset.name":                          instanceName_1,
+					"asset.metadata.state":                "running",
+					"asset.metadata.instance_type":        "t3.micro",
+					"asset.metadata.image_id":             "ami-1111111",
+					"asset.metadata.platform":             "Linux/UNIX",
+					"asset.metadata.availability_zone":    "eu-west-1a",
+					"asset.metadata.launch_time":          launchTime,
+					"asset.metadata.private_ip_address":   "10.0.0.1",
+					"asset.metadata.public_ip_address":    "52.0.0.1",
+					"asset.metadata.private_dns_name":     "ip-10-0-0-1.eu-west-1.compute.internal",
+					"asset.metadata.iam_instance_profile": "arn:aws:iam::11111111111111:instance-profile/my-profile",
+					"asset.metadata.key_name":             "my-key",
+					"asset.metadata.lifecycle":            "spot",
+					"asset.type":                          "aws.ec2.instance",
+					"asset.kind":                          "host",
 					"asset.parents": []string{
 						"network:" + subnetID1,
+						"network:" + vpcID1,
+						"security_group:" + securityGroupID_1,
+					},
+					"asset.children": []string{
+						"volume:" + volumeID_1,
 					},
-					"asset.metadata.tags." + tag_1_k: tag_1_v,
-					"cloud.account.id":               "11111111111111",
-					"cloud.provider":                 "aws",
-					"cloud.region":                   "eu-west-1",
+					"asset.metadata.tags." + tag_1_k:    tag_1_v,
+					"asset.metadata.tags." + nameTagKey: instanceName_1,
+					"cloud.account.id":                  "11111111111111",
+					"cloud.provider":                    "aws",
+					"cloud.region":                      "eu-west-1",
 				},
 				Meta: mapstr.M{
 					"index": internal.GetDefaultIndexName(),
@@ -107,11 +157,17 @@ func TestAssetsAWS_collectEC2Assets(t *testing.T) {
 			},
 			{
 				Fields: mapstr.M{
-					"asset.ean":            "host:" + instanceID_2,
-					"asset.id":             instanceID_2,
-					"asset.metadata.state": "stopped",
-					"asset.type":           "aws.ec2.instance",
-					"asset.kind":           "host",
+					"asset.ean":                         "host:" + instanceID_2,
+					"asset.id":                          instanceID_2,
+					"asset.metadata.state":              "stopped",
+					"asset.metadata.instance_type":      "",
+					"asset.metadata.image_id":           "",
+					"asset.metadata.platform":           "",
+					"asset.metadata.private_ip_address": "",
+					"asset.metadata.private_dns_name":   "",
+					"asset.metadata.lifecycle":          "on-demand",
+					"asset.type":                        "aws.ec2.instance",
+					"asset.kind":                        "host",
 					"asset.parents": []string{
 						"network:" + subnetID1,
 					},
@@ -0,0 +1,330 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package aws
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/elastic/assetbeat/input/internal"
+	stateless "github.com/elastic/beats/v7/filebeat/input/v2/input-stateless"
+
+	"github.com/elastic/elastic-agent-libs/logp"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/aws/aws-sdk-go-v2/service/ecs/types"
+)
+
+// The ECS Describe* APIs accept a limited number of resources per call.
+const (
+	ecsDescribeClustersBatchSize           = 100
+	ecsDescribeServicesBatchSize           = 10
+	ecsDescribeTasksBatchSize              = 100
+	ecsDescribeContainerInstancesBatchSize = 100
+)
+
+// ecsClient is the subset of the ECS API used to build the cluster -> service -> task hierarchy.
+type ecsClient interface {
+	ecs.ListClustersAPIClient
+	ecs.ListServicesAPIClient
+	ecs.ListTasksAPIClient
+	ecs.DescribeServicesAPIClient
+	ecs.DescribeTasksAPIClient
+	DescribeClusters(ctx context.Context, params *ecs.DescribeClustersInput, optFns ...func(*ecs.Options)) (*ecs.DescribeClustersOutput, error)
+	DescribeContainerInstances(ctx context.Context, params *ecs.DescribeContainerInstancesInput, optFns ...func(*ecs.Options)) (*ecs.DescribeContainerInstancesOutput, error)
+}
+
+func collectECSAssets(ctx context.Context, client ecsClient, region string, assetTypes []string, log *logp.Logger, publisher stateless.Publisher) error {
+	clusterARNs, err := listECSClusters(ctx, client)
+	if err != nil {
+		return err
+	}
+	clusters, err := describeECSClusters(ctx, client, clusterARNs)
+	if err != nil {
+		return err
+	}
+
+	for _, cluster := range clusters {
+		clusterARN := aws.ToString(cluster.ClusterArn)
+		services, err := describeECSServices(ctx, client, clusterARN)
+		if err != nil {
+			log.Errorf("error collecting services of ECS cluster %s: %v", clusterARN, err)
+			continue
+		}
+		tasks, err := describeECSTasks(ctx, client, clusterARN)
+		if err != nil {
+			log.Errorf("error collecting tasks of ECS cluster %s: %v", clusterARN, err)
+			continue
+		}
+		hosts, err := getECSContainerInstanceHosts(ctx, client, clusterARN, tasks)
+		if err != nil {
+			log.Errorf("error collecting container instances of ECS cluster %s: %v", clusterARN, err)
+		}
+
+		accountID := ""
+		if parsedARN, err := arn.Parse(clusterARN); err == nil {
+			accountID = parsedARN.AccountID
+		}
+		clusterEAN := "cluster:" + clusterARN
+
+		serviceEANs := make(map[string]string, len(services))
+		serviceChildren := make(map[string][]string, len(services))
+		var clusterChildren []string
+		for _, service := range services {
+			serviceEAN := "service:" + aws.ToString(service.ServiceArn)
+			serviceEANs[aws.ToString(service.ServiceName)] = serviceEAN
+			clusterChildren = append(clusterChildren, serviceEAN)
+		}
+
+		taskParents := make(map[string][]string, len(tasks))
+		for _, task := range tasks {
+			taskARN := aws.ToString(task.TaskArn)
+			taskEAN := "container_group:" + taskARN
+			parent := clusterEAN
+			if serviceEAN, ok := serviceEANs[getECSTaskServiceName(task)]; ok {
+				parent = serviceEAN
+				serviceChildren[serviceEAN] = append(serviceChildren[serviceEAN], taskEAN)
+			} else {
+				clusterChildren = append(clusterChildren, taskEAN)
+			}
+			taskParents[taskARN] = []string{parent}
+			if instanceID, ok := hosts[aws.ToString(task.ContainerInstanceArn)]; ok {
+				taskParents[taskARN] = append(taskParents[taskARN], "host:"+instanceID)
+			}
+		}
+
+		if internal.IsTypeEnabled(assetTypes, "aws.ecs.cluster") {
+			internal.Publish(publisher, nil,
+				internal.WithAssetCloudProvider("aws"),
+				internal.WithAssetRegion(region),
+				internal.WithAssetAccountID(accountID),
+				internal.WithAssetKindAndID("cluster", clusterARN),
+				internal.WithAssetName(aws.ToString(cluster.ClusterName)),
+				internal.WithAssetType("aws.ecs.cluster"),
+				internal.WithAssetChildren(clusterChildren),
+				WithAssetTags(flattenECSTags(cluster.Tags)),
+				internal.WithAssetMetadata(mapstr.M{
+					"status":                               aws.ToString(cluster.Status),
+					"active_services_count":                cluster.ActiveServicesCount,
+					"running_tasks_count":                  cluster.RunningTasksCount,
+					"pending_tasks_count":                  cluster.PendingTasksCount,
+					"registered_container_instances_count": cluster.RegisteredContainerInstancesCount,
+					"capacity_providers":                   cluster.CapacityProviders,
+				}),
+			)
+		}
+
+		if internal.IsTypeEnabled(assetTypes, "aws.ecs.service") {
+			for _, service := range services {
+				serviceEAN := serviceEANs[aws.ToString(service.ServiceName)]
+				internal.Publish(publisher, nil,
+					internal.WithAssetCloudProvider("aws"),
+					internal.WithAssetRegion(region),
+					internal.WithAssetAccountID(accountID),
+					internal.WithAssetKindAndID("service", aws.ToString(service.ServiceArn)),
+					internal.WithAssetName(aws.ToString(service.ServiceName)),
+					internal.WithAssetType("aws.ecs.service"),
+					internal.WithAssetParents([]string{clusterEAN}),
+					internal.WithAssetChildren(serviceChildren[serviceEAN]),
+					WithAssetTags(flattenECSTags(service.Tags)),
+					internal.WithAssetMetadata(mapstr.M{
+						"status":              aws.ToString(service.Status),
+						"launch_type":         string(service.LaunchType),
+						"scheduling_strategy": string(service.SchedulingStrategy),
+						"task_definition":     aws.ToString(service.TaskDefinition),
+						"desired_count":       service.DesiredCount,
+						"running_count":       service.RunningCount,
+						"pending_count":       service.PendingCount,
+					}),
+				)
+			}
+		}
+
+		if internal.IsTypeEnabled(assetTypes, "aws.ecs.task") {
+			for _, task := range tasks {
+				taskARN := aws.ToString(task.TaskArn)
+				internal.Publish(publisher, nil,
+					internal.WithAssetCloudProvider("aws"),
+					internal.WithAssetRegion(region),
+					internal.WithAssetAccountID(accountID),
+					internal.WithAssetKindAndID("container_group", taskARN),
+					internal.WithAssetType("aws.ecs.task"),
+					internal.WithAssetParents(taskParents[taskARN]),
+					WithAssetTags(flattenECSTags(task.Tags)),
+					internal.WithAssetMetadata(mapstr.M{
+						"last_status":       aws.ToString(task.LastStatus),
+						"desired_status":    aws.ToString(task.DesiredStatus),
+						"launch_type":       string(task.LaunchType),
+						"task_definition":   aws.ToString(task.TaskDefinitionArn),
+						"availability_zone": aws.ToString(task.AvailabilityZone),
+						"cpu":               aws.ToString(task.Cpu),
+						"memory":            aws.ToString(task.Memory),
+					}),
+				)
+			}
+		}
+	}
+
+	return nil
+}
+
+func listECSClusters(ctx context.Context, client ecs.ListClustersAPIClient) ([]string, error) {
+	clusters := make([]string, 0, 100)
+	paginator := ecs.NewListClustersPaginator(client, &ecs.ListClustersInput{})
+	for paginator.HasMorePages() {
+		resp, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error listing ECS clusters: %w", err)
+		}
+		clusters = append(clusters, resp.ClusterArns...)
+	}
+	return clusters, nil
+}
+
+func describeECSClusters(ctx context.Context, client ecsClient, clusterARNs []string) ([]types.Cluster, error) {
+	var clusters []types.Cluster
+	for _, batch := range batchStrings(clusterARNs, ecsDescribeClustersBatchSize) {
+		resp, err := client.DescribeClusters(ctx, &ecs.DescribeClustersInput{
+			Clusters: batch,
+			Include:  []types.ClusterField{types.ClusterFieldTags},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error describing ECS clusters: %w", err)
+		}
+		clusters = append(clusters, resp.Clusters...)
+	}
+	return clusters, nil
+}
+
+func describeECSServices(ctx context.Context, client ecsClient, clusterARN string) ([]types.Service, error) {
+	var serviceARNs []string
+	paginator := ecs.NewListServicesPaginator(client, &ecs.ListServicesInput{Cluster: &clusterARN})
+	for paginator.HasMorePages() {
+		resp, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error listing ECS services: %w", err)
+		}
+		serviceARNs = append(serviceARNs, resp.ServiceArns...)
+	}
+
+	var services []types.Service
+	for _, batch := range batchStrings(serviceARNs, ecsDescribeServicesBatchSize) {
+		resp, err := client.DescribeServices(ctx, &ecs.DescribeServicesInput{
+			Cluster:  &clusterARN,
+			Services: batch,
+			Include:  []types.ServiceField{types.ServiceFieldTags},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error describing ECS services: %w", err)
+		}
+		services = append(services, resp.Services...)
+	}
+	return services, nil
+}
+
+func describeECSTasks(ctx context.Context, client ecsClient, clusterARN string) ([]types.Task, error) {
+	var taskARNs []string
+	paginator := ecs.NewListTasksPaginator(client, &ecs.ListTasksInput{Cluster: &clusterARN})
+	for paginator.HasMorePages() {
+		resp, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error listing ECS tasks: %w", err)
+		}
+		taskARNs = append(taskARNs, resp.TaskArns...)
+	}
+
+	var tasks []types.Task
+	for _, batch := range batchStrings(taskARNs, ecsDescribeTasksBatchSize) {
+		resp, err := client.DescribeTasks(ctx, &ecs.DescribeTasksInput{
+			Cluster: &clusterARN,
+			Tasks:   batch,
+			Include: []types.TaskField{types.TaskFieldTags},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error describing ECS tasks: %w", err)
+		}
+		tasks = append(tasks, resp.Tasks...)
+	}
+	return tasks, nil
+}
+
+// getECSContainerInstanceHosts maps the container instances the given tasks run on to their EC2 instance IDs.
+// Tasks using the Fargate launch type have no container instance, so they are not part of the result.
+func getECSContainerInstanceHosts(ctx context.Context, client ecsClient, clusterARN string, tasks []types.Task) (map[string]string, error) {
+	hosts := make(map[string]string)
+	seen := make(map[string]bool)
+	var containerInstanceARNs []string
+	for _, task := range tasks {
+		containerInstanceARN := aws.ToString(task.ContainerInstanceArn)
+		if containerInstanceARN != "" && !seen[containerInstanceARN] {
+			seen[containerInstanceARN] = true
+			containerInstanceARNs = append(containerInstanceARNs, containerInstanceARN)
+		}
+	}
+
+	for _, batch := range batchStrings(containerInstanceARNs, ecsDescribeContainerInstancesBatchSize) {
+		resp, err := client.DescribeContainerInstances(ctx, &ecs.DescribeContainerInstancesInput{
+			Cluster:            &clusterARN,
+			ContainerInstances: batch,
+		})
+		if err != nil {
+			return hosts, fmt.Errorf("error describing ECS container instances: %w", err)
+		}
+		for _, containerInstance := range resp.ContainerInstances {
+			if containerInstance.Ec2InstanceId != nil {
+				hosts[aws.ToString(containerInstance.ContainerInstanceArn)] = *containerInstance.Ec2InstanceId
+			}
+		}
+	}
+	return hosts, nil
+}
+
+// getECSTaskServiceName returns the name of the service that started a task, or an empty string
+// if the task was not started by a service. ECS sets the task group to `service:<service name>` for those tasks.
+func getECSTaskServiceName(task types.Task) string {
+	group := aws.ToString(task.Group)
+	if !strings.HasPrefix(group, "service:") {
+		return ""
+	}
+	return strings.TrimPrefix(group, "service:")
+}
+
+// flattenECSTags converts the ECS tag format to a simple `map[string]string`
+func flattenECSTags(tags []types.Tag) mapstr.M {
+	out := mapstr.M{}
+	for _, t := range tags {
+		out[*t.Key] = *t.Value
+	}
+	return out
+}
+
+// batchStrings splits items into consecutive batches of at most size elements.
+func batchStrings(items []string, size int) [][]string {
+	var batches [][]string
+	for size < len(items) {
+		items, batches = items[size:], append(batches, items[0:size:size])
+	}
+	if len(items) > 0 {
+		batches = append(batches, items)
+	}
+	return batches
+}
@@ -0,0 +1,240 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package aws
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/aws/aws-sdk-go-v2/service/ecs/types"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/elastic/assetbeat/input/internal"
+	"github.com/elastic/assetbeat/input/testutil"
+	"github.com/elastic/beats/v7/libbeat/beat"
+	"github.com/elastic/elastic-agent-libs/logp"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+const ecsClusterARN = "arn:aws:ecs:eu-west-1:11111111111111:cluster/my-cluster"
+const ecsServiceARN = "arn:aws:ecs:eu-west-1:11111111111111:service/my-cluster/my-service"
+const ecsTaskDefinitionARN = "arn:aws:ecs:eu-west-1:11111111111111:task-definition/my-task:1"
+const ecsTaskARN1 = "arn:aws:ecs:eu-west-1:11111111111111:task/my-cluster/1111"
+const ecsTaskARN2 = "arn:aws:ecs:eu-west-1:11111111111111:task/my-cluster/2222"
+const ecsTaskARN3 = "arn:aws:ecs:eu-west-1:11111111111111:task/my-cluster/3333"
+const ecsContainerInstanceARN = "arn:aws:ecs:eu-west-1:11111111111111:container-instance/my-cluster/4444"
+
+type mockECSClient struct {
+	clusters           []types.Cluster
+	services           []types.Service
+	tasks              []types.Task
+	containerInstances []types.ContainerInstance
+}
+
+func (m mockECSClient) ListClusters(ctx context.Context, params *ecs.ListClustersInput, optFns ...func(*ecs.Options)) (*ecs.ListClustersOutput, error) {
+	out := &ecs.ListClustersOutput{}
+	for _, c := range m.clusters {
+		out.ClusterArns = append(out.ClusterArns, *c.ClusterArn)
+	}
+	return out, nil
+}
+
+func (m mockECSClient) DescribeClusters(ctx context.Context, params *ecs.DescribeClustersInput, optFns ...func(*ecs.Options)) (*ecs.DescribeClustersOutput, error) {
+	return &ecs.DescribeClustersOutput{Clusters: m.clusters}, nil
+}
+
+func (m mockECSClient) ListServices(ctx context.Context, params *ecs.ListServicesInput, optFns ...func(*ecs.Options)) (*ecs.ListServicesOutput, error) {
+	out := &ecs.ListServicesOutput{}
+	for _, s := range m.services {
+		out.ServiceArns = append(out.ServiceArns, *s.ServiceArn)
+	}
+	return out, nil
+}
+
+func (m mockECSClient) DescribeServices(ctx context.Context, params *ecs.DescribeServicesInput, optFns ...func(*ecs.Options)) (*ecs.DescribeServicesOutput, error) {
+	return &ecs.DescribeServicesOutput{Services: m.services}, nil
+}
+
+func (m mockECSClient) ListTasks(ctx context.Context, params *ecs.ListTasksInput, optFns ...func(*ecs.Options)) (*ecs.ListTasksOutput, error) {
+	out := &ecs.ListTasksOutput{}
+	for _, t := range m.tasks {
+		out.TaskArns = append(out.TaskArns, *t.TaskArn)
+	}
+	return out, nil
+}
+
+func (m mockECSClient) DescribeTasks(ctx context.Context, params *ecs.DescribeTasksInput, optFns ...func(*ecs.Options)) (*ecs.DescribeTasksOutput, error) {
+	return &ecs.DescribeTasksOutput{Tasks: m.tasks}, nil
+}
+
+func (m mockECSClient) DescribeContainerInstances(ctx context.Context, params *ecs.DescribeContainerInstancesInput, optFns ...func(*ecs.Options)) (*ecs.DescribeContainerInstancesOutput, error) {
+	return &ecs.DescribeContainerInstancesOutput{ContainerInstances: m.containerInstances}, nil
+}
+
+func TestAssetsAWS_collectECSAssets(t *testing.T) {
+	client := mockECSClient{
+		clusters: []types.Cluster{
+			{
+				ClusterArn:        aws.String(ecsClusterARN),
+				ClusterName:       aws.String("my-cluster"),
+				Status:            aws.String("ACTIVE"),
+				RunningTasksCount: 3,
+				CapacityProviders: []string{"FARGATE"},
+				Tags:              []types.Tag{{Key: &tag_1_k, Value: &tag_1_v}},
+			},
+		},
+		services: []types.Service{
+			{
+				ServiceArn:         aws.String(ecsServiceARN),
+				ServiceName:        aws.String("my-service"),
+				Status:             aws.String("ACTIVE"),
+				SchedulingStrategy: types.SchedulingStrategyReplica,
+				TaskDefinition:     aws.String(ecsTaskDefinitionARN),
+				DesiredCount:       2,
+				RunningCount:       2,
+			},
+		},
+		tasks: []types.Task{
+			{
+				TaskArn:              aws.String(ecsTaskARN1),
+				Group:                aws.String("service:my-service"),
+				LaunchType:           types.LaunchTypeEc2,
+				ContainerInstanceArn: aws.String(ecsContainerInstanceARN),
+				TaskDefinitionArn:    aws.String(ecsTaskDefinitionARN),
+				LastStatus:           aws.String("RUNNING"),
+			},
+			{
+				TaskArn:           aws.String(ecsTaskARN2),
+				Group:             aws.String("service:my-service"),
+				LaunchType:        types.LaunchTypeFargate,
+				TaskDefinitionArn: aws.String(ecsTaskDefinitionARN),
+				LastStatus:        aws.String("RUNNING"),
+			},
+			{
+				TaskArn:           aws.String(ecsTaskARN3),
+				Group:             aws.String("family:my-task"),
+				LaunchType:        types.LaunchTypeFargate,
+				TaskDefinitionArn: aws.String(ecsTaskDefinitionARN),
+				LastStatus:        aws.String("RUNNING"),
+			},
+		},
+		containerInstances: []types.ContainerInstance{
+			{
+				ContainerInstanceArn: aws.String(ecsContainerInstanceARN),
+				Ec2InstanceId:        aws.String(instanceID1),
+			},
+		},
+	}
+
+	taskEvent := func(taskARN, launchType string, parents []string) beat.Event {
+		return beat.Event{
+			Fields: mapstr.M{
+				"asset.ean":                        "container_group:" + taskARN,
+				"asset.id":                         taskARN,
+				"asset.type":                       "aws.ecs.task",
+				"asset.kind":                       "container_group",
+				"asset.parents":                    parents,
+				"asset.metadata.last_status":       "RUNNING",
+				"asset.metadata.desired_status":    "",
+				"asset.metadata.launch_type":       launchType,
+				"asset.metadata.task_definition":   ecsTaskDefinitionARN,
+				"asset.metadata.availability_zone": "",
+				"asset.metadata.cpu":               "",
+				"asset.metadata.memory":            "",
+				"cloud.account.id":                 "11111111111111",
+				"cloud.provider":                   "aws",
+				"cloud.region":                     "eu-west-1",
+			},
+			Meta: mapstr.M{
+				"index": internal.GetDefaultIndexName(),
+			},
+		}
+	}
+
+	expectedEvents := []beat.Event{
+		{
+			Fields: mapstr.M{
+				"asset.ean":  "cluster:" + ecsClusterARN,
+				"asset.id":   ecsClusterARN,
+				"asset.name": "my-cluster",
+				"asset.type": "aws.ecs.cluster",
+				"asset.kind": "cluster",
+				"asset.children": []string{
+					"service:" + ecsServiceARN,
+					"container_group:" + ecsTaskARN3,
+				},
+				"asset.metadata.status":                               "ACTIVE",
+				"asset.metadata.active_services_count":                int32(0),
+				"asset.metadata.running_tasks_count":                  int32(3),
+				"asset.metadata.pending_tasks_count":                  int32(0),
+				"asset.metadata.registered_container_instances_count": int32(0),
+				"asset.metadata.capacity_providers":                   []string{"FARGATE"},
+				"asset.metadata.tags." + tag_1_k:                      tag_1_v,
+				"cloud.account.id":                                    "11111111111111",
+				"cloud.provider":                                      "aws",
+				"cloud.region":                                        "eu-west-1",
+			},
+			Meta: mapstr.M{
+				"index": internal.GetDefaultIndexName(),
+			},
+		},
+		{
+			Fields: mapstr.M{
+				"asset.ean":     "service:" + ecsServiceARN,
+				"asset.id":      ecsServiceARN,
+				"asset.name":    "my-service",
+				"asset.type":    "aws.ecs.service",
+				"asset.kind":    "service",
+				"asset.parents": []string{"cluster:" + ecsClusterARN},
+				"asset.children": []string{
+					"container_group:" + ecsTaskARN1,
+					"container_group:" + ecsTaskARN2,
+				},
+				"asset.metadata.status":              "ACTIVE",
+				"asset.metadata.launch_type":         "",
+				"asset.metadata.scheduling_strategy": "REPLICA",
+				"asset.metadata.task_definition":     ecsTaskDefinitionARN,
+				"asset.metadata.desired_count":       int32(2),
+				"asset.metadata.running_count":       int32(2),
+				"asset.metadata.pending_count":       int32(0),
+				"cloud.account.id":                   "11111111111111",
+				"cloud.provider":                     "aws",
+				"cloud.region":                       "eu-west-1",
+			},
+			Meta: mapstr.M{
+				"index": internal.GetDefaultIndexName(),
+			},
+		},
+		taskEvent(ecsTaskARN1, "EC2", []string{"service:" + ecsServiceARN, "host:" + instanceID1}),
+		taskEvent(ecsTaskARN2, "FARGATE", []string{"service:" + ecsServiceARN}),
+		taskEvent(ecsTaskARN3, "FARGATE", []string{"cluster:" + ecsClusterARN}),
+	}
+
+	publisher := testutil.NewInMemoryPublisher()
+	err := collectECSAssets(context.Background(), client, "eu-west-1", nil, logp.NewLogger("test"), publisher)
+	assert.NoError(t, err)
+	assert.Equal(t, expectedEvents, publisher.Events)
+}
+
+func TestBatchStrings(t *testing.T) {
+	assert.Nil(t, batchStrings(nil, 10))
+	assert.Equal(t, [][]string{{"a", "b"}, {"c", "d"}, {"e"}}, batchStrings([]string{"a", "b", "c", "d", "e"}, 2))
+	assert.Equal(t, [][]string{{"a", "b"}}, batchStrings([]string{"a", "b"}, 2))
+}
@@ -35,7 +35,18 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/eks/types"
 )
 
-func collectEKSAssets(ctx context.Context, cfg aws.Config, log *logp.Logger, publisher stateless.Publisher) error {
+type eksFargateProfilesAPIClient interface {
+	eks.ListFargateProfilesAPIClient
+	eks.DescribeFargateProfileAPIClient
+}
+
+type eksClusterResourcesAPIClient interface {
+	eks.ListNodegroupsAPIClient
+	eks.DescribeNodegroupAPIClient
+	eksFargateProfilesAPIClient
+}
+
+func collectEKSAssets(ctx context.Context, cfg aws.Config, assetTypes []string, log *logp.Logger, publisher stateless.Publisher) error {
 	eksClient := eks.NewFromConfig(cfg)
 	asgClient := autoscaling.NewFromConfig(cfg)
 	clusters, err := listEKSClusters(ctx, eksClient)
@@ -46,38 +57,159 @@ func collectEKSAssets(ctx context.Context, cfg aws.Config, log *logp.Logger, pub
 
 	for _, clusterDetail := range describeEKSClusters(log, ctx, clusters, eksClient) {
 		if clusterDetail != nil {
-			var parents []string
-			var children []string
-			if clusterDetail.ResourcesVpcConfig.VpcId != nil {
-				parents = []string{"network:" + *clusterDetail.ResourcesVpcConfig.VpcId}
-			}
-			nodeGroups, _ := listNodeGroups(ctx, *clusterDetail.Name, eksClient)
-			instances, _ := getInstanceIDsFromNodeGroup(ctx, *clusterDetail.Name, nodeGroups, eksClient, asgClient)
+			publishEKSCluster(ctx, clusterDetail, cfg.Region, eksClient, asgClient, assetTypes, log, publisher)
+		}
+	}
 
-			for _, instance := range instances {
-				children = []string{"host:" + instance}
-			}
+	return nil
+}
+
+// publishEKSCluster publishes an EKS cluster with its node groups and Fargate profiles. The EC2 instances
+// of the node groups are children of their node group, not of the cluster.
+func publishEKSCluster(ctx context.Context, clusterDetail *types.Cluster, region string, eksClient eksClusterResourcesAPIClient, asgClient autoscaling.DescribeAutoScalingGroupsAPIClient, assetTypes []string, log *logp.Logger, publisher stateless.Publisher) {
+	var parents []string
+	var children []string
+	if clusterDetail.ResourcesVpcConfig.VpcId != nil {
+		parents = []string{"network:" + *clusterDetail.ResourcesVpcConfig.VpcId}
+	}
+	clusterARN, _ := arn.Parse(*clusterDetail.Arn)
+	clusterEAN := "cluster:" + *clusterDetail.Arn
+
+	nodeGroupNames, err := listNodeGroups(ctx, *clusterDetail.Name, eksClient)
+	if err != nil {
+		log.Warnf("Error while retrieving node groups for EKS cluster %s: %+v", *clusterDetail.Name, err)
+	}
+	nodeGroups, err := describeNodeGroups(ctx, *clusterDetail.Name, nodeGroupNames, eksClient)
+	if err != nil {
+		log.Warnf("Error while describing node groups for EKS cluster %s: %+v", *clusterDetail.Name, err)
+	}
+	for _, nodeGroup := range nodeGroups {
+		nodeGroupEAN := "node_group:" + *nodeGroup.NodegroupArn
+		children = append(children, nodeGroupEAN)
 
-			clusterARN, _ := arn.Parse(*clusterDetail.Arn)
-			assetType := "k8s.cluster"
-			assetKind := "cluster"
+		var nodeGroupChildren []string
+		instances, err := getInstanceIDsFromNodeGroup(ctx, nodeGroup, asgClient)
+		if err != nil {
+			log.Warnf("Error while retrieving instances for EKS node group %s: %+v", *nodeGroup.NodegroupName, err)
+		}
+		for _, instance := range instances {
+			nodeGroupChildren = append(nodeGroupChildren, "host:"+instance)
+		}
+
+		if internal.IsTypeEnabled(assetTypes, "aws.eks.nodegroup") {
 			internal.Publish(publisher, nil,
 				internal.WithAssetCloudProvider("aws"),
-				internal.WithAssetRegion(cfg.Region),
+				internal.WithAssetRegion(region),
 				internal.WithAssetAccountID(clusterARN.AccountID),
-				internal.WithAssetKindAndID(assetKind, *clusterDetail.Arn),
-				internal.WithAssetType(assetType),
-				internal.WithAssetParents(parents),
-				internal.WithAssetChildren(children),
-				WithAssetTags(internal.ToMapstr(clusterDetail.Tags)),
-				internal.WithAssetMetadata(mapstr.M{
-					"status": clusterDetail.Status,
-				}),
+				internal.WithAssetKindAndID("node_group", *nodeGroup.NodegroupArn),
+				internal.WithAssetName(*nodeGroup.NodegroupName),
+				internal.WithAssetType("aws.eks.nodegroup"),
+				internal.WithAssetParents([]string{clusterEAN}),
+				internal.WithAssetChildren(nodeGroupChildren),
+				WithAssetTags(internal.ToMapstr(nodeGroup.Tags)),
+				internal.WithAssetMetadata(getNodeGroupMetadata(nodeGroup)),
 			)
 		}
 	}
 
-	return nil
+	fargateProfiles, err := describeFargateProfiles(ctx, *clusterDetail.Name, eksClient)
+	if err != nil {
+		log.Warnf("Error while retrieving Fargate profiles for EKS cluster %s: %+v", *clusterDetail.Name, err)
+	}
+	for _, fargateProfile := range fargateProfiles {
+		children = append(children, "fargate_profile:"+*fargateProfile.FargateProfileArn)
+
+		if internal.IsTypeEnabled(assetTypes, "aws.eks.fargate_profile") {
+			internal.Publish(publisher, nil,
+				internal.WithAssetCloudProvider("aws"),
+				internal.WithAssetRegion(region),
+				internal.WithAssetAccountID(clusterARN.AccountID),
+				internal.WithAssetKindAndID("fargate_profile", *fargateProfile.FargateProfileArn),
+				internal.WithAssetName(*fargateProfile.FargateProfileName),
+				internal.WithAssetType("aws.eks.fargate_profile"),
+				internal.WithAssetParents([]string{clusterEAN}),
+				WithAssetTags(internal.ToMapstr(fargateProfile.Tags)),
+				internal.WithAssetMetadata(getFargateProfileMetadata(fargateProfile)),
+			)
+		}
+	}
+
+	if internal.IsTypeEnabled(assetTypes, "k8s.cluster") {
+		assetType := "k8s.cluster"
+		assetKind := "cluster"
+		internal.Publish(publisher, nil,
+			internal.WithAssetCloudProvider("aws"),
+			internal.WithAssetRegion(region),
+			internal.WithAssetAccountID(clusterARN.AccountID),
+			internal.WithAssetKindAndID(assetKind, *clusterDetail.Arn),
+			internal.WithAssetType(assetType),
+			internal.WithAssetParents(parents),
+			internal.WithAssetChildren(children),
+			WithAssetTags(internal.ToMapstr(clusterDetail.Tags)),
+			internal.WithAssetMetadata(getEKSClusterMetadata(clusterDetail)),
+		)
+	}
+}
+
+func getEKSClusterMetadata(cluster *types.Cluster) mapstr.M {
+	metadata := mapstr.M{
+		"status":           cluster.Status,
+		"version":          aws.ToString(cluster.Version),
+		"platform_version": aws.ToString(cluster.PlatformVersion),
+	}
+	if cluster.ResourcesVpcConfig != nil {
+		metadata["endpoint_access"] = getEKSEndpointAccess(cluster.ResourcesVpcConfig)
+	}
+	if cluster.Identity != nil && cluster.Identity.Oidc != nil && cluster.Identity.Oidc.Issuer != nil {
+		metadata["oidc_issuer"] = *cluster.Identity.Oidc.Issuer
+	}
+	return metadata
+}
+
+// getEKSEndpointAccess summarizes how the Kubernetes API server endpoint of a cluster can be reached.
+func getEKSEndpointAccess(vpcConfig *types.VpcConfigResponse) string {
+	switch {
+	case vpcConfig.EndpointPublicAccess && vpcConfig.EndpointPrivateAccess:
+		return "public_and_private"
+	case vpcConfig.EndpointPrivateAccess:
+		return "private"
+	default:
+		return "public"
+	}
+}
+
+func getNodeGroupMetadata(nodeGroup types.Nodegroup) mapstr.M {
+	metadata := mapstr.M{
+		"status":          string(nodeGroup.Status),
+		"capacity_type":   string(nodeGroup.CapacityType),
+		"ami_type":        string(nodeGroup.AmiType),
+		"instance_types":  nodeGroup.InstanceTypes,
+		"version":         aws.ToString(nodeGroup.Version),
+		"release_version": aws.ToString(nodeGroup.ReleaseVersion),
+	}
+	if nodeGroup.ScalingConfig != nil {
+		metadata["scaling"] = mapstr.M{
+			"min_size":     aws.ToInt32(nodeGroup.ScalingConfig.MinSize),
+			"max_size":     aws.ToInt32(nodeGroup.ScalingConfig.MaxSize),
+			"desired_size": aws.ToInt32(nodeGroup.ScalingConfig.DesiredSize),
+		}
+	}
+	return metadata
+}
+
+func getFargateProfileMetadata(fargateProfile types.FargateProfile) mapstr.M {
+	var namespaces []string
+	for _, selector := range fargateProfile.Selectors {
+		if selector.Namespace != nil {
+			namespaces = append(namespaces, *selector.Namespace)
+		}
+	}
+	return mapstr.M{
+		"status":                 string(fargateProfile.Status),
+		"pod_execution_role_arn": aws.ToString(fargateProfile.PodExecutionRoleArn),
+		"selector_namespaces":    namespaces,
+		"subnets":                fargateProfile.Subnets,
+	}
 }
 
 func listNodeGroups(ctx context.Context, clusterName string, eksClient eks.ListNodegroupsAPIClient) ([]string, error) {
@@ -88,21 +220,48 @@ func listNodeGroups(ctx context.Context, clusterName string, eksClient eks.ListN
 	return resp.Nodegroups, nil
 }
 
-// Gets the underlying EC2 Instance IDs that are assigned to an EKS Node Group.
-// Note: this function returns no instance IDs if EKS Fargate is used, since they are not exposed by AWS.
-func getInstanceIDsFromNodeGroup(ctx context.Context, clusterName string, nodeGroups []string, eksClient eks.DescribeNodegroupAPIClient, asgClient autoscaling.DescribeAutoScalingGroupsAPIClient) ([]string, error) {
-	var result []string
+func describeNodeGroups(ctx context.Context, clusterName string, nodeGroups []string, eksClient eks.DescribeNodegroupAPIClient) ([]types.Nodegroup, error) {
+	var result []types.Nodegroup
 	for _, nodeGroup := range nodeGroups {
 		resp, err := eksClient.DescribeNodegroup(ctx, &eks.DescribeNodegroupInput{
 			ClusterName:   &clusterName,
 			NodegroupName: &nodeGroup,
 		})
 		if err != nil {
-			return nil, fmt.Errorf("error while describing Node Group %s: %w", nodeGroup, err)
+			return result, fmt.Errorf("error while describing Node Group %s: %w", nodeGroup, err)
+		}
+		result = append(result, *resp.Nodegroup)
+	}
+	return result, nil
+}
+
+// Gets the underlying EC2 Instance IDs that are assigned to an EKS Node Group.
+// Note: this function returns no instance IDs if EKS Fargate is used, since they are not exposed by AWS.
+func getInstanceIDsFromNodeGroup(ctx context.Context, nodeGroup types.Nodegroup, asgClient autoscaling.DescribeAutoScalingGroupsAPIClient) ([]string, error) {
+	if nodeGroup.Resources == nil {
+		return nil, nil
+	}
+	return getInstanceIDsFromEKSAsg(ctx, nodeGroup.Resources.AutoScalingGroups, asgClient)
+}
+
+func describeFargateProfiles(ctx context.Context, clusterName string, eksClient eksFargateProfilesAPIClient) ([]types.FargateProfile, error) {
+	var result []types.FargateProfile
+	paginator := eks.NewListFargateProfilesPaginator(eksClient, &eks.ListFargateProfilesInput{ClusterName: &clusterName})
+	for paginator.HasMorePages() {
+		resp, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error while listing Fargate profiles for cluster %s: %w", clusterName, err)
+		}
+		for _, fargateProfile := range resp.FargateProfileNames {
+			profile, err := eksClient.DescribeFargateProfile(ctx, &eks.DescribeFargateProfileInput{
+				ClusterName:        &clusterName,
+				FargateProfileName: &fargateProfile,
+			})
+			if err != nil {
+				return result, fmt.Errorf("error while describing Fargate profile %s: %w", fargateProfile, err)
+			}
+			result = append(result, *profile.FargateProfile)
 		}
-		autoscalingGroups := resp.Nodegroup.Resources.AutoScalingGroups
-		instances, _ := getInstanceIDsFromEKSAsg(ctx, autoscalingGroups, asgClient)
-		result = append(result, instances...)
 	}
 	return result, nil
 }
@@ -115,6 +274,10 @@ func getInstanceIDsFromEKSAsg(ctx context.Context, eksAutoscalingGroups []types.
 	for _, eksAsg := range eksAutoscalingGroups {
 		asgs = append(asgs, *eksAsg.Name)
 	}
+	// Describing an empty list of Autoscaling groups would return all the groups in the region
+	if len(asgs) == 0 {
+		return nil, nil
+	}
 	asgDetails, err := asgClient.DescribeAutoScalingGroups(ctx, &autoscaling.DescribeAutoScalingGroupsInput{AutoScalingGroupNames: asgs})
 	if err != nil {
 		return nil, fmt.Errorf("error while describing Autoscaling groups %q: %w", asgs, err)
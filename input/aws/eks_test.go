@@ -19,12 +19,15 @@ package aws
 
 import (
 	"context"
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/autoscaling"
 	typesAsg "github.com/aws/aws-sdk-go-v2/service/autoscaling/types"
 	"github.com/aws/aws-sdk-go-v2/service/eks"
 	"github.com/aws/aws-sdk-go-v2/service/eks/types"
 	"github.com/aws/smithy-go/middleware"
+	"github.com/elastic/assetbeat/input/testutil"
 	"github.com/elastic/elastic-agent-libs/logp"
+	"github.com/elastic/elastic-agent-libs/mapstr"
 	"github.com/stretchr/testify/assert"
 	"testing"
 )
@@ -52,6 +55,29 @@ func (m mockDescribeNodeGroupsAPI) DescribeNodegroup(ctx context.Context, params
 	return m(ctx, params, optFns...)
 }
 
+type mockFargateProfilesAPI struct {
+	profiles map[string]types.FargateProfile
+}
+
+func (m mockFargateProfilesAPI) ListFargateProfiles(ctx context.Context, params *eks.ListFargateProfilesInput, optFns ...func(*eks.Options)) (*eks.ListFargateProfilesOutput, error) {
+	out := &eks.ListFargateProfilesOutput{}
+	for name := range m.profiles {
+		out.FargateProfileNames = append(out.FargateProfileNames, name)
+	}
+	return out, nil
+}
+
+func (m mockFargateProfilesAPI) DescribeFargateProfile(ctx context.Context, params *eks.DescribeFargateProfileInput, optFns ...func(*eks.Options)) (*eks.DescribeFargateProfileOutput, error) {
+	profile := m.profiles[*params.FargateProfileName]
+	return &eks.DescribeFargateProfileOutput{FargateProfile: &profile}, nil
+}
+
+type mockEKSClusterResourcesAPI struct {
+	mockListNodeGroupsAPI
+	mockDescribeNodeGroupsAPI
+	mockFargateProfilesAPI
+}
+
 type mockDescribeAutoscalingGroupsAPI func(ctx context.Context, params *autoscaling.DescribeAutoScalingGroupsInput, optFns ...func(*autoscaling.Options)) (*autoscaling.DescribeAutoScalingGroupsOutput, error)
 
 func (m mockDescribeAutoscalingGroupsAPI) DescribeAutoScalingGroups(ctx context.Context, params *autoscaling.DescribeAutoScalingGroupsInput, optFns ...func(*autoscaling.Options)) (*autoscaling.DescribeAutoScalingGroupsOutput, error) {
@@ -195,9 +221,160 @@ func TestGetInstanceIDsFromNodeGroup(t *testing.T) {
 		},
 	} {
 		t.Run(tt.name, func(t *testing.T) {
-			instances, err := getInstanceIDsFromNodeGroup(tt.ctx, tt.cluster, tt.nodeGroups, tt.eksClient(t), tt.asgClient(t))
+			nodeGroups, err := describeNodeGroups(tt.ctx, tt.cluster, tt.nodeGroups, tt.eksClient(t))
 			assert.NoError(t, err)
+			assert.Len(t, nodeGroups, len(tt.nodeGroups))
+			var instances []string
+			for _, nodeGroup := range nodeGroups {
+				nodeGroupInstances, err := getInstanceIDsFromNodeGroup(tt.ctx, nodeGroup, tt.asgClient(t))
+				assert.NoError(t, err)
+				instances = append(instances, nodeGroupInstances...)
+			}
 			assert.Equal(t, instances, tt.instanceIDs)
 		})
 	}
 }
+
+func TestGetInstanceIDsFromNodeGroupWithoutAutoscalingGroups(t *testing.T) {
+	asgClient := mockDescribeAutoscalingGroupsAPI(func(ctx context.Context, params *autoscaling.DescribeAutoScalingGroupsInput, optFns ...func(*autoscaling.Options)) (*autoscaling.DescribeAutoScalingGroupsOutput, error) {
+		t.Fatal("autoscaling groups should not be described when the node group has none")
+		return nil, nil
+	})
+	instances, err := getInstanceIDsFromNodeGroup(context.Background(), types.Nodegroup{Resources: &types.NodegroupResources{}}, asgClient)
+	assert.NoError(t, err)
+	assert.Empty(t, instances)
+}
+
+func TestDescribeFargateProfiles(t *testing.T) {
+	profileArn := "arn:aws:eks:eu-west-1:12345678:fargateprofile/test-cluster/test-profile/1111"
+	client := mockFargateProfilesAPI{
+		profiles: map[string]types.FargateProfile{
+			"test-profile": {
+				FargateProfileArn:  &profileArn,
+				FargateProfileName: aws.String("test-profile"),
+				Status:             types.FargateProfileStatusActive,
+				Selectors:          []types.FargateProfileSelector{{Namespace: aws.String("default")}},
+			},
+		},
+	}
+	profiles, err := describeFargateProfiles(context.Background(), "test-cluster", client)
+	assert.NoError(t, err)
+	assert.Len(t, profiles, 1)
+	assert.Equal(t, profileArn, *profiles[0].FargateProfileArn)
+	assert.Equal(t, mapstr.M{
+		"status":                 "ACTIVE",
+		"pod_execution_role_arn": "",
+		"selector_namespaces":    []string{"default"},
+		"subnets":                []string(nil),
+	}, getFargateProfileMetadata(profiles[0]))
+}
+
+func TestGetEKSClusterMetadata(t *testing.T) {
+	cluster := &types.Cluster{
+		Status:          types.ClusterStatusActive,
+		Version:         aws.String("1.27"),
+		PlatformVersion: aws.String("eks.5"),
+		ResourcesVpcConfig: &types.VpcConfigResponse{
+			EndpointPublicAccess:  true,
+			EndpointPrivateAccess: true,
+		},
+		Identity: &types.Identity{
+			Oidc: &types.OIDC{Issuer: aws.String("https://oidc.eks.eu-west-1.amazonaws.com/id/1111")},
+		},
+	}
+	assert.Equal(t, mapstr.M{
+		"status":           types.ClusterStatusActive,
+		"version":          "1.27",
+		"platform_version": "eks.5",
+		"endpoint_access":  "public_and_private",
+		"oidc_issuer":      "https://oidc.eks.eu-west-1.amazonaws.com/id/1111",
+	}, getEKSClusterMetadata(cluster))
+}
+
+func TestGetNodeGroupMetadata(t *testing.T) {
+	nodeGroup := types.Nodegroup{
+		Status:         types.NodegroupStatusActive,
+		CapacityType:   types.CapacityTypesOnDemand,
+		AmiType:        types.AMITypesAl2X8664,
+		InstanceTypes:  []string{"t3.medium"},
+		Version:        aws.String("1.27"),
+		ReleaseVersion: aws.String("1.27.4-20230825"),
+		ScalingConfig: &types.NodegroupScalingConfig{
+			MinSize:     aws.Int32(1),
+			MaxSize:     aws.Int32(3),
+			DesiredSize: aws.Int32(2),
+		},
+	}
+	assert.Equal(t, mapstr.M{
+		"status":          "ACTIVE",
+		"capacity_type":   "ON_DEMAND",
+		"ami_type":        "AL2_x86_64",
+		"instance_types":  []string{"t3.medium"},
+		"version":         "1.27",
+		"release_version": "1.27.4-20230825",
+		"scaling": mapstr.M{
+			"min_size":     int32(1),
+			"max_size":     int32(3),
+			"desired_size": int32(2),
+		},
+	}, getNodeGroupMetadata(nodeGroup))
+}
+
+func TestPublishEKSCluster(t *testing.T) {
+	clusterArn := clusterArnPrefix + "test-cluster"
+	nodeGroupArn := "arn:aws:eks:eu-west-1:12345678:nodegroup/test-cluster/test-nodegroup/1111"
+	profileArn := "arn:aws:eks:eu-west-1:12345678:fargateprofile/test-cluster/test-profile/1111"
+	eksClient := mockEKSClusterResourcesAPI{
+		mockListNodeGroupsAPI: func(ctx context.Context, params *eks.ListNodegroupsInput, optFns ...func(*eks.Options)) (*eks.ListNodegroupsOutput, error) {
+			return &eks.ListNodegroupsOutput{Nodegroups: []string{"test-nodegroup"}}, nil
+		},
+		mockDescribeNodeGroupsAPI: func(ctx context.Context, params *eks.DescribeNodegroupInput, optFns ...func(*eks.Options)) (*eks.DescribeNodegroupOutput, error) {
+			return &eks.DescribeNodegroupOutput{
+				Nodegroup: &types.Nodegroup{
+					NodegroupArn:  &nodeGroupArn,
+					NodegroupName: params.NodegroupName,
+					Resources: &types.NodegroupResources{
+						AutoScalingGroups: []types.AutoScalingGroup{{Name: aws.String("test-asg")}},
+					},
+				},
+			}, nil
+		},
+		mockFargateProfilesAPI: mockFargateProfilesAPI{
+			profiles: map[string]types.FargateProfile{
+				"test-profile": {FargateProfileArn: &profileArn, FargateProfileName: aws.String("test-profile")},
+			},
+		},
+	}
+	asgClient := mockDescribeAutoscalingGroupsAPI(func(ctx context.Context, params *autoscaling.DescribeAutoScalingGroupsInput, optFns ...func(*autoscaling.Options)) (*autoscaling.DescribeAutoScalingGroupsOutput, error) {
+		return &autoscaling.DescribeAutoScalingGroupsOutput{
+			AutoScalingGroups: []typesAsg.AutoScalingGroup{
+				{Instances: []typesAsg.Instance{{InstanceId: &instanceID1}, {InstanceId: &instanceID2}}},
+			},
+		}, nil
+	})
+	cluster := &types.Cluster{
+		Arn:                &clusterArn,
+		Name:               aws.String("test-cluster"),
+		ResourcesVpcConfig: &types.VpcConfigResponse{VpcId: aws.String("vpc-1")},
+	}
+
+	publisher := testutil.NewInMemoryPublisher()
+	publishEKSCluster(context.Background(), cluster, "eu-west-1", eksClient, asgClient, nil, logp.NewLogger("test"), publisher)
+	assert.Len(t, publisher.Events, 3)
+
+	nodeGroup := publisher.Events[0].Fields
+	assert.Equal(t, "node_group:"+nodeGroupArn, nodeGroup["asset.ean"])
+	assert.Equal(t, []string{"cluster:" + clusterArn}, nodeGroup["asset.parents"])
+	assert.Equal(t, []string{"host:" + instanceID1, "host:" + instanceID2}, nodeGroup["asset.children"])
+
+	fargateProfile := publisher.Events[1].Fields
+	assert.Equal(t, "fargate_profile:"+profileArn, fargateProfile["asset.ean"])
+	assert.Equal(t, "fargate_profile", fargateProfile["asset.kind"])
+	assert.Equal(t, []string{"cluster:" + clusterArn}, fargateProfile["asset.parents"])
+
+	// the instances are only children of their node group
+	clusterFields := publisher.Events[2].Fields
+	assert.Equal(t, "cluster:"+clusterArn, clusterFields["asset.ean"])
+	assert.Equal(t, []string{"network:vpc-1"}, clusterFields["asset.parents"])
+	assert.Equal(t, []string{"node_group:" + nodeGroupArn, "fargate_profile:" + profileArn}, clusterFields["asset.children"])
+}
@@ -0,0 +1,166 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package aws
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/elastic/assetbeat/input/internal"
+	stateless "github.com/elastic/beats/v7/filebeat/input/v2/input-stateless"
+
+	"github.com/elastic/elastic-agent-libs/logp"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	"github.com/aws/aws-sdk-go-v2/service/elasticache"
+	"github.com/aws/aws-sdk-go-v2/service/elasticache/types"
+)
+
+type elastiCacheClient interface {
+	elasticache.DescribeCacheClustersAPIClient
+	elasticache.DescribeCacheSubnetGroupsAPIClient
+	ListTagsForResource(ctx context.Context, params *elasticache.ListTagsForResourceInput, optFns ...func(*elasticache.Options)) (*elasticache.ListTagsForResourceOutput, error)
+}
+
+func collectElastiCacheAssets(ctx context.Context, client elastiCacheClient, region string, log *logp.Logger, publisher stateless.Publisher) error {
+	clusters, err := describeElastiCacheClusters(ctx, client)
+	if err != nil {
+		return err
+	}
+	subnetGroups, err := describeElastiCacheSubnetGroups(ctx, client)
+	if err != nil {
+		return err
+	}
+
+	assetType := "aws.elasticache.cluster"
+	assetKind := "cluster"
+	for _, cluster := range clusters {
+		clusterARN, err := arn.Parse(*cluster.ARN)
+		if err != nil {
+			log.Warnf("skipping ElastiCache cluster with invalid ARN %s: %v", *cluster.ARN, err)
+			continue
+		}
+		var parents []string
+		if subnetGroup, ok := subnetGroups[aws.ToString(cluster.CacheSubnetGroupName)]; ok {
+			for _, subnet := range subnetGroup.Subnets {
+				if subnet.SubnetIdentifier != nil {
+					parents = append(parents, "network:"+*subnet.SubnetIdentifier)
+				}
+			}
+			if subnetGroup.VpcId != nil {
+				parents = append(parents, "network:"+*subnetGroup.VpcId)
+			}
+		}
+		for _, group := range cluster.SecurityGroups {
+			if group.SecurityGroupId != nil {
+				parents = append(parents, "security_group:"+*group.SecurityGroupId)
+			}
+		}
+		tags, err := client.ListTagsForResource(ctx, &elasticache.ListTagsForResourceInput{ResourceName: cluster.ARN})
+		var tagList []types.Tag
+		if err != nil {
+			log.Warnf("error getting tags of ElastiCache cluster %s: %v", *cluster.CacheClusterId, err)
+		} else {
+			tagList = tags.TagList
+		}
+		options := []internal.AssetOption{
+			internal.WithAssetCloudProvider("aws"),
+			internal.WithAssetRegion(region),
+			internal.WithAssetAccountID(clusterARN.AccountID),
+			internal.WithAssetKindAndID(assetKind, *cluster.ARN),
+			internal.WithAssetType(assetType),
+			internal.WithAssetName(aws.ToString(cluster.CacheClusterId)),
+			WithAssetTags(flattenElastiCacheTags(tagList)),
+			internal.WithAssetMetadata(getElastiCacheClusterMetadata(cluster)),
+		}
+		if parents != nil {
+			options = append(options, internal.WithAssetParents(parents))
+		}
+		internal.Publish(publisher, nil,
+			options...,
+		)
+	}
+
+	return nil
+}
+
+func getElastiCacheClusterMetadata(cluster types.CacheCluster) mapstr.M {
+	metadata := mapstr.M{
+		"status":                     aws.ToString(cluster.CacheClusterStatus),
+		"engine":                     aws.ToString(cluster.Engine),
+		"engine_version":             aws.ToString(cluster.EngineVersion),
+		"node_type":                  aws.ToString(cluster.CacheNodeType),
+		"num_cache_nodes":            aws.ToInt32(cluster.NumCacheNodes),
+		"at_rest_encryption_enabled": aws.ToBool(cluster.AtRestEncryptionEnabled),
+		"transit_encryption_enabled": aws.ToBool(cluster.TransitEncryptionEnabled),
+	}
+	if cluster.PreferredAvailabilityZone != nil {
+		metadata["availability_zone"] = *cluster.PreferredAvailabilityZone
+	}
+	if cluster.ReplicationGroupId != nil {
+		metadata["replication_group_id"] = *cluster.ReplicationGroupId
+	}
+	if cluster.CacheClusterCreateTime != nil {
+		metadata["creation_date"] = *cluster.CacheClusterCreateTime
+	}
+	return metadata
+}
+
+func describeElastiCacheClusters(ctx context.Context, client elasticache.DescribeCacheClustersAPIClient) ([]types.CacheCluster, error) {
+	clusters := make([]types.CacheCluster, 0, 100)
+	paginator := elasticache.NewDescribeCacheClustersPaginator(client, &elasticache.DescribeCacheClustersInput{})
+	for paginator.HasMorePages() {
+		resp, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error describing ElastiCache clusters: %w", err)
+		}
+
+		clusters = append(clusters, resp.CacheClusters...)
+	}
+
+	return clusters, nil
+}
+
+// describeElastiCacheSubnetGroups returns the subnet groups, indexed by name
+func describeElastiCacheSubnetGroups(ctx context.Context, client elasticache.DescribeCacheSubnetGroupsAPIClient) (map[string]types.CacheSubnetGroup, error) {
+	subnetGroups := map[string]types.CacheSubnetGroup{}
+	paginator := elasticache.NewDescribeCacheSubnetGroupsPaginator(client, &elasticache.DescribeCacheSubnetGroupsInput{})
+	for paginator.HasMorePages() {
+		resp, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error describing ElastiCache subnet groups: %w", err)
+		}
+
+		for _, subnetGroup := range resp.CacheSubnetGroups {
+			subnetGroups[aws.ToString(subnetGroup.CacheSubnetGroupName)] = subnetGroup
+		}
+	}
+
+	return subnetGroups, nil
+}
+
+// flattenElastiCacheTags converts the ElastiCache tag format to a simple `map[string]string`
+func flattenElastiCacheTags(tags []types.Tag) mapstr.M {
+	out := mapstr.M{}
+	for _, t := range tags {
+		out[*t.Key] = *t.Value
+	}
+	return out
+}
@@ -0,0 +1,164 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package aws
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/elasticache"
+	"github.com/aws/aws-sdk-go-v2/service/elasticache/types"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/elastic/assetbeat/input/internal"
+	"github.com/elastic/assetbeat/input/testutil"
+	"github.com/elastic/beats/v7/libbeat/beat"
+	"github.com/elastic/elastic-agent-libs/logp"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+var elastiCacheClusterARN_1 = "arn:aws:elasticache:eu-west-1:11111111111111:cluster:cache-1"
+var elastiCacheClusterARN_2 = "arn:aws:elasticache:eu-west-1:11111111111111:cluster:cache-2"
+
+type mockElastiCacheClient struct {
+	clusters     []types.CacheCluster
+	subnetGroups []types.CacheSubnetGroup
+	tags         map[string][]types.Tag
+}
+
+func (m mockElastiCacheClient) DescribeCacheClusters(ctx context.Context, params *elasticache.DescribeCacheClustersInput, optFns ...func(*elasticache.Options)) (*elasticache.DescribeCacheClustersOutput, error) {
+	return &elasticache.DescribeCacheClustersOutput{CacheClusters: m.clusters}, nil
+}
+
+func (m mockElastiCacheClient) DescribeCacheSubnetGroups(ctx context.Context, params *elasticache.DescribeCacheSubnetGroupsInput, optFns ...func(*elasticache.Options)) (*elasticache.DescribeCacheSubnetGroupsOutput, error) {
+	return &elasticache.DescribeCacheSubnetGroupsOutput{CacheSubnetGroups: m.subnetGroups}, nil
+}
+
+func (m mockElastiCacheClient) ListTagsForResource(ctx context.Context, params *elasticache.ListTagsForResourceInput, optFns ...func(*elasticache.Options)) (*elasticache.ListTagsForResourceOutput, error) {
+	tags, ok := m.tags[*params.ResourceName]
+	if !ok {
+		return nil, errors.New("access denied")
+	}
+	return &elasticache.ListTagsForResourceOutput{TagList: tags}, nil
+}
+
+func TestAssetsAWS_collectElastiCacheAssets(t *testing.T) {
+	client := mockElastiCacheClient{
+		clusters: []types.CacheCluster{
+			{
+				ARN:                       &elastiCacheClusterARN_1,
+				CacheClusterId:            aws.String("cache-1"),
+				CacheClusterStatus:        aws.String("available"),
+				CacheNodeType:             aws.String("cache.t3.micro"),
+				Engine:                    aws.String("redis"),
+				EngineVersion:             aws.String("7.0.7"),
+				NumCacheNodes:             aws.Int32(1),
+				PreferredAvailabilityZone: aws.String("eu-west-1a"),
+				ReplicationGroupId:        aws.String("cache"),
+				CacheSubnetGroupName:      aws.String("private"),
+				SecurityGroups:            []types.SecurityGroupMembership{{SecurityGroupId: &securityGroupID_1}},
+				TransitEncryptionEnabled:  aws.Bool(true),
+			},
+			{
+				ARN:                &elastiCacheClusterARN_2,
+				CacheClusterId:     aws.String("cache-2"),
+				CacheClusterStatus: aws.String("creating"),
+				CacheNodeType:      aws.String("cache.t3.micro"),
+				Engine:             aws.String("memcached"),
+				EngineVersion:      aws.String("1.6.17"),
+				NumCacheNodes:      aws.Int32(2),
+			},
+		},
+		subnetGroups: []types.CacheSubnetGroup{
+			{
+				CacheSubnetGroupName: aws.String("private"),
+				VpcId:                &vpcID1,
+				Subnets: []types.Subnet{
+					{SubnetIdentifier: &subnetID1},
+					{SubnetIdentifier: &subnetID2},
+				},
+			},
+		},
+		tags: map[string][]types.Tag{
+			elastiCacheClusterARN_1: {{Key: &tag_1_k, Value: &tag_1_v}},
+		},
+	}
+
+	expectedEvents := []beat.Event{
+		{
+			Fields: mapstr.M{
+				"asset.ean":  "cluster:" + elastiCacheClusterARN_1,
+				"asset.id":   elastiCacheClusterARN_1,
+				"asset.name": "cache-1",
+				"asset.type": "aws.elasticache.cluster",
+				"asset.kind": "cluster",
+				"asset.parents": []string{
+					"network:" + subnetID1,
+					"network:" + subnetID2,
+					"network:" + vpcID1,
+					"security_group:" + securityGroupID_1,
+				},
+				"asset.metadata.status":                     "available",
+				"asset.metadata.engine":                     "redis",
+				"asset.metadata.engine_version":             "7.0.7",
+				"asset.metadata.node_type":                  "cache.t3.micro",
+				"asset.metadata.num_cache_nodes":            int32(1),
+				"asset.metadata.at_rest_encryption_enabled": false,
+				"asset.metadata.transit_encryption_enabled": true,
+				"asset.metadata.availability_zone":          "eu-west-1a",
+				"asset.metadata.replication_group_id":       "cache",
+				"asset.metadata.tags." + tag_1_k:            tag_1_v,
+				"cloud.account.id":                          ownerID_1,
+				"cloud.provider":                            "aws",
+				"cloud.region":                              "eu-west-1",
+			},
+			Meta: mapstr.M{
+				"index": internal.GetDefaultIndexName(),
+			},
+		},
+		{
+			Fields: mapstr.M{
+				"asset.ean":                      "cluster:" + elastiCacheClusterARN_2,
+				"asset.id":                       elastiCacheClusterARN_2,
+				"asset.name":                     "cache-2",
+				"asset.type":                     "aws.elasticache.cluster",
+				"asset.kind":                     "cluster",
+				"asset.metadata.status":          "creating",
+				"asset.metadata.engine":          "memcached",
+				"asset.metadata.engine_version":  "1.6.17",
+				"asset.metadata.node_type":       "cache.t3.micro",
+				"asset.metadata.num_cache_nodes": int32(2),
+				"asset.metadata.at_rest_encryption_enabled": false,
+				"asset.metadata.transit_encryption_enabled": false,
+				"cloud.account.id":                          ownerID_1,
+				"cloud.provider":                            "aws",
+				"cloud.region":                              "eu-west-1",
+			},
+			Meta: mapstr.M{
+				"index": internal.GetDefaultIndexName(),
+			},
+		},
+	}
+
+	publisher := testutil.NewInMemoryPublisher()
+	err := collectElastiCacheAssets(context.Background(), client, "eu-west-1", logp.NewLogger("test"), publisher)
+	assert.NoError(t, err)
+	assert.Equal(t, expectedEvents, publisher.Events)
+}
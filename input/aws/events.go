@@ -0,0 +1,282 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/elastic/assetbeat/input/internal"
+	stateless "github.com/elastic/beats/v7/filebeat/input/v2/input-stateless"
+
+	"github.com/elastic/elastic-agent-libs/logp"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	sqstypes "github.com/aws/aws-sdk-go-v2/service/sqs/types"
+)
+
+const (
+	ec2StateChangeDetailType = "EC2 Instance State-change Notification"
+	cloudTrailAPICallType    = "AWS API Call via CloudTrail"
+
+	sqsWaitTimeSeconds = 20
+	sqsErrorBackoff    = 10 * time.Second
+)
+
+type sqsAPIClient interface {
+	ReceiveMessage(ctx context.Context, params *sqs.ReceiveMessageInput, optFns ...func(*sqs.Options)) (*sqs.ReceiveMessageOutput, error)
+	DeleteMessage(ctx context.Context, params *sqs.DeleteMessageInput, optFns ...func(*sqs.Options)) (*sqs.DeleteMessageOutput, error)
+}
+
+type ec2EventsAPIClient interface {
+	ec2.DescribeInstancesAPIClient
+	ec2.DescribeVpcsAPIClient
+	ec2.DescribeSubnetsAPIClient
+}
+
+// awsEvent is an EventBridge event, as delivered to SQS by an EventBridge rule.
+type awsEvent struct {
+	Source     string          `json:"source"`
+	DetailType string          `json:"detail-type"`
+	Account    string          `json:"account"`
+	Region     string          `json:"region"`
+	Detail     json.RawMessage `json:"detail"`
+}
+
+type ec2StateChangeDetail struct {
+	InstanceID string `json:"instance-id"`
+	State      string `json:"state"`
+}
+
+type cloudTrailDetail struct {
+	EventName         string `json:"eventName"`
+	RequestParameters struct {
+		VpcID    string `json:"vpcId"`
+		SubnetID string `json:"subnetId"`
+	} `json:"requestParameters"`
+	ResponseElements struct {
+		Vpc struct {
+			VpcID string `json:"vpcId"`
+		} `json:"vpc"`
+		Subnet struct {
+			SubnetID string `json:"subnetId"`
+		} `json:"subnet"`
+	} `json:"responseElements"`
+}
+
+// awsEventHandler publishes the assets affected by EventBridge events between full collections,
+// describing each affected resource, or publishing a tombstone for it when it was deleted.
+type awsEventHandler struct {
+	assetTypes []string
+	ec2Client  func(ctx context.Context, region string) (ec2EventsAPIClient, error)
+	log        *logp.Logger
+	publisher  stateless.Publisher
+}
+
+// consumeAWSEvents receives the events of an SQS queue until the context is cancelled.
+// Messages are only deleted from the queue once handled, so that failures are retried after the visibility timeout.
+func consumeAWSEvents(ctx context.Context, client sqsAPIClient, queueURL string, handler *awsEventHandler) {
+	for ctx.Err() == nil {
+		resp, err := client.ReceiveMessage(ctx, &sqs.ReceiveMessageInput{
+			QueueUrl:            aws.String(queueURL),
+			MaxNumberOfMessages: 10,
+			WaitTimeSeconds:     sqsWaitTimeSeconds,
+		})
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			handler.log.Errorf("error receiving messages from SQS queue %s: %v", queueURL, err)
+			select {
+			case <-ctx.Done():
+			case <-time.After(sqsErrorBackoff):
+			}
+			continue
+		}
+
+		for _, message := range resp.Messages {
+			if err := handler.handleMessage(ctx, message); err != nil {
+				handler.log.Errorf("error handling AWS event: %v", err)
+				continue
+			}
+			_, err := client.DeleteMessage(ctx, &sqs.DeleteMessageInput{
+				QueueUrl:      aws.String(queueURL),
+				ReceiptHandle: message.ReceiptHandle,
+			})
+			if err != nil {
+				handler.log.Errorf("error deleting message from SQS queue %s: %v", queueURL, err)
+			}
+		}
+	}
+}
+
+func (h *awsEventHandler) handleMessage(ctx context.Context, message sqstypes.Message) error {
+	var event awsEvent
+	if err := json.Unmarshal([]byte(aws.ToString(message.Body)), &event); err != nil {
+		// a message that can't be parsed never will, so it is dropped from the queue
+		h.log.Warnf("ignoring invalid AWS event: %v", err)
+		return nil
+	}
+	if event.Source != "aws.ec2" {
+		h.log.Debugf("ignoring AWS event from %s", event.Source)
+		return nil
+	}
+
+	switch event.DetailType {
+	case ec2StateChangeDetailType:
+		var detail ec2StateChangeDetail
+		if err := json.Unmarshal(event.Detail, &detail); err != nil {
+			return fmt.Errorf("error parsing EC2 state change: %w", err)
+		}
+		return h.handleEC2StateChange(ctx, event, detail)
+	case cloudTrailAPICallType:
+		var detail cloudTrailDetail
+		if err := json.Unmarshal(event.Detail, &detail); err != nil {
+			return fmt.Errorf("error parsing CloudTrail event: %w", err)
+		}
+		return h.handleEC2APICall(ctx, event, detail)
+	default:
+		h.log.Debugf("ignoring AWS event %s", event.DetailType)
+		return nil
+	}
+}
+
+func (h *awsEventHandler) handleEC2StateChange(ctx context.Context, event awsEvent, detail ec2StateChangeDetail) error {
+	if !internal.IsTypeEnabled(h.assetTypes, "aws.ec2.instance") || detail.InstanceID == "" {
+		return nil
+	}
+	if detail.State == "terminated" {
+		h.publishTombstone(event, "aws.ec2.instance", "host", detail.InstanceID)
+		return nil
+	}
+
+	client, err := h.ec2Client(ctx, event.Region)
+	if err != nil {
+		return err
+	}
+	resp, err := client.DescribeInstances(ctx, &ec2.DescribeInstancesInput{InstanceIds: []string{detail.InstanceID}})
+	if err != nil {
+		return fmt.Errorf("error describing EC2 instance %s: %w", detail.InstanceID, err)
+	}
+	for _, reservation := range resp.Reservations {
+		for _, instance := range reservation.Instances {
+			publishEC2InstanceAsset(h.publisher, event.Region, newEC2Instance(*reservation.OwnerId, instance))
+		}
+	}
+	return nil
+}
+
+func (h *awsEventHandler) handleEC2APICall(ctx context.Context, event awsEvent, detail cloudTrailDetail) error {
+	switch detail.EventName {
+	case "CreateVpc":
+		vpcID := detail.ResponseElements.Vpc.VpcID
+		if !internal.IsTypeEnabled(h.assetTypes, "aws.vpc") || vpcID == "" {
+			return nil
+		}
+		client, err := h.ec2Client(ctx, event.Region)
+		if err != nil {
+			return err
+		}
+		resp, err := client.DescribeVpcs(ctx, &ec2.DescribeVpcsInput{VpcIds: []string{vpcID}})
+		if err != nil {
+			return fmt.Errorf("error describing VPC %s: %w", vpcID, err)
+		}
+		for _, vpc := range resp.Vpcs {
+			publishVPCAsset(h.publisher, event.Region, vpc)
+		}
+	case "DeleteVpc":
+		vpcID := detail.RequestParameters.VpcID
+		if internal.IsTypeEnabled(h.assetTypes, "aws.vpc") && vpcID != "" {
+			h.publishTombstone(event, "aws.vpc", "network", vpcID)
+		}
+	case "CreateSubnet":
+		subnetID := detail.ResponseElements.Subnet.SubnetID
+		if !internal.IsTypeEnabled(h.assetTypes, "aws.subnet") || subnetID == "" {
+			return nil
+		}
+		client, err := h.ec2Client(ctx, event.Region)
+		if err != nil {
+			return err
+		}
+		resp, err := client.DescribeSubnets(ctx, &ec2.DescribeSubnetsInput{SubnetIds: []string{subnetID}})
+		if err != nil {
+			return fmt.Errorf("error describing subnet %s: %w", subnetID, err)
+		}
+		for _, subnet := range resp.Subnets {
+			publishSubnetAsset(h.publisher, event.Region, subnet)
+		}
+	case "DeleteSubnet":
+		subnetID := detail.RequestParameters.SubnetID
+		if internal.IsTypeEnabled(h.assetTypes, "aws.subnet") && subnetID != "" {
+			h.publishTombstone(event, "aws.subnet", "network", subnetID)
+		}
+	default:
+		h.log.Debugf("ignoring AWS API call %s", detail.EventName)
+	}
+	return nil
+}
+
+func (h *awsEventHandler) publishTombstone(event awsEvent, assetType string, assetKind string, id string) {
+	internal.Publish(h.publisher, nil,
+		internal.WithAssetCloudProvider("aws"),
+		internal.WithAssetRegion(event.Region),
+		internal.WithAssetAccountID(event.Account),
+		internal.WithAssetKindAndID(assetKind, id),
+		internal.WithAssetType(assetType),
+		internal.WithAssetDeleted(),
+	)
+}
+
+// getSQSQueueRegion returns the region of a queue from its URL (e.g. https://sqs.eu-west-1.amazonaws.com/1111/queue,
+// or the legacy https://eu-west-1.queue.amazonaws.com/1111/queue and https://queue.amazonaws.com/1111/queue in us-east-1),
+// or an empty string when the URL doesn't follow the AWS format.
+func getSQSQueueRegion(queueURL string) string {
+	u, err := url.Parse(queueURL)
+	if err != nil {
+		return ""
+	}
+	parts := strings.Split(u.Hostname(), ".")
+	switch {
+	case len(parts) >= 4 && parts[0] == "sqs":
+		return parts[1]
+	case len(parts) >= 4 && parts[1] == "queue":
+		return parts[0]
+	case len(parts) >= 3 && parts[0] == "queue":
+		return "us-east-1"
+	default:
+		return ""
+	}
+}
+
+// getSQSQueueEndpoint returns the scheme and host of a queue URL.
+func getSQSQueueEndpoint(queueURL string) (string, error) {
+	u, err := url.Parse(queueURL)
+	if err != nil {
+		return "", err
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return "", fmt.Errorf("missing scheme or host in %s", queueURL)
+	}
+	return u.Scheme + "://" + u.Host, nil
+}
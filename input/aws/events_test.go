@@ -0,0 +1,274 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package aws
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	sqstypes "github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/elastic/assetbeat/input/internal"
+	"github.com/elastic/assetbeat/input/testutil"
+	"github.com/elastic/beats/v7/libbeat/beat"
+	"github.com/elastic/elastic-agent-libs/logp"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+var sqsQueueURL = "https://sqs.eu-west-1.amazonaws.com/11111111111111/assets"
+
+// mockSQSClient returns its messages on the first receive, and cancels the context on the next one.
+type mockSQSClient struct {
+	messages []sqstypes.Message
+	cancel   context.CancelFunc
+	deleted  []string
+	received int
+}
+
+func (m *mockSQSClient) ReceiveMessage(ctx context.Context, params *sqs.ReceiveMessageInput, optFns ...func(*sqs.Options)) (*sqs.ReceiveMessageOutput, error) {
+	m.received++
+	if m.received > 1 {
+		m.cancel()
+		return nil, ctx.Err()
+	}
+	return &sqs.ReceiveMessageOutput{Messages: m.messages}, nil
+}
+
+func (m *mockSQSClient) DeleteMessage(ctx context.Context, params *sqs.DeleteMessageInput, optFns ...func(*sqs.Options)) (*sqs.DeleteMessageOutput, error) {
+	m.deleted = append(m.deleted, aws.ToString(params.ReceiptHandle))
+	return &sqs.DeleteMessageOutput{}, nil
+}
+
+type mockEC2EventsAPI struct {
+	instances []types.Instance
+	vpcs      []types.Vpc
+	subnets   []types.Subnet
+	err       error
+}
+
+func (m mockEC2EventsAPI) DescribeInstances(ctx context.Context, params *ec2.DescribeInstancesInput, optFns ...func(*ec2.Options)) (*ec2.DescribeInstancesOutput, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return &ec2.DescribeInstancesOutput{
+		Reservations: []types.Reservation{{OwnerId: &ownerID_1, Instances: m.instances}},
+	}, nil
+}
+
+func (m mockEC2EventsAPI) DescribeVpcs(ctx context.Context, params *ec2.DescribeVpcsInput, optFns ...func(*ec2.Options)) (*ec2.DescribeVpcsOutput, error) {
+	return &ec2.DescribeVpcsOutput{Vpcs: m.vpcs}, m.err
+}
+
+func (m mockEC2EventsAPI) DescribeSubnets(ctx context.Context, params *ec2.DescribeSubnetsInput, optFns ...func(*ec2.Options)) (*ec2.DescribeSubnetsOutput, error) {
+	return &ec2.DescribeSubnetsOutput{Subnets: m.subnets}, m.err
+}
+
+func newSQSMessage(receiptHandle string, body string) sqstypes.Message {
+	return sqstypes.Message{
+		ReceiptHandle: aws.String(receiptHandle),
+		Body:          aws.String(body),
+	}
+}
+
+func TestAssetsAWS_consumeAWSEvents(t *testing.T) {
+	isNotDefault := false
+	for _, tt := range []struct {
+		name            string
+		assetTypes      []string
+		messages        []sqstypes.Message
+		ec2Client       mockEC2EventsAPI
+		expectedDeleted []string
+		expectedEvents  []beat.Event
+	}{
+		{
+			name: "Test with a running instance",
+			messages: []sqstypes.Message{
+				newSQSMessage("1", `{"source":"aws.ec2","detail-type":"EC2 Instance State-change Notification","account":"11111111111111","region":"eu-west-1","detail":{"instance-id":"`+instanceID_1+`","state":"running"}}`),
+			},
+			ec2Client: mockEC2EventsAPI{
+				instances: []types.Instance{
+					{
+						InstanceId: &instanceID_1,
+						State:      &types.InstanceState{Name: "running"},
+						SubnetId:   &subnetID1,
+					},
+				},
+			},
+			expectedDeleted: []string{"1"},
+			expectedEvents: []beat.Event{
+				{
+					Fields: mapstr.M{
+						"asset.ean":                         "host:" + instanceID_1,
+						"asset.id":                          instanceID_1,
+						"asset.metadata.state":              "running",
+						"asset.metadata.instance_type":      "",
+						"asset.metadata.image_id":           "",
+						"asset.metadata.platform":           "",
+						"asset.metadata.private_ip_address": "",
+						"asset.metadata.private_dns_name":   "",
+						"asset.metadata.lifecycle":          "on-demand",
+						"asset.type":                        "aws.ec2.instance",
+						"asset.kind":                        "host",
+						"asset.parents":                     []string{"network:" + subnetID1},
+						"cloud.account.id":                  ownerID_1,
+						"cloud.provider":                    "aws",
+						"cloud.region":                      "eu-west-1",
+					},
+					Meta: mapstr.M{
+						"index": internal.GetDefaultIndexName(),
+					},
+				},
+			},
+		},
+		{
+			name: "Test with a terminated instance and a deleted subnet",
+			messages: []sqstypes.Message{
+				newSQSMessage("1", `{"source":"aws.ec2","detail-type":"EC2 Instance State-change Notification","account":"11111111111111","region":"eu-west-1","detail":{"instance-id":"`+instanceID_1+`","state":"terminated"}}`),
+				newSQSMessage("2", `{"source":"aws.ec2","detail-type":"AWS API Call via CloudTrail","account":"11111111111111","region":"eu-west-1","detail":{"eventName":"DeleteSubnet","requestParameters":{"subnetId":"`+subnetID1+`"}}}`),
+			},
+			expectedDeleted: []string{"1", "2"},
+			expectedEvents: []beat.Event{
+				{
+					Fields: mapstr.M{
+						"asset.ean":        "host:" + instanceID_1,
+						"asset.id":         instanceID_1,
+						"asset.type":       "aws.ec2.instance",
+						"asset.kind":       "host",
+						"asset.deleted":    true,
+						"cloud.account.id": ownerID_1,
+						"cloud.provider":   "aws",
+						"cloud.region":     "eu-west-1",
+					},
+					Meta: mapstr.M{
+						"index": internal.GetDefaultIndexName(),
+					},
+				},
+				{
+					Fields: mapstr.M{
+						"asset.ean":        "network:" + subnetID1,
+						"asset.id":         subnetID1,
+						"asset.type":       "aws.subnet",
+						"asset.kind":       "network",
+						"asset.deleted":    true,
+						"cloud.account.id": ownerID_1,
+						"cloud.provider":   "aws",
+						"cloud.region":     "eu-west-1",
+					},
+					Meta: mapstr.M{
+						"index": internal.GetDefaultIndexName(),
+					},
+				},
+			},
+		},
+		{
+			name: "Test with a created VPC",
+			messages: []sqstypes.Message{
+				newSQSMessage("1", `{"source":"aws.ec2","detail-type":"AWS API Call via CloudTrail","account":"11111111111111","region":"eu-west-1","detail":{"eventName":"CreateVpc","responseElements":{"vpc":{"vpcId":"`+vpcId1+`"}}}}`),
+			},
+			ec2Client: mockEC2EventsAPI{
+				vpcs: []types.Vpc{
+					{
+						OwnerId:   &ownerID_1,
+						VpcId:     &vpcId1,
+						IsDefault: &isNotDefault,
+					},
+				},
+			},
+			expectedDeleted: []string{"1"},
+			expectedEvents: []beat.Event{
+				{
+					Fields: mapstr.M{
+						"asset.ean":                "network:" + vpcId1,
+						"asset.id":                 vpcId1,
+						"asset.type":               "aws.vpc",
+						"asset.kind":               "network",
+						"asset.metadata.isDefault": &isNotDefault,
+						"cloud.account.id":         ownerID_1,
+						"cloud.provider":           "aws",
+						"cloud.region":             "eu-west-1",
+					},
+					Meta: mapstr.M{
+						"index": internal.GetDefaultIndexName(),
+					},
+				},
+			},
+		},
+		{
+			name:       "Test with disabled asset types and unrelated events",
+			assetTypes: []string{"aws.vpc"},
+			messages: []sqstypes.Message{
+				newSQSMessage("1", `{"source":"aws.ec2","detail-type":"EC2 Instance State-change Notification","account":"11111111111111","region":"eu-west-1","detail":{"instance-id":"`+instanceID_1+`","state":"terminated"}}`),
+				newSQSMessage("2", `{"source":"aws.s3","detail-type":"Object Created","detail":{}}`),
+				newSQSMessage("3", `not json`),
+			},
+			expectedDeleted: []string{"1", "2", "3"},
+		},
+		{
+			name: "Test with a failing describe",
+			messages: []sqstypes.Message{
+				newSQSMessage("1", `{"source":"aws.ec2","detail-type":"EC2 Instance State-change Notification","account":"11111111111111","region":"eu-west-1","detail":{"instance-id":"`+instanceID_1+`","state":"pending"}}`),
+			},
+			ec2Client: mockEC2EventsAPI{
+				err: errors.New("throttled"),
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			publisher := testutil.NewInMemoryPublisher()
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			client := &mockSQSClient{messages: tt.messages, cancel: cancel}
+			handler := &awsEventHandler{
+				assetTypes: tt.assetTypes,
+				ec2Client: func(ctx context.Context, region string) (ec2EventsAPIClient, error) {
+					assert.Equal(t, "eu-west-1", region)
+					return tt.ec2Client, nil
+				},
+				log:       logp.NewLogger("test"),
+				publisher: publisher,
+			}
+
+			consumeAWSEvents(ctx, client, sqsQueueURL, handler)
+			assert.Equal(t, tt.expectedDeleted, client.deleted)
+			assert.Equal(t, tt.expectedEvents, publisher.Events)
+		})
+	}
+}
+
+func TestGetSQSQueueRegion(t *testing.T) {
+	assert.Equal(t, "eu-west-1", getSQSQueueRegion(sqsQueueURL))
+	assert.Equal(t, "eu-west-1", getSQSQueueRegion("https://eu-west-1.queue.amazonaws.com/000000000000/assets"))
+	assert.Equal(t, "us-east-1", getSQSQueueRegion("https://queue.amazonaws.com/000000000000/assets"))
+	assert.Equal(t, "", getSQSQueueRegion("http://localhost:9324/000000000000/assets"))
+}
+
+func TestGetSQSQueueEndpoint(t *testing.T) {
+	endpoint, err := getSQSQueueEndpoint("http://localhost:9324/000000000000/assets")
+	assert.NoError(t, err)
+	assert.Equal(t, "http://localhost:9324", endpoint)
+
+	_, err = getSQSQueueEndpoint("assets")
+	assert.Error(t, err)
+}
@@ -0,0 +1,200 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+
+	"github.com/elastic/assetbeat/input/internal"
+	stateless "github.com/elastic/beats/v7/filebeat/input/v2/input-stateless"
+
+	"github.com/elastic/elastic-agent-libs/logp"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/aws/aws-sdk-go-v2/service/iam/types"
+)
+
+type iamClient interface {
+	iam.ListRolesAPIClient
+	GetRole(ctx context.Context, params *iam.GetRoleInput, optFns ...func(*iam.Options)) (*iam.GetRoleOutput, error)
+}
+
+// collectIAMRoleAssets publishes every IAM role of the account. IAM is a global service, its roles have no region.
+func collectIAMRoleAssets(ctx context.Context, client iamClient, accountID string, log *logp.Logger, publisher stateless.Publisher) error {
+	roles, err := describeIAMRoles(ctx, client, log)
+	if err != nil {
+		return err
+	}
+
+	assetType := "aws.iam.role"
+	assetKind := "role"
+	for _, role := range roles {
+		internal.Publish(publisher, nil,
+			internal.WithAssetCloudProvider("aws"),
+			internal.WithAssetAccountID(accountID),
+			internal.WithAssetKindAndID(assetKind, aws.ToString(role.Arn)),
+			internal.WithAssetType(assetType),
+			internal.WithAssetName(aws.ToString(role.RoleName)),
+			WithAssetTags(flattenIAMTags(role.Tags)),
+			internal.WithAssetMetadata(getIAMRoleMetadata(role, log)),
+		)
+	}
+
+	return nil
+}
+
+func getIAMRoleMetadata(role types.Role, log *logp.Logger) mapstr.M {
+	metadata := mapstr.M{
+		"role_id":              aws.ToString(role.RoleId),
+		"path":                 aws.ToString(role.Path),
+		"creation_date":        role.CreateDate,
+		"max_session_duration": aws.ToInt32(role.MaxSessionDuration),
+	}
+	if role.Description != nil {
+		metadata["description"] = *role.Description
+	}
+	if role.AssumeRolePolicyDocument != nil {
+		principals, err := getIAMTrustPolicyPrincipals(*role.AssumeRolePolicyDocument)
+		if err != nil {
+			log.Warnf("error parsing trust policy of IAM role %s: %v", aws.ToString(role.RoleName), err)
+		} else {
+			metadata["trust_policy"] = mapstr.M{"principals": principals}
+		}
+	}
+	if lastUsed := role.RoleLastUsed; lastUsed != nil && lastUsed.LastUsedDate != nil {
+		metadata["last_used"] = mapstr.M{
+			"date":   lastUsed.LastUsedDate,
+			"region": aws.ToString(lastUsed.Region),
+		}
+	}
+	return metadata
+}
+
+// describeIAMRoles lists the roles of the account. Listed roles lack their tags and last usage,
+// which are only returned when getting each role.
+func describeIAMRoles(ctx context.Context, client iamClient, log *logp.Logger) ([]types.Role, error) {
+	roles := make([]types.Role, 0, 100)
+	paginator := iam.NewListRolesPaginator(client, &iam.ListRolesInput{})
+	for paginator.HasMorePages() {
+		resp, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error listing IAM roles: %w", err)
+		}
+
+		for _, role := range resp.Roles {
+			roleResp, err := client.GetRole(ctx, &iam.GetRoleInput{RoleName: role.RoleName})
+			if err != nil || roleResp.Role == nil {
+				log.Warnf("error getting IAM role %s: %v", aws.ToString(role.RoleName), err)
+				roles = append(roles, role)
+				continue
+			}
+			roles = append(roles, *roleResp.Role)
+		}
+	}
+
+	return roles, nil
+}
+
+// iamPolicyStatements holds the statements of a policy document,
+// which may be written as a single statement or as a list of statements.
+type iamPolicyStatements []iamPolicyStatement
+
+type iamPolicyStatement struct {
+	Effect    string          `json:"Effect"`
+	Principal json.RawMessage `json:"Principal"`
+}
+
+func (s *iamPolicyStatements) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '{' {
+		var statement iamPolicyStatement
+		if err := json.Unmarshal(data, &statement); err != nil {
+			return err
+		}
+		*s = iamPolicyStatements{statement}
+		return nil
+	}
+	return json.Unmarshal(data, (*[]iamPolicyStatement)(s))
+}
+
+// getIAMTrustPolicyPrincipals returns the principals allowed by a URL-encoded trust policy,
+// in the `<type>:<principal>` format, e.g. `Service:ec2.amazonaws.com`.
+func getIAMTrustPolicyPrincipals(document string) ([]string, error) {
+	decoded, err := url.QueryUnescape(document)
+	if err != nil {
+		return nil, err
+	}
+	var policy struct {
+		Statement iamPolicyStatements `json:"Statement"`
+	}
+	if err := json.Unmarshal([]byte(decoded), &policy); err != nil {
+		return nil, err
+	}
+
+	seen := map[string]bool{}
+	principals := []string{}
+	add := func(principal string) {
+		if !seen[principal] {
+			seen[principal] = true
+			principals = append(principals, principal)
+		}
+	}
+	for _, statement := range policy.Statement {
+		if statement.Effect != "Allow" || len(statement.Principal) == 0 {
+			continue
+		}
+		var anyone string
+		if err := json.Unmarshal(statement.Principal, &anyone); err == nil {
+			add(anyone)
+			continue
+		}
+		var byType map[string]json.RawMessage
+		if err := json.Unmarshal(statement.Principal, &byType); err != nil {
+			return nil, err
+		}
+		for principalType, raw := range byType {
+			var values []string
+			if err := json.Unmarshal(raw, &values); err != nil {
+				var value string
+				if err := json.Unmarshal(raw, &value); err != nil {
+					return nil, err
+				}
+				values = []string{value}
+			}
+			for _, value := range values {
+				add(principalType + ":" + value)
+			}
+		}
+	}
+	sort.Strings(principals)
+	return principals, nil
+}
+
+// flattenIAMTags converts the IAM tag format to a simple `map[string]string`
+func flattenIAMTags(tags []types.Tag) mapstr.M {
+	out := mapstr.M{}
+	for _, t := range tags {
+		out[*t.Key] = *t.Value
+	}
+	return out
+}
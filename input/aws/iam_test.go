@@ -0,0 +1,172 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package aws
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/aws/aws-sdk-go-v2/service/iam/types"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/elastic/assetbeat/input/internal"
+	"github.com/elastic/assetbeat/input/testutil"
+	"github.com/elastic/beats/v7/libbeat/beat"
+	"github.com/elastic/elastic-agent-libs/logp"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+var iamRoleARN_1 = "arn:aws:iam::11111111111111:role/service-role/my-role"
+
+type mockIAMClient struct {
+	roles []types.Role
+}
+
+func (m mockIAMClient) ListRoles(ctx context.Context, params *iam.ListRolesInput, optFns ...func(*iam.Options)) (*iam.ListRolesOutput, error) {
+	var listed []types.Role
+	for _, role := range m.roles {
+		// listed roles lack their tags and last usage
+		role.Tags = nil
+		role.RoleLastUsed = nil
+		listed = append(listed, role)
+	}
+	return &iam.ListRolesOutput{Roles: listed}, nil
+}
+
+func (m mockIAMClient) GetRole(ctx context.Context, params *iam.GetRoleInput, optFns ...func(*iam.Options)) (*iam.GetRoleOutput, error) {
+	for _, role := range m.roles {
+		role := role
+		if *role.RoleName == *params.RoleName {
+			return &iam.GetRoleOutput{Role: &role}, nil
+		}
+	}
+	return nil, &types.NoSuchEntityException{}
+}
+
+func TestAssetsAWS_collectIAMRoleAssets(t *testing.T) {
+	creationDate := time.Date(2023, 9, 1, 10, 0, 0, 0, time.UTC)
+	lastUsedDate := time.Date(2023, 10, 2, 8, 30, 0, 0, time.UTC)
+	trustPolicy := url.QueryEscape(`{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Principal":{"Service":"lambda.amazonaws.com"},"Action":"sts:AssumeRole"}]}`)
+
+	client := mockIAMClient{
+		roles: []types.Role{
+			{
+				Arn:                      &iamRoleARN_1,
+				RoleName:                 aws.String("my-role"),
+				RoleId:                   aws.String("AROA1111111111111111"),
+				Path:                     aws.String("/service-role/"),
+				CreateDate:               &creationDate,
+				MaxSessionDuration:       aws.Int32(3600),
+				Description:              aws.String("Role of my function"),
+				AssumeRolePolicyDocument: &trustPolicy,
+				RoleLastUsed:             &types.RoleLastUsed{LastUsedDate: &lastUsedDate, Region: aws.String("eu-west-1")},
+				Tags:                     []types.Tag{{Key: &tag_1_k, Value: &tag_1_v}},
+			},
+		},
+	}
+
+	expectedEvents := []beat.Event{
+		{
+			Fields: mapstr.M{
+				"asset.ean":                              "role:" + iamRoleARN_1,
+				"asset.id":                               iamRoleARN_1,
+				"asset.name":                             "my-role",
+				"asset.type":                             "aws.iam.role",
+				"asset.kind":                             "role",
+				"asset.metadata.role_id":                 "AROA1111111111111111",
+				"asset.metadata.path":                    "/service-role/",
+				"asset.metadata.creation_date":           &creationDate,
+				"asset.metadata.max_session_duration":    int32(3600),
+				"asset.metadata.description":             "Role of my function",
+				"asset.metadata.trust_policy.principals": []string{"Service:lambda.amazonaws.com"},
+				"asset.metadata.last_used.date":          &lastUsedDate,
+				"asset.metadata.last_used.region":        "eu-west-1",
+				"asset.metadata.tags." + tag_1_k:         tag_1_v,
+				"cloud.account.id":                       ownerID_1,
+				"cloud.provider":                         "aws",
+			},
+			Meta: mapstr.M{
+				"index": internal.GetDefaultIndexName(),
+			},
+		},
+	}
+
+	publisher := testutil.NewInMemoryPublisher()
+	log := logp.NewLogger("mylogger")
+	err := collectIAMRoleAssets(context.Background(), client, ownerID_1, log, publisher)
+	assert.NoError(t, err)
+	assert.Equal(t, expectedEvents, publisher.Events)
+}
+
+func TestGetIAMTrustPolicyPrincipals(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		document string
+
+		expectedPrincipals []string
+		expectedError      bool
+	}{
+		{
+			name:               "single statement and principal",
+			document:           `{"Statement":{"Effect":"Allow","Principal":{"Service":"ec2.amazonaws.com"}}}`,
+			expectedPrincipals: []string{"Service:ec2.amazonaws.com"},
+		},
+		{
+			name: "multiple statements and principals",
+			document: `{"Statement":[
+				{"Effect":"Allow","Principal":{"AWS":["arn:aws:iam::22222222222222:root","arn:aws:iam::33333333333333:root"],"Service":"ec2.amazonaws.com"}},
+				{"Effect":"Allow","Principal":{"Federated":"cognito-identity.amazonaws.com"}}
+			]}`,
+			expectedPrincipals: []string{
+				"AWS:arn:aws:iam::22222222222222:root",
+				"AWS:arn:aws:iam::33333333333333:root",
+				"Federated:cognito-identity.amazonaws.com",
+				"Service:ec2.amazonaws.com",
+			},
+		},
+		{
+			name:               "anonymous principal",
+			document:           `{"Statement":[{"Effect":"Allow","Principal":"*"}]}`,
+			expectedPrincipals: []string{"*"},
+		},
+		{
+			name:               "denied principals are ignored",
+			document:           `{"Statement":[{"Effect":"Deny","Principal":{"Service":"ec2.amazonaws.com"}}]}`,
+			expectedPrincipals: []string{},
+		},
+		{
+			name:          "invalid document",
+			document:      `{"Statement":`,
+			expectedError: true,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			principals, err := getIAMTrustPolicyPrincipals(url.QueryEscape(tt.document))
+			if tt.expectedError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedPrincipals, principals)
+		})
+	}
+}
@@ -0,0 +1,110 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package aws
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/elastic/assetbeat/input/internal"
+	"github.com/elastic/assetbeat/util"
+	stateless "github.com/elastic/beats/v7/filebeat/input/v2/input-stateless"
+
+	"github.com/elastic/elastic-agent-libs/logp"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/aws/aws-sdk-go-v2/service/lambda/types"
+)
+
+type LambdaFunction struct {
+	ARN       string
+	Name      string
+	AccountID string
+	SubnetIDs []string
+	Metadata  mapstr.M
+}
+
+func collectLambdaAssets(ctx context.Context, client lambda.ListFunctionsAPIClient, region string, log *logp.Logger, publisher stateless.Publisher) error {
+	functions, err := listLambdaFunctions(ctx, client)
+	if err != nil {
+		return err
+	}
+
+	assetType := "aws.lambda.function"
+	assetKind := "function"
+	for _, function := range functions {
+		var parents []string
+		for _, subnetID := range function.SubnetIDs {
+			parents = append(parents, "network:"+subnetID)
+		}
+		options := []internal.AssetOption{
+			internal.WithAssetCloudProvider("aws"),
+			internal.WithAssetRegion(region),
+			internal.WithAssetAccountID(function.AccountID),
+			internal.WithAssetKindAndID(assetKind, function.ARN),
+			internal.WithAssetName(function.Name),
+			internal.WithAssetType(assetType),
+			internal.WithAssetMetadata(function.Metadata),
+		}
+		if parents != nil {
+			options = append(options, internal.WithAssetParents(parents))
+		}
+		internal.Publish(publisher, nil,
+			options...,
+		)
+	}
+
+	return nil
+}
+
+func listLambdaFunctions(ctx context.Context, client lambda.ListFunctionsAPIClient) ([]LambdaFunction, error) {
+	functions := make([]LambdaFunction, 0, 100)
+	paginator := lambda.NewListFunctionsPaginator(client, &lambda.ListFunctionsInput{})
+	for paginator.HasMorePages() {
+		resp, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error listing Lambda functions: %w", err)
+		}
+		functions = append(functions, util.Map(func(f types.FunctionConfiguration) LambdaFunction {
+			function := LambdaFunction{
+				ARN:  aws.ToString(f.FunctionArn),
+				Name: aws.ToString(f.FunctionName),
+				Metadata: mapstr.M{
+					"runtime":       string(f.Runtime),
+					"memory_size":   aws.ToInt32(f.MemorySize),
+					"timeout":       aws.ToInt32(f.Timeout),
+					"architectures": util.Map(func(a types.Architecture) string { return string(a) }, f.Architectures),
+					"last_modified": aws.ToString(f.LastModified),
+					"package_type":  string(f.PackageType),
+					"role":          aws.ToString(f.Role),
+				},
+			}
+			if functionARN, err := arn.Parse(function.ARN); err == nil {
+				function.AccountID = functionARN.AccountID
+			}
+			if f.VpcConfig != nil {
+				function.SubnetIDs = f.VpcConfig.SubnetIds
+			}
+			return function
+		}, resp.Functions)...)
+	}
+	return functions, nil
+}
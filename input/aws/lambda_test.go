@@ -0,0 +1,163 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package aws
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/elastic/assetbeat/input/internal"
+	"github.com/elastic/assetbeat/input/testutil"
+	"github.com/elastic/beats/v7/libbeat/beat"
+	"github.com/elastic/elastic-agent-libs/logp"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+var functionName1 = "my-function"
+var functionARN1 = "arn:aws:lambda:eu-west-1:11111111111111:function:my-function"
+var functionName2 = "my-vpc-function"
+var functionARN2 = "arn:aws:lambda:eu-west-1:11111111111111:function:my-vpc-function"
+var functionRole = "arn:aws:iam::11111111111111:role/lambda-role"
+var functionLastModified = "2023-09-01T10:00:00.000+0000"
+
+type mockListFunctionsAPI func(ctx context.Context, params *lambda.ListFunctionsInput, optFns ...func(*lambda.Options)) (*lambda.ListFunctionsOutput, error)
+
+func (m mockListFunctionsAPI) ListFunctions(ctx context.Context, params *lambda.ListFunctionsInput, optFns ...func(*lambda.Options)) (*lambda.ListFunctionsOutput, error) {
+	return m(ctx, params, optFns...)
+}
+
+func TestAssetsAWS_collectLambdaAssets(t *testing.T) {
+	memorySize, timeout := int32(128), int32(30)
+	nextMarker := "page2"
+	for _, tt := range []struct {
+		name           string
+		region         string
+		client         func(t *testing.T) lambda.ListFunctionsAPIClient
+		expectedEvents []beat.Event
+	}{{
+		name:   "Test with multiple pages of Lambda functions returned",
+		region: "eu-west-1",
+		client: func(t *testing.T) lambda.ListFunctionsAPIClient {
+			return mockListFunctionsAPI(func(ctx context.Context, params *lambda.ListFunctionsInput, optFns ...func(*lambda.Options)) (*lambda.ListFunctionsOutput, error) {
+				t.Helper()
+				if params.Marker == nil {
+					return &lambda.ListFunctionsOutput{
+						NextMarker: &nextMarker,
+						Functions: []types.FunctionConfiguration{
+							{
+								FunctionArn:   &functionARN1,
+								FunctionName:  &functionName1,
+								Runtime:       types.RuntimePython311,
+								MemorySize:    &memorySize,
+								Timeout:       &timeout,
+								Architectures: []types.Architecture{types.ArchitectureArm64},
+								LastModified:  &functionLastModified,
+								PackageType:   types.PackageTypeZip,
+								Role:          &functionRole,
+							},
+						},
+					}, nil
+				}
+				return &lambda.ListFunctionsOutput{
+					Functions: []types.FunctionConfiguration{
+						{
+							FunctionArn:   &functionARN2,
+							FunctionName:  &functionName2,
+							Runtime:       types.RuntimeGo1x,
+							MemorySize:    &memorySize,
+							Timeout:       &timeout,
+							Architectures: []types.Architecture{types.ArchitectureX8664},
+							LastModified:  &functionLastModified,
+							PackageType:   types.PackageTypeZip,
+							Role:          &functionRole,
+							VpcConfig: &types.VpcConfigResponse{
+								SubnetIds: []string{subnetID1, "mysubnetid2"},
+							},
+						},
+					},
+				}, nil
+			})
+		},
+		expectedEvents: []beat.Event{
+			{
+				Fields: mapstr.M{
+					"asset.ean":                    "function:" + functionARN1,
+					"asset.id":                     functionARN1,
+					"asset.name":                   functionName1,
+					"asset.type":                   "aws.lambda.function",
+					"asset.kind":                   "function",
+					"asset.metadata.runtime":       "python3.11",
+					"asset.metadata.memory_size":   memorySize,
+					"asset.metadata.timeout":       timeout,
+					"asset.metadata.architectures": []string{"arm64"},
+					"asset.metadata.last_modified": functionLastModified,
+					"asset.metadata.package_type":  "Zip",
+					"asset.metadata.role":          functionRole,
+					"cloud.account.id":             "11111111111111",
+					"cloud.provider":               "aws",
+					"cloud.region":                 "eu-west-1",
+				},
+				Meta: mapstr.M{
+					"index": internal.GetDefaultIndexName(),
+				},
+			},
+			{
+				Fields: mapstr.M{
+					"asset.ean":                    "function:" + functionARN2,
+					"asset.id":                     functionARN2,
+					"asset.name":                   functionName2,
+					"asset.type":                   "aws.lambda.function",
+					"asset.kind":                   "function",
+					"asset.metadata.runtime":       "go1.x",
+					"asset.metadata.memory_size":   memorySize,
+					"asset.metadata.timeout":       timeout,
+					"asset.metadata.architectures": []string{"x86_64"},
+					"asset.metadata.last_modified": functionLastModified,
+					"asset.metadata.package_type":  "Zip",
+					"asset.metadata.role":          functionRole,
+					"asset.parents": []string{
+						"network:" + subnetID1,
+						"network:mysubnetid2",
+					},
+					"cloud.account.id": "11111111111111",
+					"cloud.provider":   "aws",
+					"cloud.region":     "eu-west-1",
+				},
+				Meta: mapstr.M{
+					"index": internal.GetDefaultIndexName(),
+				},
+			},
+		},
+	},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			publisher := testutil.NewInMemoryPublisher()
+
+			ctx := context.Background()
+			logger := logp.NewLogger("test")
+
+			err := collectLambdaAssets(ctx, tt.client(t), tt.region, logger, publisher)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedEvents, publisher.Events)
+		})
+	}
+}
@@ -0,0 +1,408 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package aws
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/elastic/assetbeat/input/internal"
+	stateless "github.com/elastic/beats/v7/filebeat/input/v2/input-stateless"
+
+	"github.com/elastic/elastic-agent-libs/logp"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+type describeAddressesAPIClient interface {
+	DescribeAddresses(ctx context.Context, params *ec2.DescribeAddressesInput, optFns ...func(*ec2.Options)) (*ec2.DescribeAddressesOutput, error)
+}
+
+type elasticIPsAPIClient interface {
+	describeAddressesAPIClient
+	ec2.DescribeNatGatewaysAPIClient
+}
+
+type transitGatewaysAPIClient interface {
+	ec2.DescribeTransitGatewaysAPIClient
+	ec2.DescribeTransitGatewayAttachmentsAPIClient
+}
+
+func collectInternetGatewayAssets(ctx context.Context, client ec2.DescribeInternetGatewaysAPIClient, region string, log *logp.Logger, publisher stateless.Publisher) error {
+	gateways, err := describeInternetGateways(ctx, client)
+	if err != nil {
+		return err
+	}
+
+	assetType := "aws.internet_gateway"
+	assetKind := "network"
+	for _, gateway := range gateways {
+		var parents []string
+		var attachmentStates []string
+		for _, attachment := range gateway.Attachments {
+			if attachment.VpcId != nil {
+				parents = append(parents, "network:"+*attachment.VpcId)
+			}
+			attachmentStates = append(attachmentStates, string(attachment.State))
+		}
+		options := []internal.AssetOption{
+			internal.WithAssetCloudProvider("aws"),
+			internal.WithAssetRegion(region),
+			internal.WithAssetAccountID(aws.ToString(gateway.OwnerId)),
+			internal.WithAssetKindAndID(assetKind, *gateway.InternetGatewayId),
+			internal.WithAssetType(assetType),
+			WithAssetTags(flattenEC2Tags(gateway.Tags)),
+			internal.WithAssetMetadata(mapstr.M{
+				"attachment_states": attachmentStates,
+			}),
+		}
+		if parents != nil {
+			options = append(options, internal.WithAssetParents(parents))
+		}
+		internal.Publish(publisher, nil,
+			options...,
+		)
+	}
+
+	return nil
+}
+
+func collectNatGatewayAssets(ctx context.Context, client ec2.DescribeNatGatewaysAPIClient, region string, accountID string, log *logp.Logger, publisher stateless.Publisher) error {
+	gateways, err := describeNatGateways(ctx, client)
+	if err != nil {
+		return err
+	}
+
+	assetType := "aws.nat_gateway"
+	assetKind := "network"
+	for _, gateway := range gateways {
+		var parents []string
+		if gateway.SubnetId != nil {
+			parents = append(parents, "network:"+*gateway.SubnetId)
+		}
+		var children []string
+		var publicIPs, privateIPs []string
+		for _, address := range gateway.NatGatewayAddresses {
+			if address.AllocationId != nil {
+				children = append(children, "network:"+*address.AllocationId)
+			}
+			if address.PublicIp != nil {
+				publicIPs = append(publicIPs, *address.PublicIp)
+			}
+			if address.PrivateIp != nil {
+				privateIPs = append(privateIPs, *address.PrivateIp)
+			}
+		}
+		options := []internal.AssetOption{
+			internal.WithAssetCloudProvider("aws"),
+			internal.WithAssetRegion(region),
+			internal.WithAssetAccountID(accountID),
+			internal.WithAssetKindAndID(assetKind, *gateway.NatGatewayId),
+			internal.WithAssetType(assetType),
+			WithAssetTags(flattenEC2Tags(gateway.Tags)),
+			internal.WithAssetMetadata(mapstr.M{
+				"state":              string(gateway.State),
+				"connectivity_type":  string(gateway.ConnectivityType),
+				"vpc_id":             aws.ToString(gateway.VpcId),
+				"public_ip_address":  publicIPs,
+				"private_ip_address": privateIPs,
+			}),
+		}
+		if parents != nil {
+			options = append(options, internal.WithAssetParents(parents))
+		}
+		if children != nil {
+			options = append(options, internal.WithAssetChildren(children))
+		}
+		internal.Publish(publisher, nil,
+			options...,
+		)
+	}
+
+	return nil
+}
+
+func collectElasticIPAssets(ctx context.Context, client elasticIPsAPIClient, region string, accountID string, log *logp.Logger, publisher stateless.Publisher) error {
+	resp, err := client.DescribeAddresses(ctx, &ec2.DescribeAddressesInput{})
+	if err != nil {
+		return fmt.Errorf("error describing Elastic IP addresses: %w", err)
+	}
+
+	// Elastic IPs of NAT gateways are associated with a network interface owned by the gateway
+	// rather than with an instance, so they are linked to the gateway through that interface
+	natGatewayIDs := make(map[string]string)
+	gateways, err := describeNatGateways(ctx, client)
+	if err != nil {
+		log.Warnf("Elastic IPs will not be linked to their NAT gateways: %v", err)
+	}
+	for _, gateway := range gateways {
+		for _, address := range gateway.NatGatewayAddresses {
+			if address.NetworkInterfaceId != nil {
+				natGatewayIDs[*address.NetworkInterfaceId] = *gateway.NatGatewayId
+			}
+		}
+	}
+
+	assetType := "aws.eip"
+	assetKind := "network"
+	for _, address := range resp.Addresses {
+		var parents []string
+		if address.InstanceId != nil {
+			parents = append(parents, "host:"+*address.InstanceId)
+		} else if address.NetworkInterfaceId != nil {
+			if natGatewayID, ok := natGatewayIDs[*address.NetworkInterfaceId]; ok {
+				parents = append(parents, "network:"+natGatewayID)
+			}
+		}
+		metadata := mapstr.M{
+			"public_ip_address": aws.ToString(address.PublicIp),
+			"domain":            string(address.Domain),
+		}
+		if address.PrivateIpAddress != nil {
+			metadata["private_ip_address"] = *address.PrivateIpAddress
+		}
+		if address.NetworkInterfaceId != nil {
+			metadata["network_interface_id"] = *address.NetworkInterfaceId
+		}
+		if address.AssociationId != nil {
+			metadata["association_id"] = *address.AssociationId
+		}
+		// Elastic IPs in EC2-Classic have no allocation ID, so the IP is the only identifier available
+		id := aws.ToString(address.AllocationId)
+		if id == "" {
+			id = aws.ToString(address.PublicIp)
+		}
+		options := []internal.AssetOption{
+			internal.WithAssetCloudProvider("aws"),
+			internal.WithAssetRegion(region),
+			internal.WithAssetAccountID(accountID),
+			internal.WithAssetKindAndID(assetKind, id),
+			internal.WithAssetType(assetType),
+			WithAssetTags(flattenEC2Tags(address.Tags)),
+			internal.WithAssetMetadata(metadata),
+		}
+		if parents != nil {
+			options = append(options, internal.WithAssetParents(parents))
+		}
+		internal.Publish(publisher, nil,
+			options...,
+		)
+	}
+
+	return nil
+}
+
+func collectTransitGatewayAssets(ctx context.Context, client transitGatewaysAPIClient, region string, assetTypes []string, log *logp.Logger, publisher stateless.Publisher) error {
+	gateways, err := describeTransitGateways(ctx, client)
+	if err != nil {
+		return err
+	}
+	attachments, err := describeTransitGatewayAttachments(ctx, client)
+	if err != nil {
+		return err
+	}
+
+	gatewayChildren := make(map[string][]string, len(gateways))
+	for _, attachment := range attachments {
+		gatewayID := aws.ToString(attachment.TransitGatewayId)
+		gatewayChildren[gatewayID] = append(gatewayChildren[gatewayID], "network:"+*attachment.TransitGatewayAttachmentId)
+	}
+
+	if internal.IsTypeEnabled(assetTypes, "aws.transit_gateway") {
+		for _, gateway := range gateways {
+			metadata := mapstr.M{
+				"state":       string(gateway.State),
+				"description": aws.ToString(gateway.Description),
+			}
+			if gateway.Options != nil && gateway.Options.AmazonSideAsn != nil {
+				metadata["amazon_side_asn"] = *gateway.Options.AmazonSideAsn
+			}
+			options := []internal.AssetOption{
+				internal.WithAssetCloudProvider("aws"),
+				internal.WithAssetRegion(region),
+				internal.WithAssetAccountID(aws.ToString(gateway.OwnerId)),
+				internal.WithAssetKindAndID("network", *gateway.TransitGatewayId),
+				internal.WithAssetType("aws.transit_gateway"),
+				WithAssetTags(flattenEC2Tags(gateway.Tags)),
+				internal.WithAssetMetadata(metadata),
+			}
+			if children, ok := gatewayChildren[*gateway.TransitGatewayId]; ok {
+				options = append(options, internal.WithAssetChildren(children))
+			}
+			internal.Publish(publisher, nil,
+				options...,
+			)
+		}
+	}
+
+	if internal.IsTypeEnabled(assetTypes, "aws.transit_gateway.attachment") {
+		for _, attachment := range attachments {
+			parents := []string{"network:" + aws.ToString(attachment.TransitGatewayId)}
+			if attachment.ResourceType == types.TransitGatewayAttachmentResourceTypeVpc && attachment.ResourceId != nil {
+				parents = append(parents, "network:"+*attachment.ResourceId)
+			}
+			internal.Publish(publisher, nil,
+				internal.WithAssetCloudProvider("aws"),
+				internal.WithAssetRegion(region),
+				internal.WithAssetAccountID(aws.ToString(attachment.ResourceOwnerId)),
+				internal.WithAssetKindAndID("network", *attachment.TransitGatewayAttachmentId),
+				internal.WithAssetType("aws.transit_gateway.attachment"),
+				internal.WithAssetParents(parents),
+				WithAssetTags(flattenEC2Tags(attachment.Tags)),
+				internal.WithAssetMetadata(mapstr.M{
+					"state":         string(attachment.State),
+					"resource_type": string(attachment.ResourceType),
+					"resource_id":   aws.ToString(attachment.ResourceId),
+				}),
+			)
+		}
+	}
+
+	return nil
+}
+
+func collectVPCPeeringAssets(ctx context.Context, client ec2.DescribeVpcPeeringConnectionsAPIClient, region string, log *logp.Logger, publisher stateless.Publisher) error {
+	connections, err := describeVPCPeeringConnections(ctx, client)
+	if err != nil {
+		return err
+	}
+
+	assetType := "aws.vpc_peering_connection"
+	assetKind := "network"
+	for _, connection := range connections {
+		var parents []string
+		var accountID string
+		metadata := mapstr.M{}
+		if connection.Status != nil {
+			metadata["status"] = string(connection.Status.Code)
+		}
+		if info := connection.RequesterVpcInfo; info != nil {
+			parents = append(parents, "network:"+aws.ToString(info.VpcId))
+			accountID = aws.ToString(info.OwnerId)
+			metadata["requester"] = getVPCPeeringInfoMetadata(info)
+		}
+		if info := connection.AccepterVpcInfo; info != nil {
+			parents = append(parents, "network:"+aws.ToString(info.VpcId))
+			metadata["accepter"] = getVPCPeeringInfoMetadata(info)
+		}
+		options := []internal.AssetOption{
+			internal.WithAssetCloudProvider("aws"),
+			internal.WithAssetRegion(region),
+			internal.WithAssetAccountID(accountID),
+			internal.WithAssetKindAndID(assetKind, *connection.VpcPeeringConnectionId),
+			internal.WithAssetType(assetType),
+			WithAssetTags(flattenEC2Tags(connection.Tags)),
+			internal.WithAssetMetadata(metadata),
+		}
+		if parents != nil {
+			options = append(options, internal.WithAssetParents(parents))
+		}
+		internal.Publish(publisher, nil,
+			options...,
+		)
+	}
+
+	return nil
+}
+
+func getVPCPeeringInfoMetadata(info *types.VpcPeeringConnectionVpcInfo) mapstr.M {
+	return mapstr.M{
+		"vpc_id":     aws.ToString(info.VpcId),
+		"owner_id":   aws.ToString(info.OwnerId),
+		"region":     aws.ToString(info.Region),
+		"cidr_block": aws.ToString(info.CidrBlock),
+	}
+}
+
+func describeInternetGateways(ctx context.Context, client ec2.DescribeInternetGatewaysAPIClient) ([]types.InternetGateway, error) {
+	gateways := make([]types.InternetGateway, 0, 100)
+	paginator := ec2.NewDescribeInternetGatewaysPaginator(client, &ec2.DescribeInternetGatewaysInput{})
+	for paginator.HasMorePages() {
+		resp, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error describing internet gateways: %w", err)
+		}
+
+		gateways = append(gateways, resp.InternetGateways...)
+	}
+
+	return gateways, nil
+}
+
+func describeNatGateways(ctx context.Context, client ec2.DescribeNatGatewaysAPIClient) ([]types.NatGateway, error) {
+	gateways := make([]types.NatGateway, 0, 100)
+	paginator := ec2.NewDescribeNatGatewaysPaginator(client, &ec2.DescribeNatGatewaysInput{})
+	for paginator.HasMorePages() {
+		resp, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error describing NAT gateways: %w", err)
+		}
+
+		gateways = append(gateways, resp.NatGateways...)
+	}
+
+	return gateways, nil
+}
+
+func describeTransitGateways(ctx context.Context, client ec2.DescribeTransitGatewaysAPIClient) ([]types.TransitGateway, error) {
+	gateways := make([]types.TransitGateway, 0, 100)
+	paginator := ec2.NewDescribeTransitGatewaysPaginator(client, &ec2.DescribeTransitGatewaysInput{})
+	for paginator.HasMorePages() {
+		resp, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error describing transit gateways: %w", err)
+		}
+
+		gateways = append(gateways, resp.TransitGateways...)
+	}
+
+	return gateways, nil
+}
+
+func describeTransitGatewayAttachments(ctx context.Context, client ec2.DescribeTransitGatewayAttachmentsAPIClient) ([]types.TransitGatewayAttachment, error) {
+	attachments := make([]types.TransitGatewayAttachment, 0, 100)
+	paginator := ec2.NewDescribeTransitGatewayAttachmentsPaginator(client, &ec2.DescribeTransitGatewayAttachmentsInput{})
+	for paginator.HasMorePages() {
+		resp, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error describing transit gateway attachments: %w", err)
+		}
+
+		attachments = append(attachments, resp.TransitGatewayAttachments...)
+	}
+
+	return attachments, nil
+}
+
+func describeVPCPeeringConnections(ctx context.Context, client ec2.DescribeVpcPeeringConnectionsAPIClient) ([]types.VpcPeeringConnection, error) {
+	connections := make([]types.VpcPeeringConnection, 0, 100)
+	paginator := ec2.NewDescribeVpcPeeringConnectionsPaginator(client, &ec2.DescribeVpcPeeringConnectionsInput{})
+	for paginator.HasMorePages() {
+		resp, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error describing VPC peering connections: %w", err)
+		}
+
+		connections = append(connections, resp.VpcPeeringConnections...)
+	}
+
+	return connections, nil
+}
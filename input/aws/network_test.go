@@ -0,0 +1,457 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package aws
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/elastic/assetbeat/input/internal"
+	"github.com/elastic/assetbeat/input/testutil"
+	"github.com/elastic/beats/v7/libbeat/beat"
+	"github.com/elastic/elastic-agent-libs/logp"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+var internetGatewayID1 = "igw-1"
+var natGatewayID1 = "nat-1"
+var allocationID1 = "eipalloc-1"
+var allocationID2 = "eipalloc-2"
+var allocationID3 = "eipalloc-3"
+var transitGatewayID1 = "tgw-1"
+var transitGatewayAttachmentID1 = "tgw-attach-1"
+var vpcPeeringConnectionID1 = "pcx-1"
+var ownerID_2 = "22222222222222"
+
+type mockDescribeInternetGatewaysAPI func(ctx context.Context, params *ec2.DescribeInternetGatewaysInput, optFns ...func(*ec2.Options)) (*ec2.DescribeInternetGatewaysOutput, error)
+
+func (m mockDescribeInternetGatewaysAPI) DescribeInternetGateways(ctx context.Context, params *ec2.DescribeInternetGatewaysInput, optFns ...func(*ec2.Options)) (*ec2.DescribeInternetGatewaysOutput, error) {
+	return m(ctx, params, optFns...)
+}
+
+type mockDescribeNatGatewaysAPI func(ctx context.Context, params *ec2.DescribeNatGatewaysInput, optFns ...func(*ec2.Options)) (*ec2.DescribeNatGatewaysOutput, error)
+
+func (m mockDescribeNatGatewaysAPI) DescribeNatGateways(ctx context.Context, params *ec2.DescribeNatGatewaysInput, optFns ...func(*ec2.Options)) (*ec2.DescribeNatGatewaysOutput, error) {
+	return m(ctx, params, optFns...)
+}
+
+type mockDescribeAddressesAPI func(ctx context.Context, params *ec2.DescribeAddressesInput, optFns ...func(*ec2.Options)) (*ec2.DescribeAddressesOutput, error)
+
+func (m mockDescribeAddressesAPI) DescribeAddresses(ctx context.Context, params *ec2.DescribeAddressesInput, optFns ...func(*ec2.Options)) (*ec2.DescribeAddressesOutput, error) {
+	return m(ctx, params, optFns...)
+}
+
+type mockElasticIPsAPI struct {
+	mockDescribeAddressesAPI
+	mockDescribeNatGatewaysAPI
+}
+
+type mockTransitGatewaysAPI struct {
+	gateways    []types.TransitGateway
+	attachments []types.TransitGatewayAttachment
+}
+
+func (m mockTransitGatewaysAPI) DescribeTransitGateways(ctx context.Context, params *ec2.DescribeTransitGatewaysInput, optFns ...func(*ec2.Options)) (*ec2.DescribeTransitGatewaysOutput, error) {
+	return &ec2.DescribeTransitGatewaysOutput{TransitGateways: m.gateways}, nil
+}
+
+func (m mockTransitGatewaysAPI) DescribeTransitGatewayAttachments(ctx context.Context, params *ec2.DescribeTransitGatewayAttachmentsInput, optFns ...func(*ec2.Options)) (*ec2.DescribeTransitGatewayAttachmentsOutput, error) {
+	return &ec2.DescribeTransitGatewayAttachmentsOutput{TransitGatewayAttachments: m.attachments}, nil
+}
+
+type mockDescribeVpcPeeringConnectionsAPI func(ctx context.Context, params *ec2.DescribeVpcPeeringConnectionsInput, optFns ...func(*ec2.Options)) (*ec2.DescribeVpcPeeringConnectionsOutput, error)
+
+func (m mockDescribeVpcPeeringConnectionsAPI) DescribeVpcPeeringConnections(ctx context.Context, params *ec2.DescribeVpcPeeringConnectionsInput, optFns ...func(*ec2.Options)) (*ec2.DescribeVpcPeeringConnectionsOutput, error) {
+	return m(ctx, params, optFns...)
+}
+
+func TestAssetsAWS_collectInternetGatewayAssets(t *testing.T) {
+	client := mockDescribeInternetGatewaysAPI(func(ctx context.Context, params *ec2.DescribeInternetGatewaysInput, optFns ...func(*ec2.Options)) (*ec2.DescribeInternetGatewaysOutput, error) {
+		return &ec2.DescribeInternetGatewaysOutput{
+			InternetGateways: []types.InternetGateway{
+				{
+					InternetGatewayId: &internetGatewayID1,
+					OwnerId:           &ownerID_1,
+					Attachments: []types.InternetGatewayAttachment{
+						{
+							State: types.AttachmentStatusAttached,
+							VpcId: &vpcId1,
+						},
+					},
+					Tags: []types.Tag{
+						{
+							Key:   &tag_1_k,
+							Value: &tag_1_v,
+						},
+					},
+				},
+			},
+		}, nil
+	})
+	expectedEvents := []beat.Event{
+		{
+			Fields: mapstr.M{
+				"asset.ean":                        "network:" + internetGatewayID1,
+				"asset.id":                         internetGatewayID1,
+				"asset.type":                       "aws.internet_gateway",
+				"asset.kind":                       "network",
+				"asset.parents":                    []string{"network:" + vpcId1},
+				"asset.metadata.attachment_states": []string{"attached"},
+				"asset.metadata.tags." + tag_1_k:   tag_1_v,
+				"cloud.account.id":                 ownerID_1,
+				"cloud.provider":                   "aws",
+				"cloud.region":                     "eu-west-1",
+			},
+			Meta: mapstr.M{
+				"index": internal.GetDefaultIndexName(),
+			},
+		},
+	}
+
+	publisher := testutil.NewInMemoryPublisher()
+	err := collectInternetGatewayAssets(context.Background(), client, "eu-west-1", logp.NewLogger("test"), publisher)
+	assert.NoError(t, err)
+	assert.Equal(t, expectedEvents, publisher.Events)
+}
+
+func TestAssetsAWS_collectNatGatewayAssets(t *testing.T) {
+	client := mockDescribeNatGatewaysAPI(func(ctx context.Context, params *ec2.DescribeNatGatewaysInput, optFns ...func(*ec2.Options)) (*ec2.DescribeNatGatewaysOutput, error) {
+		return &ec2.DescribeNatGatewaysOutput{
+			NatGateways: []types.NatGateway{
+				{
+					NatGatewayId:     &natGatewayID1,
+					SubnetId:         &subnetID1,
+					VpcId:            &vpcId1,
+					State:            types.NatGatewayStateAvailable,
+					ConnectivityType: types.ConnectivityTypePublic,
+					NatGatewayAddresses: []types.NatGatewayAddress{
+						{
+							AllocationId: &allocationID1,
+							PublicIp:     aws.String("203.0.113.10"),
+							PrivateIp:    aws.String("10.0.0.10"),
+						},
+					},
+				},
+			},
+		}, nil
+	})
+	expectedEvents := []beat.Event{
+		{
+			Fields: mapstr.M{
+				"asset.ean":                         "network:" + natGatewayID1,
+				"asset.id":                          natGatewayID1,
+				"asset.type":                        "aws.nat_gateway",
+				"asset.kind":                        "network",
+				"asset.parents":                     []string{"network:" + subnetID1},
+				"asset.children":                    []string{"network:" + allocationID1},
+				"asset.metadata.state":              "available",
+				"asset.metadata.connectivity_type":  "public",
+				"asset.metadata.vpc_id":             vpcId1,
+				"asset.metadata.public_ip_address":  []string{"203.0.113.10"},
+				"asset.metadata.private_ip_address": []string{"10.0.0.10"},
+				"cloud.account.id":                  ownerID_1,
+				"cloud.provider":                    "aws",
+				"cloud.region":                      "eu-west-1",
+			},
+			Meta: mapstr.M{
+				"index": internal.GetDefaultIndexName(),
+			},
+		},
+	}
+
+	publisher := testutil.NewInMemoryPublisher()
+	err := collectNatGatewayAssets(context.Background(), client, "eu-west-1", ownerID_1, logp.NewLogger("test"), publisher)
+	assert.NoError(t, err)
+	assert.Equal(t, expectedEvents, publisher.Events)
+}
+
+func TestAssetsAWS_collectElasticIPAssets(t *testing.T) {
+	describeAddresses := mockDescribeAddressesAPI(func(ctx context.Context, params *ec2.DescribeAddressesInput, optFns ...func(*ec2.Options)) (*ec2.DescribeAddressesOutput, error) {
+		return &ec2.DescribeAddressesOutput{
+			Addresses: []types.Address{
+				{
+					AllocationId:       &allocationID1,
+					AssociationId:      aws.String("eipassoc-1"),
+					Domain:             types.DomainTypeVpc,
+					InstanceId:         &instanceID_1,
+					NetworkInterfaceId: aws.String("eni-1"),
+					PrivateIpAddress:   aws.String("10.0.0.20"),
+					PublicIp:           aws.String("203.0.113.20"),
+				},
+				{
+					AllocationId: &allocationID2,
+					Domain:       types.DomainTypeVpc,
+					PublicIp:     aws.String("203.0.113.30"),
+				},
+				{
+					AllocationId:       &allocationID3,
+					AssociationId:      aws.String("eipassoc-3"),
+					Domain:             types.DomainTypeVpc,
+					NetworkInterfaceId: aws.String("eni-3"),
+					PrivateIpAddress:   aws.String("10.0.0.10"),
+					PublicIp:           aws.String("203.0.113.10"),
+				},
+			},
+		}, nil
+	})
+	describeNatGateways := mockDescribeNatGatewaysAPI(func(ctx context.Context, params *ec2.DescribeNatGatewaysInput, optFns ...func(*ec2.Options)) (*ec2.DescribeNatGatewaysOutput, error) {
+		return &ec2.DescribeNatGatewaysOutput{
+			NatGateways: []types.NatGateway{
+				{
+					NatGatewayId: &natGatewayID1,
+					NatGatewayAddresses: []types.NatGatewayAddress{
+						{AllocationId: &allocationID3, NetworkInterfaceId: aws.String("eni-3")},
+					},
+				},
+			},
+		}, nil
+	})
+	client := mockElasticIPsAPI{describeAddresses, describeNatGateways}
+	expectedEvents := []beat.Event{
+		{
+			Fields: mapstr.M{
+				"asset.ean":                           "network:" + allocationID1,
+				"asset.id":                            allocationID1,
+				"asset.type":                          "aws.eip",
+				"asset.kind":                          "network",
+				"asset.parents":                       []string{"host:" + instanceID_1},
+				"asset.metadata.public_ip_address":    "203.0.113.20",
+				"asset.metadata.private_ip_address":   "10.0.0.20",
+				"asset.metadata.domain":               "vpc",
+				"asset.metadata.network_interface_id": "eni-1",
+				"asset.metadata.association_id":       "eipassoc-1",
+				"cloud.account.id":                    ownerID_1,
+				"cloud.provider":                      "aws",
+				"cloud.region":                        "eu-west-1",
+			},
+			Meta: mapstr.M{
+				"index": internal.GetDefaultIndexName(),
+			},
+		},
+		{
+			Fields: mapstr.M{
+				"asset.ean":                        "network:" + allocationID2,
+				"asset.id":                         allocationID2,
+				"asset.type":                       "aws.eip",
+				"asset.kind":                       "network",
+				"asset.metadata.public_ip_address": "203.0.113.30",
+				"asset.metadata.domain":            "vpc",
+				"cloud.account.id":                 ownerID_1,
+				"cloud.provider":                   "aws",
+				"cloud.region":                     "eu-west-1",
+			},
+			Meta: mapstr.M{
+				"index": internal.GetDefaultIndexName(),
+			},
+		},
+		{
+			Fields: mapstr.M{
+				"asset.ean":                           "network:" + allocationID3,
+				"asset.id":                            allocationID3,
+				"asset.type":                          "aws.eip",
+				"asset.kind":                          "network",
+				"asset.parents":                       []string{"network:" + natGatewayID1},
+				"asset.metadata.public_ip_address":    "203.0.113.10",
+				"asset.metadata.private_ip_address":   "10.0.0.10",
+				"asset.metadata.domain":               "vpc",
+				"asset.metadata.network_interface_id": "eni-3",
+				"asset.metadata.association_id":       "eipassoc-3",
+				"cloud.account.id":                    ownerID_1,
+				"cloud.provider":                      "aws",
+				"cloud.region":                        "eu-west-1",
+			},
+			Meta: mapstr.M{
+				"index": internal.GetDefaultIndexName(),
+			},
+		},
+	}
+
+	publisher := testutil.NewInMemoryPublisher()
+	err := collectElasticIPAssets(context.Background(), client, "eu-west-1", ownerID_1, logp.NewLogger("test"), publisher)
+	assert.NoError(t, err)
+	assert.Equal(t, expectedEvents, publisher.Events)
+}
+
+func TestAssetsAWS_collectTransitGatewayAssets(t *testing.T) {
+	client := mockTransitGatewaysAPI{
+		gateways: []types.TransitGateway{
+			{
+				TransitGatewayId: &transitGatewayID1,
+				OwnerId:          &ownerID_1,
+				State:            types.TransitGatewayStateAvailable,
+				Description:      aws.String("core"),
+				Options: &types.TransitGatewayOptions{
+					AmazonSideAsn: aws.Int64(64512),
+				},
+			},
+		},
+		attachments: []types.TransitGatewayAttachment{
+			{
+				TransitGatewayAttachmentId: &transitGatewayAttachmentID1,
+				TransitGatewayId:           &transitGatewayID1,
+				ResourceId:                 &vpcId1,
+				ResourceOwnerId:            &ownerID_2,
+				ResourceType:               types.TransitGatewayAttachmentResourceTypeVpc,
+				State:                      types.TransitGatewayAttachmentStateAvailable,
+			},
+		},
+	}
+
+	for _, tt := range []struct {
+		name           string
+		assetTypes     []string
+		expectedEvents []beat.Event
+	}{
+		{
+			name:       "Test with gateways and attachments",
+			assetTypes: []string{},
+			expectedEvents: []beat.Event{
+				{
+					Fields: mapstr.M{
+						"asset.ean":                      "network:" + transitGatewayID1,
+						"asset.id":                       transitGatewayID1,
+						"asset.type":                     "aws.transit_gateway",
+						"asset.kind":                     "network",
+						"asset.children":                 []string{"network:" + transitGatewayAttachmentID1},
+						"asset.metadata.state":           "available",
+						"asset.metadata.description":     "core",
+						"asset.metadata.amazon_side_asn": int64(64512),
+						"cloud.account.id":               ownerID_1,
+						"cloud.provider":                 "aws",
+						"cloud.region":                   "eu-west-1",
+					},
+					Meta: mapstr.M{
+						"index": internal.GetDefaultIndexName(),
+					},
+				},
+				{
+					Fields: mapstr.M{
+						"asset.ean":                    "network:" + transitGatewayAttachmentID1,
+						"asset.id":                     transitGatewayAttachmentID1,
+						"asset.type":                   "aws.transit_gateway.attachment",
+						"asset.kind":                   "network",
+						"asset.parents":                []string{"network:" + transitGatewayID1, "network:" + vpcId1},
+						"asset.metadata.state":         "available",
+						"asset.metadata.resource_type": "vpc",
+						"asset.metadata.resource_id":   vpcId1,
+						"cloud.account.id":             ownerID_2,
+						"cloud.provider":               "aws",
+						"cloud.region":                 "eu-west-1",
+					},
+					Meta: mapstr.M{
+						"index": internal.GetDefaultIndexName(),
+					},
+				},
+			},
+		},
+		{
+			name:       "Test with attachments only",
+			assetTypes: []string{"aws.transit_gateway.attachment"},
+			expectedEvents: []beat.Event{
+				{
+					Fields: mapstr.M{
+						"asset.ean":                    "network:" + transitGatewayAttachmentID1,
+						"asset.id":                     transitGatewayAttachmentID1,
+						"asset.type":                   "aws.transit_gateway.attachment",
+						"asset.kind":                   "network",
+						"asset.parents":                []string{"network:" + transitGatewayID1, "network:" + vpcId1},
+						"asset.metadata.state":         "available",
+						"asset.metadata.resource_type": "vpc",
+						"asset.metadata.resource_id":   vpcId1,
+						"cloud.account.id":             ownerID_2,
+						"cloud.provider":               "aws",
+						"cloud.region":                 "eu-west-1",
+					},
+					Meta: mapstr.M{
+						"index": internal.GetDefaultIndexName(),
+					},
+				},
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			publisher := testutil.NewInMemoryPublisher()
+			err := collectTransitGatewayAssets(context.Background(), client, "eu-west-1", tt.assetTypes, logp.NewLogger("test"), publisher)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedEvents, publisher.Events)
+		})
+	}
+}
+
+func TestAssetsAWS_collectVPCPeeringAssets(t *testing.T) {
+	client := mockDescribeVpcPeeringConnectionsAPI(func(ctx context.Context, params *ec2.DescribeVpcPeeringConnectionsInput, optFns ...func(*ec2.Options)) (*ec2.DescribeVpcPeeringConnectionsOutput, error) {
+		return &ec2.DescribeVpcPeeringConnectionsOutput{
+			VpcPeeringConnections: []types.VpcPeeringConnection{
+				{
+					VpcPeeringConnectionId: &vpcPeeringConnectionID1,
+					Status: &types.VpcPeeringConnectionStateReason{
+						Code: types.VpcPeeringConnectionStateReasonCodeActive,
+					},
+					RequesterVpcInfo: &types.VpcPeeringConnectionVpcInfo{
+						VpcId:     &vpcId1,
+						OwnerId:   &ownerID_1,
+						Region:    aws.String("eu-west-1"),
+						CidrBlock: aws.String("10.0.0.0/16"),
+					},
+					AccepterVpcInfo: &types.VpcPeeringConnectionVpcInfo{
+						VpcId:     &vpcId2,
+						OwnerId:   &ownerID_2,
+						Region:    aws.String("us-east-1"),
+						CidrBlock: aws.String("10.1.0.0/16"),
+					},
+				},
+			},
+		}, nil
+	})
+	expectedEvents := []beat.Event{
+		{
+			Fields: mapstr.M{
+				"asset.ean":                           "network:" + vpcPeeringConnectionID1,
+				"asset.id":                            vpcPeeringConnectionID1,
+				"asset.type":                          "aws.vpc_peering_connection",
+				"asset.kind":                          "network",
+				"asset.parents":                       []string{"network:" + vpcId1, "network:" + vpcId2},
+				"asset.metadata.status":               "active",
+				"asset.metadata.requester.vpc_id":     vpcId1,
+				"asset.metadata.requester.owner_id":   ownerID_1,
+				"asset.metadata.requester.region":     "eu-west-1",
+				"asset.metadata.requester.cidr_block": "10.0.0.0/16",
+				"asset.metadata.accepter.vpc_id":      vpcId2,
+				"asset.metadata.accepter.owner_id":    ownerID_2,
+				"asset.metadata.accepter.region":      "us-east-1",
+				"asset.metadata.accepter.cidr_block":  "10.1.0.0/16",
+				"cloud.account.id":                    ownerID_1,
+				"cloud.provider":                      "aws",
+				"cloud.region":                        "eu-west-1",
+			},
+			Meta: mapstr.M{
+				"index": internal.GetDefaultIndexName(),
+			},
+		},
+	}
+
+	publisher := testutil.NewInMemoryPublisher()
+	err := collectVPCPeeringAssets(context.Background(), client, "eu-west-1", logp.NewLogger("test"), publisher)
+	assert.NoError(t, err)
+	assert.Equal(t, expectedEvents, publisher.Events)
+}
@@ -0,0 +1,185 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package aws
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/elastic/assetbeat/input/internal"
+	stateless "github.com/elastic/beats/v7/filebeat/input/v2/input-stateless"
+
+	"github.com/elastic/elastic-agent-libs/logp"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/route53"
+	"github.com/aws/aws-sdk-go-v2/service/route53/types"
+)
+
+// route53TagsBatchSize is the maximum number of hosted zones ListTagsForResources accepts at once.
+const route53TagsBatchSize = 10
+
+type route53Client interface {
+	route53.ListHostedZonesAPIClient
+	route53.ListResourceRecordSetsAPIClient
+	ListTagsForResources(ctx context.Context, params *route53.ListTagsForResourcesInput, optFns ...func(*route53.Options)) (*route53.ListTagsForResourcesOutput, error)
+}
+
+// collectRoute53Assets publishes every hosted zone of the account. When a target index is given,
+// the records of each zone are listed, and the load balancers and Elastic IPs they point at
+// are published as children of the zone.
+func collectRoute53Assets(ctx context.Context, client route53Client, accountID string, targets *awsTargetIndex, log *logp.Logger, publisher stateless.Publisher) error {
+	zones, err := listRoute53HostedZones(ctx, client)
+	if err != nil {
+		return err
+	}
+
+	zoneIDs := make([]string, 0, len(zones))
+	for _, zone := range zones {
+		zoneIDs = append(zoneIDs, getRoute53HostedZoneID(zone))
+	}
+	tags := getRoute53HostedZoneTags(ctx, client, zoneIDs, log)
+
+	assetType := "aws.route53.hosted_zone"
+	assetKind := "dns_zone"
+	for i, zone := range zones {
+		zoneID := zoneIDs[i]
+		metadata := mapstr.M{
+			"record_count": aws.ToInt64(zone.ResourceRecordSetCount),
+		}
+		if zone.Config != nil {
+			metadata["private_zone"] = zone.Config.PrivateZone
+			if aws.ToString(zone.Config.Comment) != "" {
+				metadata["comment"] = *zone.Config.Comment
+			}
+		}
+
+		options := []internal.AssetOption{
+			internal.WithAssetCloudProvider("aws"),
+			internal.WithAssetAccountID(accountID),
+			internal.WithAssetKindAndID(assetKind, zoneID),
+			internal.WithAssetType(assetType),
+			internal.WithAssetName(strings.TrimSuffix(aws.ToString(zone.Name), ".")),
+			WithAssetTags(flattenRoute53Tags(tags[zoneID])),
+			internal.WithAssetMetadata(metadata),
+		}
+		if targets != nil {
+			children, err := getRoute53RecordTargets(ctx, client, zone.Id, targets)
+			if err != nil {
+				log.Warnf("error listing records of Route 53 hosted zone %s: %v", zoneID, err)
+			} else if len(children) > 0 {
+				options = append(options, internal.WithAssetChildren(children))
+			}
+		}
+		internal.Publish(publisher, nil, options...)
+	}
+
+	return nil
+}
+
+// getRoute53RecordTargets returns the EANs of the indexed assets the records of a hosted zone point at:
+// load balancers through alias records, and Elastic IPs through A and AAAA records.
+func getRoute53RecordTargets(ctx context.Context, client route53.ListResourceRecordSetsAPIClient, zoneID *string, targets *awsTargetIndex) ([]string, error) {
+	var eans []string
+	seen := map[string]bool{}
+	add := func(ean string) {
+		if !seen[ean] {
+			seen[ean] = true
+			eans = append(eans, ean)
+		}
+	}
+
+	paginator := route53.NewListResourceRecordSetsPaginator(client, &route53.ListResourceRecordSetsInput{HostedZoneId: zoneID})
+	for paginator.HasMorePages() {
+		resp, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, record := range resp.ResourceRecordSets {
+			if record.AliasTarget != nil {
+				if ean, ok := targets.lookupLoadBalancer(aws.ToString(record.AliasTarget.DNSName)); ok {
+					add(ean)
+				}
+				continue
+			}
+			if record.Type != types.RRTypeA && record.Type != types.RRTypeAaaa {
+				continue
+			}
+			for _, value := range record.ResourceRecords {
+				if ean, ok := targets.lookupElasticIP(aws.ToString(value.Value)); ok {
+					add(ean)
+				}
+			}
+		}
+	}
+	return eans, nil
+}
+
+func listRoute53HostedZones(ctx context.Context, client route53.ListHostedZonesAPIClient) ([]types.HostedZone, error) {
+	zones := make([]types.HostedZone, 0, 100)
+	paginator := route53.NewListHostedZonesPaginator(client, &route53.ListHostedZonesInput{})
+	for paginator.HasMorePages() {
+		resp, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error listing Route 53 hosted zones: %w", err)
+		}
+
+		zones = append(zones, resp.HostedZones...)
+	}
+
+	return zones, nil
+}
+
+// getRoute53HostedZoneTags returns the tags of the given hosted zones, by zone ID.
+func getRoute53HostedZoneTags(ctx context.Context, client route53Client, zoneIDs []string, log *logp.Logger) map[string][]types.Tag {
+	tags := make(map[string][]types.Tag, len(zoneIDs))
+	for start := 0; start < len(zoneIDs); start += route53TagsBatchSize {
+		end := start + route53TagsBatchSize
+		if end > len(zoneIDs) {
+			end = len(zoneIDs)
+		}
+		resp, err := client.ListTagsForResources(ctx, &route53.ListTagsForResourcesInput{
+			ResourceType: types.TagResourceTypeHostedzone,
+			ResourceIds:  zoneIDs[start:end],
+		})
+		if err != nil {
+			log.Warnf("error getting tags of Route 53 hosted zones: %v", err)
+			continue
+		}
+		for _, tagSet := range resp.ResourceTagSets {
+			tags[aws.ToString(tagSet.ResourceId)] = tagSet.Tags
+		}
+	}
+	return tags
+}
+
+// getRoute53HostedZoneID strips the `/hostedzone/` prefix Route 53 returns zone IDs with.
+func getRoute53HostedZoneID(zone types.HostedZone) string {
+	return strings.TrimPrefix(aws.ToString(zone.Id), "/hostedzone/")
+}
+
+// flattenRoute53Tags converts the Route 53 tag format to a simple `map[string]string`
+func flattenRoute53Tags(tags []types.Tag) mapstr.M {
+	out := mapstr.M{}
+	for _, t := range tags {
+		out[*t.Key] = *t.Value
+	}
+	return out
+}
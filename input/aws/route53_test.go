@@ -0,0 +1,153 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package aws
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/route53"
+	"github.com/aws/aws-sdk-go-v2/service/route53/types"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/elastic/assetbeat/input/internal"
+	"github.com/elastic/assetbeat/input/testutil"
+	"github.com/elastic/beats/v7/libbeat/beat"
+	"github.com/elastic/elastic-agent-libs/logp"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+var (
+	hostedZoneID_1      = "Z0123456789ABCDEFGHIJ"
+	loadBalancerARN_1   = "arn:aws:elasticloadbalancing:eu-west-1:11111111111111:loadbalancer/app/my-lb/1111"
+	loadBalancerDNSName = "my-lb-1111.eu-west-1.elb.amazonaws.com"
+)
+
+type mockRoute53Client struct {
+	zones   []types.HostedZone
+	records map[string][]types.ResourceRecordSet
+	tags    map[string][]types.Tag
+}
+
+func (m mockRoute53Client) ListHostedZones(ctx context.Context, params *route53.ListHostedZonesInput, optFns ...func(*route53.Options)) (*route53.ListHostedZonesOutput, error) {
+	return &route53.ListHostedZonesOutput{HostedZones: m.zones}, nil
+}
+
+func (m mockRoute53Client) ListResourceRecordSets(ctx context.Context, params *route53.ListResourceRecordSetsInput, optFns ...func(*route53.Options)) (*route53.ListResourceRecordSetsOutput, error) {
+	return &route53.ListResourceRecordSetsOutput{ResourceRecordSets: m.records[*params.HostedZoneId]}, nil
+}
+
+func (m mockRoute53Client) ListTagsForResources(ctx context.Context, params *route53.ListTagsForResourcesInput, optFns ...func(*route53.Options)) (*route53.ListTagsForResourcesOutput, error) {
+	var tagSets []types.ResourceTagSet
+	for _, id := range params.ResourceIds {
+		id := id
+		tagSets = append(tagSets, types.ResourceTagSet{ResourceId: &id, Tags: m.tags[id]})
+	}
+	return &route53.ListTagsForResourcesOutput{ResourceTagSets: tagSets}, nil
+}
+
+func TestAssetsAWS_collectRoute53Assets(t *testing.T) {
+	client := mockRoute53Client{
+		zones: []types.HostedZone{
+			{
+				Id:                     aws.String("/hostedzone/" + hostedZoneID_1),
+				Name:                   aws.String("example.com."),
+				ResourceRecordSetCount: aws.Int64(4),
+				Config:                 &types.HostedZoneConfig{Comment: aws.String("public zone"), PrivateZone: false},
+			},
+		},
+		records: map[string][]types.ResourceRecordSet{
+			"/hostedzone/" + hostedZoneID_1: {
+				{
+					Name:        aws.String("www.example.com."),
+					Type:        types.RRTypeA,
+					AliasTarget: &types.AliasTarget{DNSName: aws.String("dualstack." + loadBalancerDNSName + ".")},
+				},
+				{
+					Name:            aws.String("api.example.com."),
+					Type:            types.RRTypeA,
+					ResourceRecords: []types.ResourceRecord{{Value: aws.String("198.51.100.1")}, {Value: aws.String("203.0.113.1")}},
+				},
+				{
+					Name:            aws.String("example.com."),
+					Type:            types.RRTypeTxt,
+					ResourceRecords: []types.ResourceRecord{{Value: aws.String(`"198.51.100.1"`)}},
+				},
+			},
+		},
+		tags: map[string][]types.Tag{
+			hostedZoneID_1: {{Key: &tag_1_k, Value: &tag_1_v}},
+		},
+	}
+
+	targets := newAWSTargetIndex()
+	targets.loadBalancers[loadBalancerDNSName] = "resource:" + loadBalancerARN_1
+	targets.elasticIPs["198.51.100.1"] = "network:" + allocationID1
+
+	expectedFields := mapstr.M{
+		"asset.ean":                      "dns_zone:" + hostedZoneID_1,
+		"asset.id":                       hostedZoneID_1,
+		"asset.name":                     "example.com",
+		"asset.type":                     "aws.route53.hosted_zone",
+		"asset.kind":                     "dns_zone",
+		"asset.metadata.record_count":    int64(4),
+		"asset.metadata.private_zone":    false,
+		"asset.metadata.comment":         "public zone",
+		"asset.metadata.tags." + tag_1_k: tag_1_v,
+		"cloud.account.id":               ownerID_1,
+		"cloud.provider":                 "aws",
+	}
+
+	for _, tt := range []struct {
+		name    string
+		targets *awsTargetIndex
+
+		expectedChildren []string
+	}{
+		{
+			name: "without record relationships",
+		},
+		{
+			name:             "with record relationships",
+			targets:          targets,
+			expectedChildren: []string{"resource:" + loadBalancerARN_1, "network:" + allocationID1},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			fields := expectedFields.Clone()
+			if tt.expectedChildren != nil {
+				fields["asset.children"] = tt.expectedChildren
+			}
+			expectedEvents := []beat.Event{
+				{
+					Fields: fields,
+					Meta: mapstr.M{
+						"index": internal.GetDefaultIndexName(),
+					},
+				},
+			}
+
+			publisher := testutil.NewInMemoryPublisher()
+			log := logp.NewLogger("mylogger")
+			err := collectRoute53Assets(context.Background(), client, ownerID_1, tt.targets, log, publisher)
+			assert.NoError(t, err)
+			assert.Equal(t, expectedEvents, publisher.Events)
+		})
+	}
+}
@@ -0,0 +1,194 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/elastic/assetbeat/input/internal"
+	stateless "github.com/elastic/beats/v7/filebeat/input/v2/input-stateless"
+
+	"github.com/elastic/elastic-agent-libs/logp"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+)
+
+type S3Bucket struct {
+	Name     string
+	Region   string
+	Tags     []types.Tag
+	Metadata mapstr.M
+}
+
+// s3Client is the subset of the S3 API used to describe buckets.
+type s3Client interface {
+	ListBuckets(ctx context.Context, params *s3.ListBucketsInput, optFns ...func(*s3.Options)) (*s3.ListBucketsOutput, error)
+	GetBucketLocation(ctx context.Context, params *s3.GetBucketLocationInput, optFns ...func(*s3.Options)) (*s3.GetBucketLocationOutput, error)
+	GetBucketVersioning(ctx context.Context, params *s3.GetBucketVersioningInput, optFns ...func(*s3.Options)) (*s3.GetBucketVersioningOutput, error)
+	GetBucketEncryption(ctx context.Context, params *s3.GetBucketEncryptionInput, optFns ...func(*s3.Options)) (*s3.GetBucketEncryptionOutput, error)
+	GetPublicAccessBlock(ctx context.Context, params *s3.GetPublicAccessBlockInput, optFns ...func(*s3.Options)) (*s3.GetPublicAccessBlockOutput, error)
+	GetBucketTagging(ctx context.Context, params *s3.GetBucketTaggingInput, optFns ...func(*s3.Options)) (*s3.GetBucketTaggingOutput, error)
+}
+
+// collectS3Assets publishes every bucket owned by the account, whichever region they are in.
+func collectS3Assets(ctx context.Context, client s3Client, accountID string, log *logp.Logger, publisher stateless.Publisher) error {
+	buckets, err := describeS3Buckets(ctx, client, log)
+	if err != nil {
+		return err
+	}
+
+	assetType := "aws.s3.bucket"
+	assetKind := "bucket"
+	for _, bucket := range buckets {
+		internal.Publish(publisher, nil,
+			internal.WithAssetCloudProvider("aws"),
+			internal.WithAssetRegion(bucket.Region),
+			internal.WithAssetAccountID(accountID),
+			internal.WithAssetKindAndID(assetKind, bucket.Name),
+			internal.WithAssetName(bucket.Name),
+			internal.WithAssetType(assetType),
+			WithAssetTags(flattenS3Tags(bucket.Tags)),
+			internal.WithAssetMetadata(bucket.Metadata),
+		)
+	}
+
+	return nil
+}
+
+func describeS3Buckets(ctx context.Context, client s3Client, log *logp.Logger) ([]S3Bucket, error) {
+	resp, err := client.ListBuckets(ctx, &s3.ListBucketsInput{})
+	if err != nil {
+		return nil, fmt.Errorf("error listing S3 buckets: %w", err)
+	}
+
+	buckets := make([]S3Bucket, 0, len(resp.Buckets))
+	for _, b := range resp.Buckets {
+		name := aws.ToString(b.Name)
+		region, err := getS3BucketRegion(ctx, client, name)
+		if err != nil {
+			log.Errorf("error retrieving region of S3 bucket %s: %v", name, err)
+			continue
+		}
+		// Bucket configuration must be requested from the region the bucket lives in.
+		inRegion := func(o *s3.Options) {
+			o.Region = region
+		}
+
+		bucket := S3Bucket{
+			Name:   name,
+			Region: region,
+			Metadata: mapstr.M{
+				"creation_date": b.CreationDate,
+			},
+		}
+
+		versioning, err := client.GetBucketVersioning(ctx, &s3.GetBucketVersioningInput{Bucket: &name}, inRegion)
+		if err != nil {
+			log.Errorf("error retrieving versioning of S3 bucket %s: %v", name, err)
+		} else {
+			bucket.Metadata["versioning"] = mapstr.M{
+				"status":     string(versioning.Status),
+				"mfa_delete": string(versioning.MFADelete),
+			}
+		}
+
+		encryption, err := client.GetBucketEncryption(ctx, &s3.GetBucketEncryptionInput{Bucket: &name}, inRegion)
+		if err != nil {
+			if !isS3ErrorCode(err, "ServerSideEncryptionConfigurationNotFoundError") {
+				log.Errorf("error retrieving encryption of S3 bucket %s: %v", name, err)
+			}
+		} else if encryption.ServerSideEncryptionConfiguration != nil && len(encryption.ServerSideEncryptionConfiguration.Rules) > 0 {
+			rule := encryption.ServerSideEncryptionConfiguration.Rules[0]
+			encryptionMetadata := mapstr.M{
+				"bucket_key_enabled": rule.BucketKeyEnabled,
+			}
+			if rule.ApplyServerSideEncryptionByDefault != nil {
+				encryptionMetadata["sse_algorithm"] = string(rule.ApplyServerSideEncryptionByDefault.SSEAlgorithm)
+				if rule.ApplyServerSideEncryptionByDefault.KMSMasterKeyID != nil {
+					encryptionMetadata["kms_master_key_id"] = *rule.ApplyServerSideEncryptionByDefault.KMSMasterKeyID
+				}
+			}
+			bucket.Metadata["encryption"] = encryptionMetadata
+		}
+
+		publicAccessBlock, err := client.GetPublicAccessBlock(ctx, &s3.GetPublicAccessBlockInput{Bucket: &name}, inRegion)
+		if err != nil {
+			if !isS3ErrorCode(err, "NoSuchPublicAccessBlockConfiguration") {
+				log.Errorf("error retrieving public access block of S3 bucket %s: %v", name, err)
+			}
+		} else if cfg := publicAccessBlock.PublicAccessBlockConfiguration; cfg != nil {
+			bucket.Metadata["public_access_block"] = mapstr.M{
+				"block_public_acls":       cfg.BlockPublicAcls,
+				"ignore_public_acls":      cfg.IgnorePublicAcls,
+				"block_public_policy":     cfg.BlockPublicPolicy,
+				"restrict_public_buckets": cfg.RestrictPublicBuckets,
+			}
+		}
+
+		tagging, err := client.GetBucketTagging(ctx, &s3.GetBucketTaggingInput{Bucket: &name}, inRegion)
+		if err != nil {
+			if !isS3ErrorCode(err, "NoSuchTagSet") {
+				log.Errorf("error retrieving tags of S3 bucket %s: %v", name, err)
+			}
+		} else {
+			bucket.Tags = tagging.TagSet
+		}
+
+		buckets = append(buckets, bucket)
+	}
+	return buckets, nil
+}
+
+// getS3BucketRegion resolves the region of a bucket from its location constraint.
+// Buckets in us-east-1 have no location constraint, and the legacy `EU` constraint stands for eu-west-1.
+func getS3BucketRegion(ctx context.Context, client s3Client, bucket string) (string, error) {
+	resp, err := client.GetBucketLocation(ctx, &s3.GetBucketLocationInput{Bucket: &bucket})
+	if err != nil {
+		return "", err
+	}
+	switch resp.LocationConstraint {
+	case "":
+		return "us-east-1", nil
+	case types.BucketLocationConstraintEu:
+		return "eu-west-1", nil
+	default:
+		return string(resp.LocationConstraint), nil
+	}
+}
+
+// isS3ErrorCode reports whether err is an S3 API error with the given code.
+// S3 uses error codes to signal that an optional bucket configuration is not set.
+func isS3ErrorCode(err error, code string) bool {
+	var apiErr smithy.APIError
+	return errors.As(err, &apiErr) && apiErr.ErrorCode() == code
+}
+
+// flattenS3Tags converts the S3 tag format to a simple `map[string]string`
+func flattenS3Tags(tags []types.Tag) mapstr.M {
+	out := mapstr.M{}
+	for _, t := range tags {
+		out[*t.Key] = *t.Value
+	}
+	return out
+}
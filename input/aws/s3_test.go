@@ -0,0 +1,175 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package aws
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/elastic/assetbeat/input/internal"
+	"github.com/elastic/assetbeat/input/testutil"
+	"github.com/elastic/beats/v7/libbeat/beat"
+	"github.com/elastic/elastic-agent-libs/logp"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+type mockS3Client struct {
+	buckets            []types.Bucket
+	locations          map[string]types.BucketLocationConstraint
+	versioning         map[string]types.BucketVersioningStatus
+	encryption         map[string]types.ServerSideEncryption
+	publicAccessBlocks map[string]types.PublicAccessBlockConfiguration
+	tags               map[string][]types.Tag
+	// requestedRegions records the region each bucket configuration was requested from
+	requestedRegions map[string]string
+}
+
+func (m mockS3Client) ListBuckets(ctx context.Context, params *s3.ListBucketsInput, optFns ...func(*s3.Options)) (*s3.ListBucketsOutput, error) {
+	return &s3.ListBucketsOutput{Buckets: m.buckets}, nil
+}
+
+func (m mockS3Client) GetBucketLocation(ctx context.Context, params *s3.GetBucketLocationInput, optFns ...func(*s3.Options)) (*s3.GetBucketLocationOutput, error) {
+	return &s3.GetBucketLocationOutput{LocationConstraint: m.locations[*params.Bucket]}, nil
+}
+
+func (m mockS3Client) GetBucketVersioning(ctx context.Context, params *s3.GetBucketVersioningInput, optFns ...func(*s3.Options)) (*s3.GetBucketVersioningOutput, error) {
+	m.recordRegion(*params.Bucket, optFns)
+	return &s3.GetBucketVersioningOutput{Status: m.versioning[*params.Bucket]}, nil
+}
+
+func (m mockS3Client) GetBucketEncryption(ctx context.Context, params *s3.GetBucketEncryptionInput, optFns ...func(*s3.Options)) (*s3.GetBucketEncryptionOutput, error) {
+	algorithm, ok := m.encryption[*params.Bucket]
+	if !ok {
+		return nil, &smithy.GenericAPIError{Code: "ServerSideEncryptionConfigurationNotFoundError"}
+	}
+	return &s3.GetBucketEncryptionOutput{
+		ServerSideEncryptionConfiguration: &types.ServerSideEncryptionConfiguration{
+			Rules: []types.ServerSideEncryptionRule{
+				{ApplyServerSideEncryptionByDefault: &types.ServerSideEncryptionByDefault{SSEAlgorithm: algorithm}},
+			},
+		},
+	}, nil
+}
+
+func (m mockS3Client) GetPublicAccessBlock(ctx context.Context, params *s3.GetPublicAccessBlockInput, optFns ...func(*s3.Options)) (*s3.GetPublicAccessBlockOutput, error) {
+	cfg, ok := m.publicAccessBlocks[*params.Bucket]
+	if !ok {
+		return nil, &smithy.GenericAPIError{Code: "NoSuchPublicAccessBlockConfiguration"}
+	}
+	return &s3.GetPublicAccessBlockOutput{PublicAccessBlockConfiguration: &cfg}, nil
+}
+
+func (m mockS3Client) GetBucketTagging(ctx context.Context, params *s3.GetBucketTaggingInput, optFns ...func(*s3.Options)) (*s3.GetBucketTaggingOutput, error) {
+	tags, ok := m.tags[*params.Bucket]
+	if !ok {
+		return nil, &smithy.GenericAPIError{Code: "NoSuchTagSet"}
+	}
+	return &s3.GetBucketTaggingOutput{TagSet: tags}, nil
+}
+
+func (m mockS3Client) recordRegion(bucket string, optFns []func(*s3.Options)) {
+	o := s3.Options{}
+	for _, fn := range optFns {
+		fn(&o)
+	}
+	m.requestedRegions[bucket] = o.Region
+}
+
+func TestAssetsAWS_collectS3Assets(t *testing.T) {
+	creationDate := time.Date(2023, 9, 1, 10, 0, 0, 0, time.UTC)
+	client := mockS3Client{
+		buckets: []types.Bucket{
+			{Name: aws.String("bucket-us"), CreationDate: &creationDate},
+			{Name: aws.String("bucket-eu"), CreationDate: &creationDate},
+			{Name: aws.String("bucket-ap"), CreationDate: &creationDate},
+		},
+		locations: map[string]types.BucketLocationConstraint{
+			"bucket-eu": types.BucketLocationConstraintEu,
+			"bucket-ap": types.BucketLocationConstraintApSoutheast1,
+		},
+		versioning: map[string]types.BucketVersioningStatus{
+			"bucket-eu": types.BucketVersioningStatusEnabled,
+		},
+		encryption: map[string]types.ServerSideEncryption{
+			"bucket-us": types.ServerSideEncryptionAes256,
+		},
+		publicAccessBlocks: map[string]types.PublicAccessBlockConfiguration{
+			"bucket-us": {BlockPublicAcls: true, IgnorePublicAcls: true, BlockPublicPolicy: true, RestrictPublicBuckets: true},
+		},
+		tags: map[string][]types.Tag{
+			"bucket-ap": {{Key: &tag_1_k, Value: &tag_1_v}},
+		},
+		requestedRegions: map[string]string{},
+	}
+
+	bucketEvent := func(name, region string, fields mapstr.M) beat.Event {
+		event := beat.Event{
+			Fields: mapstr.M{
+				"asset.ean":                            "bucket:" + name,
+				"asset.id":                             name,
+				"asset.name":                           name,
+				"asset.type":                           "aws.s3.bucket",
+				"asset.kind":                           "bucket",
+				"asset.metadata.creation_date":         &creationDate,
+				"asset.metadata.versioning.status":     "",
+				"asset.metadata.versioning.mfa_delete": "",
+				"cloud.account.id":                     "11111111111111",
+				"cloud.provider":                       "aws",
+				"cloud.region":                         region,
+			},
+			Meta: mapstr.M{
+				"index": internal.GetDefaultIndexName(),
+			},
+		}
+		event.Fields.Update(fields)
+		return event
+	}
+	expectedEvents := []beat.Event{
+		bucketEvent("bucket-us", "us-east-1", mapstr.M{
+			"asset.metadata.encryption.sse_algorithm":                    "AES256",
+			"asset.metadata.encryption.bucket_key_enabled":               false,
+			"asset.metadata.public_access_block.block_public_acls":       true,
+			"asset.metadata.public_access_block.ignore_public_acls":      true,
+			"asset.metadata.public_access_block.block_public_policy":     true,
+			"asset.metadata.public_access_block.restrict_public_buckets": true,
+		}),
+		bucketEvent("bucket-eu", "eu-west-1", mapstr.M{
+			"asset.metadata.versioning.status": "Enabled",
+		}),
+		bucketEvent("bucket-ap", "ap-southeast-1", mapstr.M{
+			"asset.metadata.tags." + tag_1_k: tag_1_v,
+		}),
+	}
+
+	publisher := testutil.NewInMemoryPublisher()
+	err := collectS3Assets(context.Background(), client, "11111111111111", logp.NewLogger("test"), publisher)
+	assert.NoError(t, err)
+	assert.Equal(t, expectedEvents, publisher.Events)
+	assert.Equal(t, map[string]string{
+		"bucket-us": "us-east-1",
+		"bucket-eu": "eu-west-1",
+		"bucket-ap": "ap-southeast-1",
+	}, client.requestedRegions)
+}
@@ -0,0 +1,171 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package aws
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/elastic/assetbeat/input/internal"
+	stateless "github.com/elastic/beats/v7/filebeat/input/v2/input-stateless"
+
+	"github.com/elastic/elastic-agent-libs/logp"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+type securityGroupsAPIClient interface {
+	ec2.DescribeSecurityGroupsAPIClient
+	ec2.DescribeInstancesAPIClient
+}
+
+func collectSecurityGroupAssets(ctx context.Context, client securityGroupsAPIClient, region string, log *logp.Logger, publisher stateless.Publisher) error {
+	groups, err := describeSecurityGroups(ctx, client)
+	if err != nil {
+		return err
+	}
+	instances, err := describeEC2Instances(ctx, client)
+	if err != nil {
+		return err
+	}
+
+	groupInstances := make(map[string][]string, len(groups))
+	for _, instance := range instances {
+		for _, groupID := range instance.SecurityGroupIDs {
+			groupInstances[groupID] = append(groupInstances[groupID], "host:"+instance.InstanceID)
+		}
+	}
+
+	assetType := "aws.ec2.security_group"
+	assetKind := "security_group"
+	for _, group := range groups {
+		var parents []string
+		if group.VpcId != nil {
+			parents = append(parents, "network:"+*group.VpcId)
+		}
+		options := []internal.AssetOption{
+			internal.WithAssetCloudProvider("aws"),
+			internal.WithAssetRegion(region),
+			internal.WithAssetAccountID(aws.ToString(group.OwnerId)),
+			internal.WithAssetKindAndID(assetKind, *group.GroupId),
+			internal.WithAssetType(assetType),
+			internal.WithAssetName(aws.ToString(group.GroupName)),
+			WithAssetTags(flattenEC2Tags(group.Tags)),
+			internal.WithAssetMetadata(getSecurityGroupMetadata(group)),
+		}
+		if parents != nil {
+			options = append(options, internal.WithAssetParents(parents))
+		}
+		if children, ok := groupInstances[*group.GroupId]; ok {
+			options = append(options, internal.WithAssetChildren(children))
+		}
+		internal.Publish(publisher, nil,
+			options...,
+		)
+	}
+
+	return nil
+}
+
+func getSecurityGroupMetadata(group types.SecurityGroup) mapstr.M {
+	metadata := mapstr.M{
+		"description":   aws.ToString(group.Description),
+		"ingress_rules": summarizeSecurityGroupRules(group.IpPermissions),
+		"egress_rules":  summarizeSecurityGroupRules(group.IpPermissionsEgress),
+	}
+
+	var referencedGroups []string
+	seen := map[string]bool{}
+	for _, permissions := range [][]types.IpPermission{group.IpPermissions, group.IpPermissionsEgress} {
+		for _, permission := range permissions {
+			for _, pair := range permission.UserIdGroupPairs {
+				groupID := aws.ToString(pair.GroupId)
+				if groupID == "" || groupID == *group.GroupId || seen[groupID] {
+					continue
+				}
+				seen[groupID] = true
+				referencedGroups = append(referencedGroups, "security_group:"+groupID)
+			}
+		}
+	}
+	if referencedGroups != nil {
+		metadata["referenced_security_groups"] = referencedGroups
+	}
+
+	return metadata
+}
+
+// summarizeSecurityGroupRules returns one "<protocol>:<ports> <source>" entry per source of each rule,
+// e.g. "tcp:443 0.0.0.0/0" or "all:all sg-0a1b2c3d".
+func summarizeSecurityGroupRules(permissions []types.IpPermission) []string {
+	rules := make([]string, 0, len(permissions))
+	for _, permission := range permissions {
+		prefix := getSecurityGroupRuleProtocol(permission) + ":" + getSecurityGroupRulePorts(permission)
+		for _, r := range permission.IpRanges {
+			rules = append(rules, prefix+" "+aws.ToString(r.CidrIp))
+		}
+		for _, r := range permission.Ipv6Ranges {
+			rules = append(rules, prefix+" "+aws.ToString(r.CidrIpv6))
+		}
+		for _, p := range permission.PrefixListIds {
+			rules = append(rules, prefix+" "+aws.ToString(p.PrefixListId))
+		}
+		for _, pair := range permission.UserIdGroupPairs {
+			rules = append(rules, prefix+" "+aws.ToString(pair.GroupId))
+		}
+	}
+	return rules
+}
+
+func getSecurityGroupRuleProtocol(permission types.IpPermission) string {
+	protocol := aws.ToString(permission.IpProtocol)
+	if protocol == "-1" {
+		return "all"
+	}
+	return protocol
+}
+
+func getSecurityGroupRulePorts(permission types.IpPermission) string {
+	if permission.FromPort == nil || permission.ToPort == nil || aws.ToInt32(permission.FromPort) == -1 {
+		return "all"
+	}
+	from, to := *permission.FromPort, *permission.ToPort
+	if from == to {
+		return strconv.Itoa(int(from))
+	}
+	return strconv.Itoa(int(from)) + "-" + strconv.Itoa(int(to))
+}
+
+func describeSecurityGroups(ctx context.Context, client ec2.DescribeSecurityGroupsAPIClient) ([]types.SecurityGroup, error) {
+	groups := make([]types.SecurityGroup, 0, 100)
+	paginator := ec2.NewDescribeSecurityGroupsPaginator(client, &ec2.DescribeSecurityGroupsInput{})
+	for paginator.HasMorePages() {
+		resp, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error describing security groups: %w", err)
+		}
+
+		groups = append(groups, resp.SecurityGroups...)
+	}
+
+	return groups, nil
+}
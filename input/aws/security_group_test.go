@@ -0,0 +1,167 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package aws
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/elastic/assetbeat/input/internal"
+	"github.com/elastic/assetbeat/input/testutil"
+	"github.com/elastic/beats/v7/libbeat/beat"
+	"github.com/elastic/elastic-agent-libs/logp"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+var securityGroupID_2 = "sg-2222222"
+
+type mockSecurityGroupsAPI struct {
+	groups       []types.SecurityGroup
+	reservations []types.Reservation
+}
+
+func (m mockSecurityGroupsAPI) DescribeSecurityGroups(ctx context.Context, params *ec2.DescribeSecurityGroupsInput, optFns ...func(*ec2.Options)) (*ec2.DescribeSecurityGroupsOutput, error) {
+	return &ec2.DescribeSecurityGroupsOutput{SecurityGroups: m.groups}, nil
+}
+
+func (m mockSecurityGroupsAPI) DescribeInstances(ctx context.Context, params *ec2.DescribeInstancesInput, optFns ...func(*ec2.Options)) (*ec2.DescribeInstancesOutput, error) {
+	return &ec2.DescribeInstancesOutput{Reservations: m.reservations}, nil
+}
+
+func TestAssetsAWS_collectSecurityGroupAssets(t *testing.T) {
+	client := mockSecurityGroupsAPI{
+		groups: []types.SecurityGroup{
+			{
+				GroupId:     &securityGroupID_1,
+				GroupName:   aws.String("web"),
+				Description: aws.String("web servers"),
+				OwnerId:     &ownerID_1,
+				VpcId:       &vpcID1,
+				Tags: []types.Tag{
+					{
+						Key:   &tag_1_k,
+						Value: &tag_1_v,
+					},
+				},
+				IpPermissions: []types.IpPermission{
+					{
+						IpProtocol: aws.String("tcp"),
+						FromPort:   aws.Int32(443),
+						ToPort:     aws.Int32(443),
+						IpRanges:   []types.IpRange{{CidrIp: aws.String("0.0.0.0/0")}},
+						Ipv6Ranges: []types.Ipv6Range{{CidrIpv6: aws.String("::/0")}},
+					},
+					{
+						IpProtocol:       aws.String("tcp"),
+						FromPort:         aws.Int32(8000),
+						ToPort:           aws.Int32(8080),
+						UserIdGroupPairs: []types.UserIdGroupPair{{GroupId: &securityGroupID_2}},
+					},
+				},
+				IpPermissionsEgress: []types.IpPermission{
+					{
+						IpProtocol: aws.String("-1"),
+						IpRanges:   []types.IpRange{{CidrIp: aws.String("0.0.0.0/0")}},
+					},
+				},
+			},
+			{
+				GroupId:     &securityGroupID_2,
+				GroupName:   aws.String("lb"),
+				Description: aws.String("load balancers"),
+				OwnerId:     &ownerID_1,
+				VpcId:       &vpcID1,
+			},
+		},
+		reservations: []types.Reservation{
+			{
+				OwnerId: &ownerID_1,
+				Instances: []types.Instance{
+					{
+						InstanceId:     &instanceID_1,
+						State:          &types.InstanceState{Name: "running"},
+						SecurityGroups: []types.GroupIdentifier{{GroupId: &securityGroupID_1}},
+					},
+					{
+						InstanceId:     &instanceID_2,
+						State:          &types.InstanceState{Name: "running"},
+						SecurityGroups: []types.GroupIdentifier{{GroupId: &securityGroupID_1}},
+					},
+				},
+			},
+		},
+	}
+
+	expectedEvents := []beat.Event{
+		{
+			Fields: mapstr.M{
+				"asset.ean":                  "security_group:" + securityGroupID_1,
+				"asset.id":                   securityGroupID_1,
+				"asset.name":                 "web",
+				"asset.type":                 "aws.ec2.security_group",
+				"asset.kind":                 "security_group",
+				"asset.parents":              []string{"network:" + vpcID1},
+				"asset.children":             []string{"host:" + instanceID_1, "host:" + instanceID_2},
+				"asset.metadata.description": "web servers",
+				"asset.metadata.ingress_rules": []string{
+					"tcp:443 0.0.0.0/0",
+					"tcp:443 ::/0",
+					"tcp:8000-8080 " + securityGroupID_2,
+				},
+				"asset.metadata.egress_rules":               []string{"all:all 0.0.0.0/0"},
+				"asset.metadata.referenced_security_groups": []string{"security_group:" + securityGroupID_2},
+				"asset.metadata.tags." + tag_1_k:            tag_1_v,
+				"cloud.account.id":                          ownerID_1,
+				"cloud.provider":                            "aws",
+				"cloud.region":                              "eu-west-1",
+			},
+			Meta: mapstr.M{
+				"index": internal.GetDefaultIndexName(),
+			},
+		},
+		{
+			Fields: mapstr.M{
+				"asset.ean":                    "security_group:" + securityGroupID_2,
+				"asset.id":                     securityGroupID_2,
+				"asset.name":                   "lb",
+				"asset.type":                   "aws.ec2.security_group",
+				"asset.kind":                   "security_group",
+				"asset.parents":                []string{"network:" + vpcID1},
+				"asset.metadata.description":   "load balancers",
+				"asset.metadata.ingress_rules": []string{},
+				"asset.metadata.egress_rules":  []string{},
+				"cloud.account.id":             ownerID_1,
+				"cloud.provider":               "aws",
+				"cloud.region":                 "eu-west-1",
+			},
+			Meta: mapstr.M{
+				"index": internal.GetDefaultIndexName(),
+			},
+		},
+	}
+
+	publisher := testutil.NewInMemoryPublisher()
+	err := collectSecurityGroupAssets(context.Background(), client, "eu-west-1", logp.NewLogger("test"), publisher)
+	assert.NoError(t, err)
+	assert.Equal(t, expectedEvents, publisher.Events)
+}
@@ -0,0 +1,119 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package aws
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/elastic/assetbeat/input/internal"
+	stateless "github.com/elastic/beats/v7/filebeat/input/v2/input-stateless"
+
+	"github.com/elastic/elastic-agent-libs/logp"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+	"github.com/aws/aws-sdk-go-v2/service/sns/types"
+)
+
+type snsClient interface {
+	sns.ListTopicsAPIClient
+	GetTopicAttributes(ctx context.Context, params *sns.GetTopicAttributesInput, optFns ...func(*sns.Options)) (*sns.GetTopicAttributesOutput, error)
+	ListTagsForResource(ctx context.Context, params *sns.ListTagsForResourceInput, optFns ...func(*sns.Options)) (*sns.ListTagsForResourceOutput, error)
+}
+
+func collectSNSAssets(ctx context.Context, client snsClient, region string, log *logp.Logger, publisher stateless.Publisher) error {
+	topics, err := listSNSTopics(ctx, client)
+	if err != nil {
+		return err
+	}
+
+	assetType := "aws.sns.topic"
+	assetKind := "topic"
+	for _, topic := range topics {
+		topicARN, err := arn.Parse(*topic.TopicArn)
+		if err != nil {
+			log.Warnf("skipping SNS topic with invalid ARN %s: %v", *topic.TopicArn, err)
+			continue
+		}
+		resp, err := client.GetTopicAttributes(ctx, &sns.GetTopicAttributesInput{TopicArn: topic.TopicArn})
+		if err != nil {
+			log.Warnf("error getting attributes of SNS topic %s: %v", *topic.TopicArn, err)
+			continue
+		}
+		var tags []types.Tag
+		tagsResp, err := client.ListTagsForResource(ctx, &sns.ListTagsForResourceInput{ResourceArn: topic.TopicArn})
+		if err != nil {
+			log.Warnf("error getting tags of SNS topic %s: %v", *topic.TopicArn, err)
+		} else {
+			tags = tagsResp.Tags
+		}
+		internal.Publish(publisher, nil,
+			internal.WithAssetCloudProvider("aws"),
+			internal.WithAssetRegion(region),
+			internal.WithAssetAccountID(topicARN.AccountID),
+			internal.WithAssetKindAndID(assetKind, *topic.TopicArn),
+			internal.WithAssetType(assetType),
+			internal.WithAssetName(topicARN.Resource),
+			WithAssetTags(flattenSNSTags(tags)),
+			internal.WithAssetMetadata(getSNSTopicMetadata(resp.Attributes)),
+		)
+	}
+
+	return nil
+}
+
+func getSNSTopicMetadata(attributes map[string]string) mapstr.M {
+	metadata := mapstr.M{
+		"fifo":                    attributes["FifoTopic"] == "true",
+		"subscriptions_confirmed": getIntAttribute(attributes, "SubscriptionsConfirmed"),
+		"subscriptions_pending":   getIntAttribute(attributes, "SubscriptionsPending"),
+	}
+	if displayName := attributes["DisplayName"]; displayName != "" {
+		metadata["display_name"] = displayName
+	}
+	if kmsKeyID := attributes["KmsMasterKeyId"]; kmsKeyID != "" {
+		metadata["kms_master_key_id"] = kmsKeyID
+	}
+	return metadata
+}
+
+func listSNSTopics(ctx context.Context, client sns.ListTopicsAPIClient) ([]types.Topic, error) {
+	topics := make([]types.Topic, 0, 100)
+	paginator := sns.NewListTopicsPaginator(client, &sns.ListTopicsInput{})
+	for paginator.HasMorePages() {
+		resp, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error listing SNS topics: %w", err)
+		}
+
+		topics = append(topics, resp.Topics...)
+	}
+
+	return topics, nil
+}
+
+// flattenSNSTags converts the SNS tag format to a simple `map[string]string`
+func flattenSNSTags(tags []types.Tag) mapstr.M {
+	out := mapstr.M{}
+	for _, t := range tags {
+		out[*t.Key] = *t.Value
+	}
+	return out
+}
@@ -0,0 +1,129 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package aws
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+	"github.com/aws/aws-sdk-go-v2/service/sns/types"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/elastic/assetbeat/input/internal"
+	"github.com/elastic/assetbeat/input/testutil"
+	"github.com/elastic/beats/v7/libbeat/beat"
+	"github.com/elastic/elastic-agent-libs/logp"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+var snsTopicARN_1 = "arn:aws:sns:eu-west-1:11111111111111:alerts"
+
+type mockSNSClient struct {
+	attributes      map[string]map[string]string
+	attributeErrors map[string]error
+	tags            map[string][]types.Tag
+}
+
+func (m mockSNSClient) ListTopics(ctx context.Context, params *sns.ListTopicsInput, optFns ...func(*sns.Options)) (*sns.ListTopicsOutput, error) {
+	var topics []types.Topic
+	for topicARN := range m.attributeErrors {
+		topicARN := topicARN
+		topics = append(topics, types.Topic{TopicArn: &topicARN})
+	}
+	for topicARN := range m.attributes {
+		topicARN := topicARN
+		topics = append(topics, types.Topic{TopicArn: &topicARN})
+	}
+	return &sns.ListTopicsOutput{Topics: topics}, nil
+}
+
+func (m mockSNSClient) GetTopicAttributes(ctx context.Context, params *sns.GetTopicAttributesInput, optFns ...func(*sns.Options)) (*sns.GetTopicAttributesOutput, error) {
+	if err, ok := m.attributeErrors[*params.TopicArn]; ok {
+		return nil, err
+	}
+	return &sns.GetTopicAttributesOutput{Attributes: m.attributes[*params.TopicArn]}, nil
+}
+
+func (m mockSNSClient) ListTagsForResource(ctx context.Context, params *sns.ListTagsForResourceInput, optFns ...func(*sns.Options)) (*sns.ListTagsForResourceOutput, error) {
+	return &sns.ListTagsForResourceOutput{Tags: m.tags[*params.ResourceArn]}, nil
+}
+
+func TestAssetsAWS_collectSNSAssets(t *testing.T) {
+	client := mockSNSClient{
+		attributes: map[string]map[string]string{
+			snsTopicARN_1: {
+				"TopicArn":               snsTopicARN_1,
+				"DisplayName":            "Alerts",
+				"SubscriptionsConfirmed": "3",
+				"SubscriptionsPending":   "1",
+				"KmsMasterKeyId":         "alias/aws/sns",
+			},
+		},
+		tags: map[string][]types.Tag{
+			snsTopicARN_1: {{Key: &tag_1_k, Value: &tag_1_v}},
+		},
+	}
+
+	expectedEvents := []beat.Event{
+		{
+			Fields: mapstr.M{
+				"asset.ean":                              "topic:" + snsTopicARN_1,
+				"asset.id":                               snsTopicARN_1,
+				"asset.name":                             "alerts",
+				"asset.type":                             "aws.sns.topic",
+				"asset.kind":                             "topic",
+				"asset.metadata.fifo":                    false,
+				"asset.metadata.subscriptions_confirmed": 3,
+				"asset.metadata.subscriptions_pending":   1,
+				"asset.metadata.display_name":            "Alerts",
+				"asset.metadata.kms_master_key_id":       "alias/aws/sns",
+				"asset.metadata.tags." + tag_1_k:         tag_1_v,
+				"cloud.account.id":                       ownerID_1,
+				"cloud.provider":                         "aws",
+				"cloud.region":                           "eu-west-1",
+			},
+			Meta: mapstr.M{
+				"index": internal.GetDefaultIndexName(),
+			},
+		},
+	}
+
+	publisher := testutil.NewInMemoryPublisher()
+	err := collectSNSAssets(context.Background(), client, "eu-west-1", logp.NewLogger("test"), publisher)
+	assert.NoError(t, err)
+	assert.Equal(t, expectedEvents, publisher.Events)
+}
+
+func TestAssetsAWS_collectSNSAssets_attributesError(t *testing.T) {
+	client := mockSNSClient{
+		attributes: map[string]map[string]string{
+			snsTopicARN_1: {"TopicArn": snsTopicARN_1},
+		},
+		attributeErrors: map[string]error{
+			"arn:aws:sns:eu-west-1:11111111111111:deleted": errors.New("NotFound: Topic does not exist"),
+		},
+	}
+
+	publisher := testutil.NewInMemoryPublisher()
+	err := collectSNSAssets(context.Background(), client, "eu-west-1", logp.NewLogger("test"), publisher)
+	assert.NoError(t, err)
+	assert.Len(t, publisher.Events, 1)
+	assert.Equal(t, snsTopicARN_1, publisher.Events[0].Fields["asset.id"])
+}
@@ -0,0 +1,137 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/elastic/assetbeat/input/internal"
+	stateless "github.com/elastic/beats/v7/filebeat/input/v2/input-stateless"
+
+	"github.com/elastic/elastic-agent-libs/logp"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	sqstypes "github.com/aws/aws-sdk-go-v2/service/sqs/types"
+)
+
+type sqsQueuesAPIClient interface {
+	sqs.ListQueuesAPIClient
+	GetQueueAttributes(ctx context.Context, params *sqs.GetQueueAttributesInput, optFns ...func(*sqs.Options)) (*sqs.GetQueueAttributesOutput, error)
+	ListQueueTags(ctx context.Context, params *sqs.ListQueueTagsInput, optFns ...func(*sqs.Options)) (*sqs.ListQueueTagsOutput, error)
+}
+
+func collectSQSAssets(ctx context.Context, client sqsQueuesAPIClient, region string, log *logp.Logger, publisher stateless.Publisher) error {
+	queueURLs, err := listSQSQueues(ctx, client)
+	if err != nil {
+		return err
+	}
+
+	assetType := "aws.sqs.queue"
+	assetKind := "queue"
+	for _, queueURL := range queueURLs {
+		resp, err := client.GetQueueAttributes(ctx, &sqs.GetQueueAttributesInput{
+			QueueUrl:       aws.String(queueURL),
+			AttributeNames: []sqstypes.QueueAttributeName{sqstypes.QueueAttributeNameAll},
+		})
+		if err != nil {
+			log.Warnf("error getting attributes of SQS queue %s: %v", queueURL, err)
+			continue
+		}
+		queueARN, err := arn.Parse(resp.Attributes[string(sqstypes.QueueAttributeNameQueueArn)])
+		if err != nil {
+			log.Warnf("skipping SQS queue %s with invalid ARN: %v", queueURL, err)
+			continue
+		}
+		tags := mapstr.M{}
+		tagsResp, err := client.ListQueueTags(ctx, &sqs.ListQueueTagsInput{QueueUrl: aws.String(queueURL)})
+		if err != nil {
+			log.Warnf("error getting tags of SQS queue %s: %v", queueURL, err)
+		} else {
+			tags = internal.ToMapstr(tagsResp.Tags)
+		}
+		internal.Publish(publisher, nil,
+			internal.WithAssetCloudProvider("aws"),
+			internal.WithAssetRegion(region),
+			internal.WithAssetAccountID(queueARN.AccountID),
+			internal.WithAssetKindAndID(assetKind, queueARN.String()),
+			internal.WithAssetType(assetType),
+			internal.WithAssetName(queueARN.Resource),
+			WithAssetTags(tags),
+			internal.WithAssetMetadata(getSQSQueueMetadata(queueURL, resp.Attributes)),
+		)
+	}
+
+	return nil
+}
+
+func getSQSQueueMetadata(queueURL string, attributes map[string]string) mapstr.M {
+	metadata := mapstr.M{
+		"url":                            queueURL,
+		"fifo":                           attributes[string(sqstypes.QueueAttributeNameFifoQueue)] == "true",
+		"visibility_timeout":             getIntAttribute(attributes, string(sqstypes.QueueAttributeNameVisibilityTimeout)),
+		"message_retention_period":       getIntAttribute(attributes, string(sqstypes.QueueAttributeNameMessageRetentionPeriod)),
+		"approximate_number_of_messages": getIntAttribute(attributes, string(sqstypes.QueueAttributeNameApproximateNumberOfMessages)),
+		"sse_enabled": attributes[string(sqstypes.QueueAttributeNameSqsManagedSseEnabled)] == "true" ||
+			attributes[string(sqstypes.QueueAttributeNameKmsMasterKeyId)] != "",
+	}
+	if createdTimestamp, err := strconv.ParseInt(attributes[string(sqstypes.QueueAttributeNameCreatedTimestamp)], 10, 64); err == nil {
+		metadata["creation_date"] = time.Unix(createdTimestamp, 0).UTC()
+	}
+	if kmsKeyID := attributes[string(sqstypes.QueueAttributeNameKmsMasterKeyId)]; kmsKeyID != "" {
+		metadata["kms_master_key_id"] = kmsKeyID
+	}
+	var redrivePolicy struct {
+		DeadLetterTargetArn string `json:"deadLetterTargetArn"`
+	}
+	if policy := attributes[string(sqstypes.QueueAttributeNameRedrivePolicy)]; policy != "" && json.Unmarshal([]byte(policy), &redrivePolicy) == nil {
+		metadata["dead_letter_target_arn"] = redrivePolicy.DeadLetterTargetArn
+	}
+	return metadata
+}
+
+// getIntAttribute returns the integer value of an attribute, as returned by SQS and SNS, or 0 when it is not set.
+func getIntAttribute(attributes map[string]string, name string) int {
+	value, err := strconv.Atoi(attributes[name])
+	if err != nil {
+		return 0
+	}
+	return value
+}
+
+func listSQSQueues(ctx context.Context, client sqs.ListQueuesAPIClient) ([]string, error) {
+	queueURLs := make([]string, 0, 100)
+	// ListQueues only returns a NextToken when MaxResults is set, otherwise the queues after the first 1000 are missed
+	paginator := sqs.NewListQueuesPaginator(client, &sqs.ListQueuesInput{MaxResults: aws.Int32(1000)})
+	for paginator.HasMorePages() {
+		resp, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error listing SQS queues: %w", err)
+		}
+
+		queueURLs = append(queueURLs, resp.QueueUrls...)
+	}
+
+	return queueURLs, nil
+}
@@ -0,0 +1,159 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package aws
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/elastic/assetbeat/input/internal"
+	"github.com/elastic/assetbeat/input/testutil"
+	"github.com/elastic/beats/v7/libbeat/beat"
+	"github.com/elastic/elastic-agent-libs/logp"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+var sqsQueueURL_1 = "https://sqs.eu-west-1.amazonaws.com/11111111111111/jobs"
+var sqsQueueARN_1 = "arn:aws:sqs:eu-west-1:11111111111111:jobs"
+
+type mockSQSQueuesClient struct {
+	attributes      map[string]map[string]string
+	attributeErrors map[string]error
+	tags            map[string]map[string]string
+}
+
+func (m mockSQSQueuesClient) ListQueues(ctx context.Context, params *sqs.ListQueuesInput, optFns ...func(*sqs.Options)) (*sqs.ListQueuesOutput, error) {
+	var urls []string
+	for url := range m.attributeErrors {
+		urls = append(urls, url)
+	}
+	for url := range m.attributes {
+		urls = append(urls, url)
+	}
+	return &sqs.ListQueuesOutput{QueueUrls: urls}, nil
+}
+
+func (m mockSQSQueuesClient) GetQueueAttributes(ctx context.Context, params *sqs.GetQueueAttributesInput, optFns ...func(*sqs.Options)) (*sqs.GetQueueAttributesOutput, error) {
+	if err, ok := m.attributeErrors[*params.QueueUrl]; ok {
+		return nil, err
+	}
+	return &sqs.GetQueueAttributesOutput{Attributes: m.attributes[*params.QueueUrl]}, nil
+}
+
+func (m mockSQSQueuesClient) ListQueueTags(ctx context.Context, params *sqs.ListQueueTagsInput, optFns ...func(*sqs.Options)) (*sqs.ListQueueTagsOutput, error) {
+	return &sqs.ListQueueTagsOutput{Tags: m.tags[*params.QueueUrl]}, nil
+}
+
+type mockListQueuesAPI func(ctx context.Context, params *sqs.ListQueuesInput, optFns ...func(*sqs.Options)) (*sqs.ListQueuesOutput, error)
+
+func (m mockListQueuesAPI) ListQueues(ctx context.Context, params *sqs.ListQueuesInput, optFns ...func(*sqs.Options)) (*sqs.ListQueuesOutput, error) {
+	return m(ctx, params, optFns...)
+}
+
+func TestListSQSQueues(t *testing.T) {
+	sqsQueueURL_2 := "https://sqs.eu-west-1.amazonaws.com/11111111111111/reports"
+	client := mockListQueuesAPI(func(ctx context.Context, params *sqs.ListQueuesInput, optFns ...func(*sqs.Options)) (*sqs.ListQueuesOutput, error) {
+		// without MaxResults, ListQueues returns the first 1000 queues and no NextToken
+		if params.MaxResults == nil {
+			return &sqs.ListQueuesOutput{QueueUrls: []string{sqsQueueURL_1}}, nil
+		}
+		if params.NextToken == nil {
+			return &sqs.ListQueuesOutput{QueueUrls: []string{sqsQueueURL_1}, NextToken: aws.String("page-2")}, nil
+		}
+		assert.Equal(t, "page-2", *params.NextToken)
+		return &sqs.ListQueuesOutput{QueueUrls: []string{sqsQueueURL_2}}, nil
+	})
+
+	queueURLs, err := listSQSQueues(context.Background(), client)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{sqsQueueURL_1, sqsQueueURL_2}, queueURLs)
+}
+
+func TestAssetsAWS_collectSQSAssets(t *testing.T) {
+	client := mockSQSQueuesClient{
+		attributes: map[string]map[string]string{
+			sqsQueueURL_1: {
+				"QueueArn":                    sqsQueueARN_1,
+				"VisibilityTimeout":           "30",
+				"MessageRetentionPeriod":      "345600",
+				"ApproximateNumberOfMessages": "7",
+				"CreatedTimestamp":            "1693562400",
+				"SqsManagedSseEnabled":        "true",
+				"RedrivePolicy":               `{"deadLetterTargetArn":"arn:aws:sqs:eu-west-1:11111111111111:jobs-dlq","maxReceiveCount":5}`,
+			},
+		},
+		tags: map[string]map[string]string{
+			sqsQueueURL_1: {tag_1_k: tag_1_v},
+		},
+	}
+
+	expectedEvents := []beat.Event{
+		{
+			Fields: mapstr.M{
+				"asset.ean":                         "queue:" + sqsQueueARN_1,
+				"asset.id":                          sqsQueueARN_1,
+				"asset.name":                        "jobs",
+				"asset.type":                        "aws.sqs.queue",
+				"asset.kind":                        "queue",
+				"asset.metadata.url":                sqsQueueURL_1,
+				"asset.metadata.fifo":               false,
+				"asset.metadata.visibility_timeout": 30,
+				"asset.metadata.message_retention_period":       345600,
+				"asset.metadata.approximate_number_of_messages": 7,
+				"asset.metadata.sse_enabled":                    true,
+				"asset.metadata.creation_date":                  time.Date(2023, 9, 1, 10, 0, 0, 0, time.UTC),
+				"asset.metadata.dead_letter_target_arn":         "arn:aws:sqs:eu-west-1:11111111111111:jobs-dlq",
+				"asset.metadata.tags." + tag_1_k:                tag_1_v,
+				"cloud.account.id":                              ownerID_1,
+				"cloud.provider":                                "aws",
+				"cloud.region":                                  "eu-west-1",
+			},
+			Meta: mapstr.M{
+				"index": internal.GetDefaultIndexName(),
+			},
+		},
+	}
+
+	publisher := testutil.NewInMemoryPublisher()
+	err := collectSQSAssets(context.Background(), client, "eu-west-1", logp.NewLogger("test"), publisher)
+	assert.NoError(t, err)
+	assert.Equal(t, expectedEvents, publisher.Events)
+}
+
+func TestAssetsAWS_collectSQSAssets_attributesError(t *testing.T) {
+	client := mockSQSQueuesClient{
+		attributes: map[string]map[string]string{
+			sqsQueueURL_1: {"QueueArn": sqsQueueARN_1},
+		},
+		attributeErrors: map[string]error{
+			"https://sqs.eu-west-1.amazonaws.com/11111111111111/deleted": errors.New("AWS.SimpleQueueService.NonExistentQueue"),
+		},
+	}
+
+	publisher := testutil.NewInMemoryPublisher()
+	err := collectSQSAssets(context.Background(), client, "eu-west-1", logp.NewLogger("test"), publisher)
+	assert.NoError(t, err)
+	assert.Len(t, publisher.Events, 1)
+	assert.Equal(t, sqsQueueARN_1, publisher.Events[0].Fields["asset.id"])
+}
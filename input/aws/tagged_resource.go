@@ -0,0 +1,129 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package aws
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/elastic/assetbeat/input/internal"
+	stateless "github.com/elastic/beats/v7/filebeat/input/v2/input-stateless"
+
+	"github.com/elastic/elastic-agent-libs/logp"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	"github.com/aws/aws-sdk-go-v2/service/resourcegroupstaggingapi"
+	"github.com/aws/aws-sdk-go-v2/service/resourcegroupstaggingapi/types"
+)
+
+// globalResourcesRegion is the region the Resource Groups Tagging API returns the global resources from.
+const globalResourcesRegion = "us-east-1"
+
+// collectTaggedResourceAssets publishes the resources of a region returned by the Resource Groups Tagging API,
+// optionally restricted to the given resource types (e.g. "dynamodb:table" or "sqs").
+// Global resources (e.g. IAM roles or CloudFront distributions) have no region in their ARN and are only returned
+// from globalResourcesRegion: they are published instead of the regional resources when global is set.
+// S3 buckets have no region in their ARN either, but they are returned from their own region, and published with it.
+func collectTaggedResourceAssets(ctx context.Context, client resourcegroupstaggingapi.GetResourcesAPIClient, region string, resourceTypes []string, global bool, log *logp.Logger, publisher stateless.Publisher) error {
+	resources, err := getTaggedResources(ctx, client, resourceTypes)
+	if err != nil {
+		return err
+	}
+
+	assetKind := "resource"
+	for _, resource := range resources {
+		resourceARN, err := arn.Parse(*resource.ResourceARN)
+		if err != nil {
+			log.Warnf("skipping tagged resource with invalid ARN %s: %v", *resource.ResourceARN, err)
+			continue
+		}
+		resourceRegion := resourceARN.Region
+		if resourceRegion == "" && resourceARN.Service == "s3" {
+			resourceRegion = region
+		}
+		if (resourceRegion == "") != global {
+			continue
+		}
+		resourceType, resourceName := parseARNResource(resourceARN.Resource)
+		// the tagged resources get their own type namespace, so that they don't clash
+		// with the asset types of the dedicated collectors
+		assetType := "aws.tagged_resource." + resourceARN.Service
+		if resourceType != "" {
+			assetType += "." + resourceType
+		}
+		options := []internal.AssetOption{
+			internal.WithAssetCloudProvider("aws"),
+			internal.WithAssetKindAndID(assetKind, *resource.ResourceARN),
+			internal.WithAssetType(assetType),
+			WithAssetTags(flattenTaggingAPITags(resource.Tags)),
+		}
+		// S3 bucket ARNs have no account either
+		if resourceARN.AccountID != "" {
+			options = append(options, internal.WithAssetAccountID(resourceARN.AccountID))
+		}
+		if resourceRegion != "" {
+			options = append(options, internal.WithAssetRegion(resourceRegion))
+		}
+		if resourceName != "" {
+			options = append(options, internal.WithAssetName(resourceName))
+		}
+		internal.Publish(publisher, nil,
+			options...,
+		)
+	}
+
+	return nil
+}
+
+// parseARNResource splits the resource part of an ARN into its resource type and name.
+// ARNs use either "type/name", "type:name" or just "name" as resource part.
+func parseARNResource(resource string) (string, string) {
+	i := strings.IndexAny(resource, "/:")
+	if i < 0 {
+		return "", resource
+	}
+	return strings.ToLower(resource[:i]), resource[i+1:]
+}
+
+func getTaggedResources(ctx context.Context, client resourcegroupstaggingapi.GetResourcesAPIClient, resourceTypes []string) ([]types.ResourceTagMapping, error) {
+	resources := make([]types.ResourceTagMapping, 0, 100)
+	paginator := resourcegroupstaggingapi.NewGetResourcesPaginator(client, &resourcegroupstaggingapi.GetResourcesInput{
+		ResourceTypeFilters: resourceTypes,
+	})
+	for paginator.HasMorePages() {
+		resp, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error getting tagged resources: %w", err)
+		}
+
+		resources = append(resources, resp.ResourceTagMappingList...)
+	}
+
+	return resources, nil
+}
+
+// flattenTaggingAPITags converts the Resource Groups Tagging API tag format to a simple `map[string]string`
+func flattenTaggingAPITags(tags []types.Tag) mapstr.M {
+	out := mapstr.M{}
+	for _, t := range tags {
+		out[*t.Key] = *t.Value
+	}
+	return out
+}
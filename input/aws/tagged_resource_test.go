@@ -0,0 +1,195 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package aws
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/resourcegroupstaggingapi"
+	"github.com/aws/aws-sdk-go-v2/service/resourcegroupstaggingapi/types"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/elastic/assetbeat/input/internal"
+	"github.com/elastic/assetbeat/input/testutil"
+	"github.com/elastic/beats/v7/libbeat/beat"
+	"github.com/elastic/elastic-agent-libs/logp"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+var dynamoDBTableARN = "arn:aws:dynamodb:eu-west-1:11111111111111:table/orders"
+var sqsQueueARN = "arn:aws:sqs:eu-west-1:11111111111111:jobs"
+var cloudFrontDistributionARN = "arn:aws:cloudfront::11111111111111:distribution/E1111111"
+var s3BucketARN = "arn:aws:s3:::my-bucket"
+
+type mockGetResourcesAPI func(ctx context.Context, params *resourcegroupstaggingapi.GetResourcesInput, optFns ...func(*resourcegroupstaggingapi.Options)) (*resourcegroupstaggingapi.GetResourcesOutput, error)
+
+func (m mockGetResourcesAPI) GetResources(ctx context.Context, params *resourcegroupstaggingapi.GetResourcesInput, optFns ...func(*resourcegroupstaggingapi.Options)) (*resourcegroupstaggingapi.GetResourcesOutput, error) {
+	return m(ctx, params, optFns...)
+}
+
+func TestAssetsAWS_collectTaggedResourceAssets(t *testing.T) {
+	resources := []types.ResourceTagMapping{
+		{
+			ResourceARN: &dynamoDBTableARN,
+			Tags: []types.Tag{
+				{
+					Key:   &tag_1_k,
+					Value: &tag_1_v,
+				},
+			},
+		},
+		{
+			ResourceARN: &sqsQueueARN,
+		},
+		{
+			ResourceARN: &cloudFrontDistributionARN,
+		},
+		{
+			ResourceARN: &s3BucketARN,
+		},
+		{
+			ResourceARN: aws.String("not-an-arn"),
+		},
+	}
+
+	client := mockGetResourcesAPI(func(ctx context.Context, params *resourcegroupstaggingapi.GetResourcesInput, optFns ...func(*resourcegroupstaggingapi.Options)) (*resourcegroupstaggingapi.GetResourcesOutput, error) {
+		assert.Equal(t, []string{"dynamodb:table", "sqs", "cloudfront", "s3"}, params.ResourceTypeFilters)
+		return &resourcegroupstaggingapi.GetResourcesOutput{
+			ResourceTagMappingList: resources,
+		}, nil
+	})
+
+	expectedEvents := []beat.Event{
+		{
+			Fields: mapstr.M{
+				"asset.ean":                      "resource:" + dynamoDBTableARN,
+				"asset.id":                       dynamoDBTableARN,
+				"asset.name":                     "orders",
+				"asset.type":                     "aws.tagged_resource.dynamodb.table",
+				"asset.kind":                     "resource",
+				"asset.metadata.tags." + tag_1_k: tag_1_v,
+				"cloud.account.id":               ownerID_1,
+				"cloud.provider":                 "aws",
+				"cloud.region":                   "eu-west-1",
+			},
+			Meta: mapstr.M{
+				"index": internal.GetDefaultIndexName(),
+			},
+		},
+		{
+			Fields: mapstr.M{
+				"asset.ean":        "resource:" + sqsQueueARN,
+				"asset.id":         sqsQueueARN,
+				"asset.name":       "jobs",
+				"asset.type":       "aws.tagged_resource.sqs",
+				"asset.kind":       "resource",
+				"cloud.account.id": ownerID_1,
+				"cloud.provider":   "aws",
+				"cloud.region":     "eu-west-1",
+			},
+			Meta: mapstr.M{
+				"index": internal.GetDefaultIndexName(),
+			},
+		},
+		{
+			Fields: mapstr.M{
+				"asset.ean":      "resource:" + s3BucketARN,
+				"asset.id":       s3BucketARN,
+				"asset.name":     "my-bucket",
+				"asset.type":     "aws.tagged_resource.s3",
+				"asset.kind":     "resource",
+				"cloud.provider": "aws",
+				"cloud.region":   "eu-west-1",
+			},
+			Meta: mapstr.M{
+				"index": internal.GetDefaultIndexName(),
+			},
+		},
+	}
+	expectedGlobalEvents := []beat.Event{
+		{
+			Fields: mapstr.M{
+				"asset.ean":        "resource:" + cloudFrontDistributionARN,
+				"asset.id":         cloudFrontDistributionARN,
+				"asset.name":       "E1111111",
+				"asset.type":       "aws.tagged_resource.cloudfront.distribution",
+				"asset.kind":       "resource",
+				"cloud.account.id": ownerID_1,
+				"cloud.provider":   "aws",
+			},
+			Meta: mapstr.M{
+				"index": internal.GetDefaultIndexName(),
+			},
+		},
+	}
+
+	resourceTypes := []string{"dynamodb:table", "sqs", "cloudfront", "s3"}
+	publisher := testutil.NewInMemoryPublisher()
+	err := collectTaggedResourceAssets(context.Background(), client, "eu-west-1", resourceTypes, false, logp.NewLogger("test"), publisher)
+	assert.NoError(t, err)
+	assert.Equal(t, expectedEvents, publisher.Events)
+
+	// global resources are only published once, from us-east-1
+	publisher = testutil.NewInMemoryPublisher()
+	err = collectTaggedResourceAssets(context.Background(), client, globalResourcesRegion, resourceTypes, true, logp.NewLogger("test"), publisher)
+	assert.NoError(t, err)
+	assert.Equal(t, expectedGlobalEvents, publisher.Events)
+}
+
+func TestAssetsAWS_collectTaggedResourceAssets_bucketInOtherRegion(t *testing.T) {
+	// buckets are only returned from their own region, which isn't necessarily the first configured one
+	client := mockGetResourcesAPI(func(ctx context.Context, params *resourcegroupstaggingapi.GetResourcesInput, optFns ...func(*resourcegroupstaggingapi.Options)) (*resourcegroupstaggingapi.GetResourcesOutput, error) {
+		return &resourcegroupstaggingapi.GetResourcesOutput{
+			ResourceTagMappingList: []types.ResourceTagMapping{{ResourceARN: &s3BucketARN}},
+		}, nil
+	})
+
+	publisher := testutil.NewInMemoryPublisher()
+	err := collectTaggedResourceAssets(context.Background(), client, "eu-central-1", nil, false, logp.NewLogger("test"), publisher)
+	assert.NoError(t, err)
+	assert.Len(t, publisher.Events, 1)
+	assert.Equal(t, "resource:"+s3BucketARN, publisher.Events[0].Fields["asset.ean"])
+	assert.Equal(t, "eu-central-1", publisher.Events[0].Fields["cloud.region"])
+
+	// nor from us-east-1 with the global resources
+	publisher = testutil.NewInMemoryPublisher()
+	err = collectTaggedResourceAssets(context.Background(), client, globalResourcesRegion, nil, true, logp.NewLogger("test"), publisher)
+	assert.NoError(t, err)
+	assert.Empty(t, publisher.Events)
+}
+
+func TestParseARNResource(t *testing.T) {
+	for _, tt := range []struct {
+		resource     string
+		expectedType string
+		expectedName string
+	}{
+		{resource: "table/orders", expectedType: "table", expectedName: "orders"},
+		{resource: "function:my-function", expectedType: "function", expectedName: "my-function"},
+		{resource: "loadbalancer/app/my-lb/1111", expectedType: "loadbalancer", expectedName: "app/my-lb/1111"},
+		{resource: "my-bucket", expectedType: "", expectedName: "my-bucket"},
+	} {
+		t.Run(tt.resource, func(t *testing.T) {
+			resourceType, resourceName := parseARNResource(tt.resource)
+			assert.Equal(t, tt.expectedType, resourceType)
+			assert.Equal(t, tt.expectedName, resourceName)
+		})
+	}
+}
@@ -0,0 +1,106 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package aws
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/elasticloadbalancingv2"
+)
+
+// awsTargetIndex resolves the DNS names and IP addresses global resources point at
+// to the EANs of the regional assets they belong to.
+type awsTargetIndex struct {
+	loadBalancers map[string]string
+	elasticIPs    map[string]string
+}
+
+func newAWSTargetIndex() *awsTargetIndex {
+	return &awsTargetIndex{
+		loadBalancers: map[string]string{},
+		elasticIPs:    map[string]string{},
+	}
+}
+
+// indexLoadBalancers adds the load balancers of a region to the index, by DNS name.
+// Load balancers have no dedicated collector, so they are referenced the way tagged resources are.
+func (i *awsTargetIndex) indexLoadBalancers(ctx context.Context, client elasticloadbalancingv2.DescribeLoadBalancersAPIClient) error {
+	paginator := elasticloadbalancingv2.NewDescribeLoadBalancersPaginator(client, &elasticloadbalancingv2.DescribeLoadBalancersInput{})
+	for paginator.HasMorePages() {
+		resp, err := paginator.NextPage(ctx)
+		if err != nil {
+			return fmt.Errorf("error describing load balancers: %w", err)
+		}
+		for _, lb := range resp.LoadBalancers {
+			if lb.DNSName == nil || lb.LoadBalancerArn == nil {
+				continue
+			}
+			i.loadBalancers[normalizeDNSName(*lb.DNSName)] = "resource:" + *lb.LoadBalancerArn
+		}
+	}
+	return nil
+}
+
+// indexElasticIPs adds the Elastic IP addresses of a region to the index, by public IP.
+func (i *awsTargetIndex) indexElasticIPs(ctx context.Context, client describeAddressesAPIClient) error {
+	resp, err := client.DescribeAddresses(ctx, &ec2.DescribeAddressesInput{})
+	if err != nil {
+		return fmt.Errorf("error describing Elastic IP addresses: %w", err)
+	}
+	for _, address := range resp.Addresses {
+		if address.PublicIp == nil {
+			continue
+		}
+		// same identifier as the one collectElasticIPAssets publishes
+		id := aws.ToString(address.AllocationId)
+		if id == "" {
+			id = *address.PublicIp
+		}
+		i.elasticIPs[*address.PublicIp] = "network:" + id
+	}
+	return nil
+}
+
+// lookupLoadBalancer returns the EAN of the load balancer with the given DNS name, if indexed.
+func (i *awsTargetIndex) lookupLoadBalancer(dnsName string) (string, bool) {
+	if i == nil {
+		return "", false
+	}
+	ean, ok := i.loadBalancers[normalizeDNSName(dnsName)]
+	return ean, ok
+}
+
+// lookupElasticIP returns the EAN of the Elastic IP with the given address, if indexed.
+func (i *awsTargetIndex) lookupElasticIP(ip string) (string, bool) {
+	if i == nil {
+		return "", false
+	}
+	ean, ok := i.elasticIPs[ip]
+	return ean, ok
+}
+
+// normalizeDNSName makes DNS names comparable: Route 53 returns fully qualified names,
+// and alias targets of load balancers carry a `dualstack.` prefix.
+func normalizeDNSName(name string) string {
+	name = strings.TrimSuffix(strings.ToLower(name), ".")
+	return strings.TrimPrefix(name, "dualstack.")
+}
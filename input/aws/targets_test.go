@@ -0,0 +1,78 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package aws
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/aws-sdk-go-v2/service/elasticloadbalancingv2"
+	"github.com/aws/aws-sdk-go-v2/service/elasticloadbalancingv2/types"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockDescribeLoadBalancersAPI func(ctx context.Context, params *elasticloadbalancingv2.DescribeLoadBalancersInput, optFns ...func(*elasticloadbalancingv2.Options)) (*elasticloadbalancingv2.DescribeLoadBalancersOutput, error)
+
+func (m mockDescribeLoadBalancersAPI) DescribeLoadBalancers(ctx context.Context, params *elasticloadbalancingv2.DescribeLoadBalancersInput, optFns ...func(*elasticloadbalancingv2.Options)) (*elasticloadbalancingv2.DescribeLoadBalancersOutput, error) {
+	return m(ctx, params, optFns...)
+}
+
+func TestAWSTargetIndex(t *testing.T) {
+	targets := newAWSTargetIndex()
+
+	err := targets.indexLoadBalancers(context.Background(), mockDescribeLoadBalancersAPI(func(ctx context.Context, params *elasticloadbalancingv2.DescribeLoadBalancersInput, optFns ...func(*elasticloadbalancingv2.Options)) (*elasticloadbalancingv2.DescribeLoadBalancersOutput, error) {
+		return &elasticloadbalancingv2.DescribeLoadBalancersOutput{
+			LoadBalancers: []types.LoadBalancer{
+				{LoadBalancerArn: &loadBalancerARN_1, DNSName: aws.String("My-LB-1111.eu-west-1.elb.amazonaws.com")},
+			},
+		}, nil
+	}))
+	assert.NoError(t, err)
+
+	err = targets.indexElasticIPs(context.Background(), mockDescribeAddressesAPI(func(ctx context.Context, params *ec2.DescribeAddressesInput, optFns ...func(*ec2.Options)) (*ec2.DescribeAddressesOutput, error) {
+		return &ec2.DescribeAddressesOutput{
+			Addresses: []ec2types.Address{
+				{AllocationId: &allocationID1, PublicIp: aws.String("198.51.100.1")},
+				{PublicIp: aws.String("198.51.100.2")},
+			},
+		}, nil
+	}))
+	assert.NoError(t, err)
+
+	ean, ok := targets.lookupLoadBalancer("dualstack." + loadBalancerDNSName + ".")
+	assert.True(t, ok)
+	assert.Equal(t, "resource:"+loadBalancerARN_1, ean)
+
+	ean, ok = targets.lookupElasticIP("198.51.100.1")
+	assert.True(t, ok)
+	assert.Equal(t, "network:"+allocationID1, ean)
+
+	ean, ok = targets.lookupElasticIP("198.51.100.2")
+	assert.True(t, ok)
+	assert.Equal(t, "network:198.51.100.2", ean)
+
+	_, ok = targets.lookupElasticIP("203.0.113.1")
+	assert.False(t, ok)
+
+	var noTargets *awsTargetIndex
+	_, ok = noTargets.lookupLoadBalancer(loadBalancerDNSName)
+	assert.False(t, ok)
+}
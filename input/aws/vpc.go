@@ -37,56 +37,64 @@ func collectVPCAssets(ctx context.Context, client ec2.DescribeVpcsAPIClient, reg
 		return err
 	}
 
-	assetType := "aws.vpc"
-	assetKind := "network"
 	for _, vpc := range vpcs {
-		internal.Publish(publisher, nil,
-			internal.WithAssetCloudProvider("aws"),
-			internal.WithAssetRegion(region),
-			internal.WithAssetAccountID(*vpc.OwnerId),
-			internal.WithAssetKindAndID(assetKind, *vpc.VpcId),
-			internal.WithAssetType(assetType),
-			WithAssetTags(flattenEC2Tags(vpc.Tags)),
-			internal.WithAssetMetadata(mapstr.M{
-				"isDefault": vpc.IsDefault,
-			}),
-		)
+		publishVPCAsset(publisher, region, vpc)
 	}
 
 	return nil
 }
 
+func publishVPCAsset(publisher stateless.Publisher, region string, vpc types.Vpc) {
+	assetType := "aws.vpc"
+	assetKind := "network"
+	internal.Publish(publisher, nil,
+		internal.WithAssetCloudProvider("aws"),
+		internal.WithAssetRegion(region),
+		internal.WithAssetAccountID(*vpc.OwnerId),
+		internal.WithAssetKindAndID(assetKind, *vpc.VpcId),
+		internal.WithAssetType(assetType),
+		WithAssetTags(flattenEC2Tags(vpc.Tags)),
+		internal.WithAssetMetadata(mapstr.M{
+			"isDefault": vpc.IsDefault,
+		}),
+	)
+}
+
 func collectSubnetAssets(ctx context.Context, client ec2.DescribeSubnetsAPIClient, region string, log *logp.Logger, publisher stateless.Publisher) error {
 	subnets, err := describeSubnets(ctx, client)
 	if err != nil {
 		return err
 	}
 
-	assetType := "aws.subnet"
-	assetKind := "network"
 	for _, subnet := range subnets {
-		var parents []string
-		if subnet.VpcId != nil {
-			parents = []string{"network:" + *subnet.VpcId}
-		}
-
-		internal.Publish(publisher, nil,
-			internal.WithAssetCloudProvider("aws"),
-			internal.WithAssetRegion(region),
-			internal.WithAssetAccountID(*subnet.OwnerId),
-			internal.WithAssetKindAndID(assetKind, *subnet.SubnetId),
-			internal.WithAssetType(assetType),
-			internal.WithAssetParents(parents),
-			WithAssetTags(flattenEC2Tags(subnet.Tags)),
-			internal.WithAssetMetadata(mapstr.M{
-				"state": string(subnet.State),
-			}),
-		)
+		publishSubnetAsset(publisher, region, subnet)
 	}
 
 	return nil
 }
 
+func publishSubnetAsset(publisher stateless.Publisher, region string, subnet types.Subnet) {
+	var parents []string
+	if subnet.VpcId != nil {
+		parents = []string{"network:" + *subnet.VpcId}
+	}
+
+	assetType := "aws.subnet"
+	assetKind := "network"
+	internal.Publish(publisher, nil,
+		internal.WithAssetCloudProvider("aws"),
+		internal.WithAssetRegion(region),
+		internal.WithAssetAccountID(*subnet.OwnerId),
+		internal.WithAssetKindAndID(assetKind, *subnet.SubnetId),
+		internal.WithAssetType(assetType),
+		internal.WithAssetParents(parents),
+		WithAssetTags(flattenEC2Tags(subnet.Tags)),
+		internal.WithAssetMetadata(mapstr.M{
+			"state": string(subnet.State),
+		}),
+	)
+}
+
 func describeVPCs(ctx context.Context, client ec2.DescribeVpcsAPIClient) ([]types.Vpc, error) {
 	vpcs := make([]types.Vpc, 0, 100)
 	paginator := ec2.NewDescribeVpcsPaginator(client, &ec2.DescribeVpcsInput{})
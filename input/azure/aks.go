@@ -0,0 +1,229 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package azure
+
+import (
+	"context"
+	"fmt"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v5"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerservice/armcontainerservice/v4"
+	"github.com/elastic/assetbeat/input/internal"
+	stateless "github.com/elastic/beats/v7/filebeat/input/v2/input-stateless"
+	"github.com/elastic/elastic-agent-libs/logp"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+	"strings"
+)
+
+// aksPoolNameTag is set by AKS on the VM scale sets of its node resource group, with the name of the node pool they back.
+const aksPoolNameTag = "aks-managed-poolName"
+
+// aksNodePoolScaleSet is the VM scale set backing an AKS node pool.
+type aksNodePoolScaleSet struct {
+	Name        string
+	InstanceIDs []string
+}
+
+// collectAzureAKSAssets publishes the AKS clusters of a subscription, with the VM scale set instances
+// backing their node pools as children.
+func collectAzureAKSAssets(ctx context.Context, clusterClient *armcontainerservice.ManagedClustersClient, scaleSetClient *armcompute.VirtualMachineScaleSetsClient, scaleSetVMClient *armcompute.VirtualMachineScaleSetVMsClient, subscriptionId string, regions []string, resourceGroups []string, log *logp.Logger, publisher stateless.Publisher) error {
+	clusters, err := getAllAzureAKSClusters(ctx, clusterClient, regions, resourceGroups)
+	if err != nil {
+		return err
+	}
+
+	log.Debug("Publishing Azure AKS clusters")
+
+	for _, cluster := range clusters {
+		var scaleSets map[string]aksNodePoolScaleSet
+		if cluster.Properties != nil && cluster.Properties.NodeResourceGroup != nil {
+			scaleSets, err = getAKSNodePoolScaleSets(ctx, scaleSetClient, scaleSetVMClient, *cluster.Properties.NodeResourceGroup)
+			if err != nil {
+				log.Warnf("Error while retrieving the VM scale sets of AKS cluster %s, it is published without its nodes: %v", *cluster.ID, err)
+			}
+		}
+		publishAzureAKSCluster(publisher, subscriptionId, cluster, scaleSets)
+	}
+
+	return nil
+}
+
+func getAllAzureAKSClusters(ctx context.Context, client *armcontainerservice.ManagedClustersClient, regions []string, resourceGroups []string) ([]*armcontainerservice.ManagedCluster, error) {
+	var clusters []*armcontainerservice.ManagedCluster
+	pager := client.NewListPager(nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to advance page: %v", err)
+		}
+		for _, c := range page.Value {
+			if c.ID != nil && c.Location != nil && wantRegion(*c.Location, regions) && wantResourceGroup(*c.ID, resourceGroups) {
+				clusters = append(clusters, c)
+			}
+		}
+	}
+	return clusters, nil
+}
+
+// getAKSNodePoolScaleSets lists the VM scale sets of the node resource group of an AKS cluster
+// and their instances, indexed by the name of the node pool they back.
+func getAKSNodePoolScaleSets(ctx context.Context, scaleSetClient *armcompute.VirtualMachineScaleSetsClient, scaleSetVMClient *armcompute.VirtualMachineScaleSetVMsClient, nodeResourceGroup string) (map[string]aksNodePoolScaleSet, error) {
+	scaleSets := map[string]aksNodePoolScaleSet{}
+	pager := scaleSetClient.NewListPager(nodeResourceGroup, nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to advance page: %v", err)
+		}
+		for _, vmss := range page.Value {
+			if vmss.Name == nil || vmss.Tags[aksPoolNameTag] == nil {
+				continue
+			}
+			instanceIDs, err := getAzureVMSSInstanceIDs(ctx, scaleSetVMClient, nodeResourceGroup, *vmss.Name)
+			if err != nil {
+				return nil, err
+			}
+			scaleSets[*vmss.Tags[aksPoolNameTag]] = aksNodePoolScaleSet{
+				Name:        *vmss.Name,
+				InstanceIDs: instanceIDs,
+			}
+		}
+	}
+	return scaleSets, nil
+}
+
+func getAzureVMSSInstanceIDs(ctx context.Context, client *armcompute.VirtualMachineScaleSetVMsClient, resourceGroup string, scaleSetName string) ([]string, error) {
+	var ids []string
+	pager := client.NewListPager(resourceGroup, scaleSetName, nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to advance page: %v", err)
+		}
+		for _, vm := range page.Value {
+			if vm.ID != nil {
+				ids = append(ids, getAzureVMSSInstanceID(*vm.ID))
+			}
+		}
+	}
+	return ids, nil
+}
+
+// getAzureVMSSInstanceID returns the ID of the asset of a VM scale set instance. Kubernetes nodes only know
+// the resource ID of the instance they run on, so that is used rather than its VM ID, lowercased
+// as the casing of resource IDs is not consistent across Azure APIs.
+func getAzureVMSSInstanceID(resourceID string) string {
+	return strings.ToLower(resourceID)
+}
+
+func publishAzureAKSCluster(publisher stateless.Publisher, subscriptionId string, cluster *armcontainerservice.ManagedCluster, scaleSets map[string]aksNodePoolScaleSet) {
+	assetType := "k8s.cluster"
+	assetKind := "cluster"
+
+	metadata := mapstr.M{
+		"resource_group": getResourceGroupFromId(*cluster.ID),
+	}
+	var children []string
+	var subnetIDs []string
+	seenSubnets := map[string]bool{}
+	if props := cluster.Properties; props != nil {
+		if props.CurrentKubernetesVersion != nil {
+			metadata["kubernetes_version"] = *props.CurrentKubernetesVersion
+		} else if props.KubernetesVersion != nil {
+			metadata["kubernetes_version"] = *props.KubernetesVersion
+		}
+		if props.ProvisioningState != nil {
+			metadata["provisioning_state"] = *props.ProvisioningState
+		}
+		if props.PowerState != nil && props.PowerState.Code != nil {
+			metadata["power_state"] = string(*props.PowerState.Code)
+		}
+		if props.NodeResourceGroup != nil {
+			metadata["node_resource_group"] = *props.NodeResourceGroup
+		}
+		if props.Fqdn != nil {
+			metadata["fqdn"] = *props.Fqdn
+		}
+		if props.NetworkProfile != nil && props.NetworkProfile.NetworkPlugin != nil {
+			metadata["network_plugin"] = string(*props.NetworkProfile.NetworkPlugin)
+		}
+
+		var nodePools []mapstr.M
+		for _, pool := range props.AgentPoolProfiles {
+			if pool == nil || pool.Name == nil {
+				continue
+			}
+			nodePool := getAKSNodePoolMetadata(pool)
+			if scaleSet, ok := scaleSets[*pool.Name]; ok {
+				nodePool["vmss"] = scaleSet.Name
+				for _, id := range scaleSet.InstanceIDs {
+					children = append(children, "host:"+id)
+				}
+			}
+			nodePools = append(nodePools, nodePool)
+			if pool.VnetSubnetID != nil && !seenSubnets[strings.ToLower(*pool.VnetSubnetID)] {
+				seenSubnets[strings.ToLower(*pool.VnetSubnetID)] = true
+				subnetIDs = append(subnetIDs, *pool.VnetSubnetID)
+			}
+		}
+		if len(nodePools) > 0 {
+			metadata["node_pools"] = nodePools
+		}
+	}
+
+	options := []internal.AssetOption{
+		internal.WithAssetCloudProvider("azure"),
+		internal.WithAssetRegion(*cluster.Location),
+		internal.WithAssetAccountID(subscriptionId),
+		internal.WithAssetKindAndID(assetKind, strings.ToLower(*cluster.ID)),
+		internal.WithAssetType(assetType),
+		internal.WithAssetName(stringValue(cluster.Name)),
+		WithAssetTags(flattenAzureTags(cluster.Tags)),
+		internal.WithAssetMetadata(metadata),
+	}
+	if parents := withAzureResourceGroupParent(*cluster.ID, getAzureNetworkParents(subnetIDs)); len(parents) > 0 {
+		options = append(options, internal.WithAssetParents(parents))
+	}
+	if len(children) > 0 {
+		options = append(options, internal.WithAssetChildren(children))
+	}
+	internal.Publish(publisher, nil, options...)
+}
+
+func getAKSNodePoolMetadata(pool *armcontainerservice.ManagedClusterAgentPoolProfile) mapstr.M {
+	nodePool := mapstr.M{
+		"name": *pool.Name,
+	}
+	if pool.Mode != nil {
+		nodePool["mode"] = string(*pool.Mode)
+	}
+	if pool.VMSize != nil {
+		nodePool["vm_size"] = *pool.VMSize
+	}
+	if pool.Count != nil {
+		nodePool["count"] = *pool.Count
+	}
+	if pool.OSType != nil {
+		nodePool["os_type"] = string(*pool.OSType)
+	}
+	if pool.CurrentOrchestratorVersion != nil {
+		nodePool["kubernetes_version"] = *pool.CurrentOrchestratorVersion
+	} else if pool.OrchestratorVersion != nil {
+		nodePool["kubernetes_version"] = *pool.OrchestratorVersion
+	}
+	return nodePool
+}
@@ -0,0 +1,210 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package azure
+
+import (
+	"context"
+	"fmt"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	azfake "github.com/Azure/azure-sdk-for-go/sdk/azcore/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v5"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerservice/armcontainerservice/v4"
+	"github.com/elastic/assetbeat/input/internal"
+	"github.com/elastic/assetbeat/input/testutil"
+	"github.com/elastic/beats/v7/libbeat/beat"
+	"github.com/elastic/elastic-agent-libs/logp"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+const aksNodeResourceGroup = "MC_TESTVM_aks1_westeurope"
+
+var aksClusterID1 = fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.ContainerService/managedClusters/aks1", subscriptionId, resourceGroup1)
+var aksClusterID2 = fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.ContainerService/managedClusters/aks2", subscriptionId, resourceGroup2)
+var aksScaleSetID = fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Compute/virtualMachineScaleSets/aks-nodepool1-12345678-vmss", subscriptionId, aksNodeResourceGroup)
+
+var aksCluster1 = armcontainerservice.ManagedCluster{
+	ID:       to.Ptr(aksClusterID1),
+	Name:     to.Ptr("aks1"),
+	Location: to.Ptr("westeurope"),
+	Tags:     map[string]*string{"env": to.Ptr("test")},
+	Properties: &armcontainerservice.ManagedClusterProperties{
+		KubernetesVersion:        to.Ptr("1.27"),
+		CurrentKubernetesVersion: to.Ptr("1.27.3"),
+		ProvisioningState:        to.Ptr("Succeeded"),
+		PowerState:               &armcontainerservice.PowerState{Code: to.Ptr(armcontainerservice.CodeRunning)},
+		NodeResourceGroup:        to.Ptr(aksNodeResourceGroup),
+		Fqdn:                     to.Ptr("aks1-dns.hcp.westeurope.azmk8s.io"),
+		NetworkProfile:           &armcontainerservice.NetworkProfile{NetworkPlugin: to.Ptr(armcontainerservice.NetworkPluginAzure)},
+		AgentPoolProfiles: []*armcontainerservice.ManagedClusterAgentPoolProfile{
+			{
+				Name:                       to.Ptr("nodepool1"),
+				Mode:                       to.Ptr(armcontainerservice.AgentPoolModeSystem),
+				VMSize:                     to.Ptr("Standard_DS2_v2"),
+				Count:                      to.Ptr[int32](2),
+				OSType:                     to.Ptr(armcontainerservice.OSTypeLinux),
+				OrchestratorVersion:        to.Ptr("1.27"),
+				CurrentOrchestratorVersion: to.Ptr("1.27.3"),
+				VnetSubnetID:               to.Ptr(subnetID1),
+			},
+		},
+	},
+}
+
+var aksCluster2 = armcontainerservice.ManagedCluster{
+	ID:       to.Ptr(aksClusterID2),
+	Name:     to.Ptr("aks2"),
+	Location: to.Ptr("northeurope"),
+	Properties: &armcontainerservice.ManagedClusterProperties{
+		KubernetesVersion: to.Ptr("1.26.6"),
+		ProvisioningState: to.Ptr("Creating"),
+	},
+}
+
+var expectedAKSCluster1Event = beat.Event{
+	Fields: mapstr.M{
+		"asset.ean":  "cluster:" + strings.ToLower(aksClusterID1),
+		"asset.id":   strings.ToLower(aksClusterID1),
+		"asset.name": "aks1",
+		"asset.type": "k8s.cluster",
+		"asset.kind": "cluster",
+		"asset.parents": []string{
+			subnetEAN1,
+			vnetEAN1,
+			resourceGroupEAN1,
+		},
+		"asset.children": []string{
+			"host:" + strings.ToLower(aksScaleSetID) + "/virtualmachines/0",
+			"host:" + strings.ToLower(aksScaleSetID) + "/virtualmachines/1",
+		},
+		"asset.metadata.resource_group":      resourceGroup1,
+		"asset.metadata.kubernetes_version":  "1.27.3",
+		"asset.metadata.provisioning_state":  "Succeeded",
+		"asset.metadata.power_state":         "Running",
+		"asset.metadata.node_resource_group": aksNodeResourceGroup,
+		"asset.metadata.fqdn":                "aks1-dns.hcp.westeurope.azmk8s.io",
+		"asset.metadata.network_plugin":      "azure",
+		"asset.metadata.node_pools": []mapstr.M{
+			{
+				"name":               "nodepool1",
+				"mode":               "System",
+				"vm_size":            "Standard_DS2_v2",
+				"count":              int32(2),
+				"os_type":            "Linux",
+				"kubernetes_version": "1.27.3",
+				"vmss":               "aks-nodepool1-12345678-vmss",
+			},
+		},
+		"asset.metadata.tags.env": "test",
+		"cloud.account.id":        subscriptionId,
+		"cloud.provider":          "azure",
+		"cloud.region":            "westeurope",
+	},
+	Meta: mapstr.M{
+		"index": internal.GetDefaultIndexName(),
+	},
+}
+
+var expectedAKSCluster2Event = beat.Event{
+	Fields: mapstr.M{
+		"asset.ean":                         "cluster:" + strings.ToLower(aksClusterID2),
+		"asset.id":                          strings.ToLower(aksClusterID2),
+		"asset.name":                        "aks2",
+		"asset.type":                        "k8s.cluster",
+		"asset.parents":                     []string{resourceGroupEAN2},
+		"asset.kind":                        "cluster",
+		"asset.metadata.resource_group":     resourceGroup2,
+		"asset.metadata.kubernetes_version": "1.26.6",
+		"asset.metadata.provisioning_state": "Creating",
+		"cloud.account.id":                  subscriptionId,
+		"cloud.provider":                    "azure",
+		"cloud.region":                      "northeurope",
+	},
+	Meta: mapstr.M{
+		"index": internal.GetDefaultIndexName(),
+	},
+}
+
+// aksTransport answers the requests listing the AKS clusters, the VM scale sets of the node resource group
+// of aks1 and the instances of its node pool scale set.
+var aksTransport = fakeTransport(func(req *http.Request) (*http.Response, error) {
+	path := strings.ToLower(req.URL.Path)
+	switch {
+	case strings.HasSuffix(path, "/providers/microsoft.containerservice/managedclusters"):
+		return newJSONResponse(req, map[string]any{"value": []any{aksCluster1, aksCluster2}})
+	case strings.HasSuffix(path, "/virtualmachinescalesets/aks-nodepool1-12345678-vmss/virtualmachines"):
+		return newJSONResponse(req, map[string]any{"value": []any{
+			armcompute.VirtualMachineScaleSetVM{ID: to.Ptr(aksScaleSetID + "/virtualMachines/0")},
+			armcompute.VirtualMachineScaleSetVM{ID: to.Ptr(aksScaleSetID + "/virtualMachines/1")},
+		}})
+	case strings.HasSuffix(path, "/resourcegroups/"+strings.ToLower(aksNodeResourceGroup)+"/providers/microsoft.compute/virtualmachinescalesets"):
+		return newJSONResponse(req, map[string]any{"value": []any{
+			armcompute.VirtualMachineScaleSet{
+				ID:   to.Ptr(aksScaleSetID),
+				Name: to.Ptr("aks-nodepool1-12345678-vmss"),
+				Tags: map[string]*string{aksPoolNameTag: to.Ptr("nodepool1")},
+			},
+		}})
+	default:
+		return &http.Response{StatusCode: http.StatusNotFound, Body: http.NoBody, Request: req}, nil
+	}
+})
+
+func TestAssetsAzure_collectAzureAKSAssets(t *testing.T) {
+	for _, tt := range []struct {
+		name           string
+		regions        []string
+		resourceGroups []string
+		expectedEvents []beat.Event
+	}{
+		{
+			name:           "all clusters",
+			expectedEvents: []beat.Event{expectedAKSCluster1Event, expectedAKSCluster2Event},
+		},
+		{
+			name:           "in a region",
+			regions:        []string{"westeurope"},
+			expectedEvents: []beat.Event{expectedAKSCluster1Event},
+		},
+		{
+			name:           "in a resource group",
+			resourceGroups: []string{resourceGroup2},
+			expectedEvents: []beat.Event{expectedAKSCluster2Event},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			publisher := testutil.NewInMemoryPublisher()
+			options := &arm.ClientOptions{ClientOptions: azcore.ClientOptions{Transport: aksTransport}}
+			clusterClient, err := armcontainerservice.NewManagedClustersClient(subscriptionId, azfake.NewTokenCredential(), options)
+			assert.NoError(t, err)
+			scaleSetClient, err := armcompute.NewVirtualMachineScaleSetsClient(subscriptionId, azfake.NewTokenCredential(), options)
+			assert.NoError(t, err)
+			scaleSetVMClient, err := armcompute.NewVirtualMachineScaleSetVMsClient(subscriptionId, azfake.NewTokenCredential(), options)
+			assert.NoError(t, err)
+
+			err = collectAzureAKSAssets(context.Background(), clusterClient, scaleSetClient, scaleSetVMClient, subscriptionId, tt.regions, tt.resourceGroups, logp.NewLogger("test"), publisher)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedEvents, publisher.Events)
+		})
+	}
+}
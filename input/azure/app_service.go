@@ -0,0 +1,231 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package azure
+
+import (
+	"context"
+	"fmt"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/appservice/armappservice/v2"
+	"github.com/elastic/assetbeat/input/internal"
+	stateless "github.com/elastic/beats/v7/filebeat/input/v2/input-stateless"
+	"github.com/elastic/elastic-agent-libs/logp"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+	"strings"
+)
+
+// collectAzureAppServiceAssets publishes the App Service plans of a subscription and the web apps,
+// including the Function Apps, they host.
+func collectAzureAppServiceAssets(ctx context.Context, plansClient *armappservice.PlansClient, webAppsClient *armappservice.WebAppsClient, subscriptionId string, regions []string, resourceGroups []string, assetTypes []string, log *logp.Logger, publisher stateless.Publisher) error {
+	plans, err := getAllAzureAppServicePlans(ctx, plansClient)
+	if err != nil {
+		return err
+	}
+	webApps, err := getAllAzureWebApps(ctx, webAppsClient)
+	if err != nil {
+		return err
+	}
+
+	// Azure resource IDs are case-insensitive, plans are indexed by lowercase ID to match the ones referenced by web apps
+	plansByID := make(map[string]*armappservice.Plan, len(plans))
+	planWebApps := map[string][]string{}
+	for _, plan := range plans {
+		plansByID[strings.ToLower(*plan.ID)] = plan
+	}
+	// plans only list the web apps that are published along with them
+	for _, webApp := range webApps {
+		if !wantRegion(*webApp.Location, regions) || !wantResourceGroup(*webApp.ID, resourceGroups) {
+			continue
+		}
+		if planID := getAzureWebAppPlanID(webApp); planID != "" {
+			planWebApps[strings.ToLower(planID)] = append(planWebApps[strings.ToLower(planID)], azureEAN("service", *webApp.ID))
+		}
+	}
+
+	log.Debug("Publishing Azure App Service plans and web apps")
+
+	if internal.IsTypeEnabled(assetTypes, "azure.app_service.plan") {
+		for _, plan := range plans {
+			if wantRegion(*plan.Location, regions) && wantResourceGroup(*plan.ID, resourceGroups) {
+				publishAzureAppServicePlan(publisher, subscriptionId, plan, planWebApps[strings.ToLower(*plan.ID)])
+			}
+		}
+	}
+	if internal.IsTypeEnabled(assetTypes, "azure.web_app") {
+		for _, webApp := range webApps {
+			if wantRegion(*webApp.Location, regions) && wantResourceGroup(*webApp.ID, resourceGroups) {
+				publishAzureWebApp(publisher, subscriptionId, webApp, plansByID[strings.ToLower(getAzureWebAppPlanID(webApp))])
+			}
+		}
+	}
+
+	return nil
+}
+
+func getAllAzureAppServicePlans(ctx context.Context, client *armappservice.PlansClient) ([]*armappservice.Plan, error) {
+	var plans []*armappservice.Plan
+	pager := client.NewListPager(nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to advance page: %v", err)
+		}
+		for _, v := range page.Value {
+			if v.ID != nil && v.Location != nil {
+				plans = append(plans, v)
+			}
+		}
+	}
+	return plans, nil
+}
+
+func getAllAzureWebApps(ctx context.Context, client *armappservice.WebAppsClient) ([]*armappservice.Site, error) {
+	var webApps []*armappservice.Site
+	pager := client.NewListPager(nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to advance page: %v", err)
+		}
+		for _, v := range page.Value {
+			if v.ID != nil && v.Location != nil {
+				webApps = append(webApps, v)
+			}
+		}
+	}
+	return webApps, nil
+}
+
+func getAzureWebAppPlanID(webApp *armappservice.Site) string {
+	if webApp.Properties == nil {
+		return ""
+	}
+	return stringValue(webApp.Properties.ServerFarmID)
+}
+
+func getAzureAppServiceSKUMetadata(sku *armappservice.SKUDescription) mapstr.M {
+	metadata := mapstr.M{}
+	if sku == nil {
+		return metadata
+	}
+	if sku.Name != nil {
+		metadata["name"] = *sku.Name
+	}
+	if sku.Tier != nil {
+		metadata["tier"] = *sku.Tier
+	}
+	if sku.Capacity != nil {
+		metadata["capacity"] = *sku.Capacity
+	}
+	return metadata
+}
+
+func publishAzureAppServicePlan(publisher stateless.Publisher, subscriptionId string, plan *armappservice.Plan, webApps []string) {
+	assetType := "azure.app_service.plan"
+	assetKind := "host_group"
+
+	metadata := mapstr.M{
+		"resource_group": getResourceGroupFromId(*plan.ID),
+	}
+	if plan.Kind != nil {
+		metadata["kind"] = *plan.Kind
+	}
+	if sku := getAzureAppServiceSKUMetadata(plan.SKU); len(sku) > 0 {
+		metadata["sku"] = sku
+	}
+	if props := plan.Properties; props != nil {
+		if props.Status != nil {
+			metadata["state"] = string(*props.Status)
+		}
+		if props.NumberOfSites != nil {
+			metadata["number_of_sites"] = *props.NumberOfSites
+		}
+		if props.ZoneRedundant != nil {
+			metadata["zone_redundant"] = *props.ZoneRedundant
+		}
+	}
+
+	options := []internal.AssetOption{
+		internal.WithAssetCloudProvider("azure"),
+		internal.WithAssetRegion(normalizeAzureLocation(*plan.Location)),
+		internal.WithAssetAccountID(subscriptionId),
+		internal.WithAssetKindAndID(assetKind, strings.ToLower(*plan.ID)),
+		internal.WithAssetType(assetType),
+		internal.WithAssetName(stringValue(plan.Name)),
+		WithAssetTags(flattenAzureTags(plan.Tags)),
+		internal.WithAssetMetadata(metadata),
+	}
+	if parents := withAzureResourceGroupParent(*plan.ID, nil); len(parents) > 0 {
+		options = append(options, internal.WithAssetParents(parents))
+	}
+	if len(webApps) > 0 {
+		options = append(options, internal.WithAssetChildren(webApps))
+	}
+	internal.Publish(publisher, nil, options...)
+}
+
+// publishAzureWebApp publishes a web app, with the SKU of its App Service plan when it is known.
+func publishAzureWebApp(publisher stateless.Publisher, subscriptionId string, webApp *armappservice.Site, plan *armappservice.Plan) {
+	assetType := "azure.web_app"
+	assetKind := "service"
+
+	metadata := mapstr.M{
+		"resource_group": getResourceGroupFromId(*webApp.ID),
+	}
+	if webApp.Kind != nil {
+		metadata["kind"] = *webApp.Kind
+	}
+	if plan != nil {
+		if sku := getAzureAppServiceSKUMetadata(plan.SKU); len(sku) > 0 {
+			metadata["sku"] = sku
+		}
+	}
+	var parents []string
+	if props := webApp.Properties; props != nil {
+		if props.State != nil {
+			metadata["state"] = *props.State
+		}
+		if props.DefaultHostName != nil {
+			metadata["default_host_name"] = *props.DefaultHostName
+		}
+		if props.HTTPSOnly != nil {
+			metadata["https_only"] = *props.HTTPSOnly
+		}
+		if props.ServerFarmID != nil {
+			parents = append(parents, azureEAN("host_group", *props.ServerFarmID))
+		}
+		// VNet integration routes the outbound traffic of the web app through a subnet
+		if props.VirtualNetworkSubnetID != nil && *props.VirtualNetworkSubnetID != "" {
+			parents = append(parents, getAzureNetworkParents([]string{*props.VirtualNetworkSubnetID})...)
+		}
+	}
+
+	options := []internal.AssetOption{
+		internal.WithAssetCloudProvider("azure"),
+		internal.WithAssetRegion(normalizeAzureLocation(*webApp.Location)),
+		internal.WithAssetAccountID(subscriptionId),
+		internal.WithAssetKindAndID(assetKind, strings.ToLower(*webApp.ID)),
+		internal.WithAssetType(assetType),
+		internal.WithAssetName(stringValue(webApp.Name)),
+		WithAssetTags(flattenAzureTags(webApp.Tags)),
+		internal.WithAssetMetadata(metadata),
+	}
+	if parents = withAzureResourceGroupParent(*webApp.ID, parents); len(parents) > 0 {
+		options = append(options, internal.WithAssetParents(parents))
+	}
+	internal.Publish(publisher, nil, options...)
+}
@@ -0,0 +1,232 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package azure
+
+import (
+	"context"
+	"fmt"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	azfake "github.com/Azure/azure-sdk-for-go/sdk/azcore/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/appservice/armappservice/v2"
+	"github.com/elastic/assetbeat/input/internal"
+	"github.com/elastic/assetbeat/input/testutil"
+	"github.com/elastic/beats/v7/libbeat/beat"
+	"github.com/elastic/elastic-agent-libs/logp"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+var appServicePlanID1 = fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Web/serverfarms/plan1", subscriptionId, resourceGroup1)
+var webAppID1 = fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Web/sites/webapp1", subscriptionId, resourceGroup1)
+var functionAppID1 = fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Web/sites/function1", subscriptionId, resourceGroup2)
+
+var appServicePlan1 = armappservice.Plan{
+	ID:       to.Ptr(appServicePlanID1),
+	Name:     to.Ptr("plan1"),
+	Location: to.Ptr("West Europe"),
+	Kind:     to.Ptr("linux"),
+	Tags:     map[string]*string{"env": to.Ptr("test")},
+	SKU:      &armappservice.SKUDescription{Name: to.Ptr("P1v3"), Tier: to.Ptr("PremiumV3"), Capacity: to.Ptr[int32](1)},
+	Properties: &armappservice.PlanProperties{
+		Status:        to.Ptr(armappservice.StatusOptionsReady),
+		NumberOfSites: to.Ptr[int32](2),
+		ZoneRedundant: to.Ptr(false),
+	},
+}
+
+var webApp1 = armappservice.Site{
+	ID:       to.Ptr(webAppID1),
+	Name:     to.Ptr("webapp1"),
+	Location: to.Ptr("West Europe"),
+	Kind:     to.Ptr("app,linux"),
+	Properties: &armappservice.SiteProperties{
+		State:           to.Ptr("Running"),
+		DefaultHostName: to.Ptr("webapp1.azurewebsites.net"),
+		HTTPSOnly:       to.Ptr(true),
+		// plan IDs referenced by web apps don't have the same casing as the ones of the plans
+		ServerFarmID:           to.Ptr(strings.Replace(appServicePlanID1, "serverfarms", "serverFarms", 1)),
+		VirtualNetworkSubnetID: to.Ptr(subnetID1),
+	},
+}
+
+var functionApp1 = armappservice.Site{
+	ID:       to.Ptr(functionAppID1),
+	Name:     to.Ptr("function1"),
+	Location: to.Ptr("West Europe"),
+	Kind:     to.Ptr("functionapp,linux"),
+	Tags:     map[string]*string{"team": to.Ptr("observability")},
+	Properties: &armappservice.SiteProperties{
+		State:        to.Ptr("Stopped"),
+		ServerFarmID: to.Ptr(appServicePlanID1),
+	},
+}
+
+var expectedAppServicePlan1Event = beat.Event{
+	Fields: mapstr.M{
+		"asset.ean":                      "host_group:" + strings.ToLower(appServicePlanID1),
+		"asset.id":                       strings.ToLower(appServicePlanID1),
+		"asset.name":                     "plan1",
+		"asset.type":                     "azure.app_service.plan",
+		"asset.parents":                  []string{resourceGroupEAN1},
+		"asset.kind":                     "host_group",
+		"asset.children":                 []string{"service:" + strings.ToLower(webAppID1), "service:" + strings.ToLower(functionAppID1)},
+		"asset.metadata.resource_group":  resourceGroup1,
+		"asset.metadata.kind":            "linux",
+		"asset.metadata.sku.name":        "P1v3",
+		"asset.metadata.sku.tier":        "PremiumV3",
+		"asset.metadata.sku.capacity":    int32(1),
+		"asset.metadata.state":           "Ready",
+		"asset.metadata.number_of_sites": int32(2),
+		"asset.metadata.zone_redundant":  false,
+		"asset.metadata.tags.env":        "test",
+		"cloud.account.id":               subscriptionId,
+		"cloud.provider":                 "azure",
+		"cloud.region":                   "westeurope",
+	},
+	Meta: mapstr.M{
+		"index": internal.GetDefaultIndexName(),
+	},
+}
+
+var expectedWebApp1Event = beat.Event{
+	Fields: mapstr.M{
+		"asset.ean":  "service:" + strings.ToLower(webAppID1),
+		"asset.id":   strings.ToLower(webAppID1),
+		"asset.name": "webapp1",
+		"asset.type": "azure.web_app",
+		"asset.kind": "service",
+		"asset.parents": []string{
+			"host_group:" + strings.ToLower(appServicePlanID1),
+			subnetEAN1,
+			vnetEAN1,
+			resourceGroupEAN1,
+		},
+		"asset.metadata.resource_group":    resourceGroup1,
+		"asset.metadata.kind":              "app,linux",
+		"asset.metadata.sku.name":          "P1v3",
+		"asset.metadata.sku.tier":          "PremiumV3",
+		"asset.metadata.sku.capacity":      int32(1),
+		"asset.metadata.state":             "Running",
+		"asset.metadata.default_host_name": "webapp1.azurewebsites.net",
+		"asset.metadata.https_only":        true,
+		"cloud.account.id":                 subscriptionId,
+		"cloud.provider":                   "azure",
+		"cloud.region":                     "westeurope",
+	},
+	Meta: mapstr.M{
+		"index": internal.GetDefaultIndexName(),
+	},
+}
+
+var expectedFunctionApp1Event = beat.Event{
+	Fields: mapstr.M{
+		"asset.ean":                     "service:" + strings.ToLower(functionAppID1),
+		"asset.id":                      strings.ToLower(functionAppID1),
+		"asset.name":                    "function1",
+		"asset.type":                    "azure.web_app",
+		"asset.kind":                    "service",
+		"asset.parents":                 []string{"host_group:" + strings.ToLower(appServicePlanID1), resourceGroupEAN2},
+		"asset.metadata.resource_group": resourceGroup2,
+		"asset.metadata.kind":           "functionapp,linux",
+		"asset.metadata.sku.name":       "P1v3",
+		"asset.metadata.sku.tier":       "PremiumV3",
+		"asset.metadata.sku.capacity":   int32(1),
+		"asset.metadata.state":          "Stopped",
+		"asset.metadata.tags.team":      "observability",
+		"cloud.account.id":              subscriptionId,
+		"cloud.provider":                "azure",
+		"cloud.region":                  "westeurope",
+	},
+	Meta: mapstr.M{
+		"index": internal.GetDefaultIndexName(),
+	},
+}
+
+// withAssetChildren returns a copy of an expected event with other children.
+func withAssetChildren(event beat.Event, children ...string) beat.Event {
+	event.Fields = event.Fields.Clone()
+	event.Fields["asset.children"] = children
+	return event
+}
+
+// appServiceTransport answers the requests listing the App Service plans and the web apps of the subscription.
+var appServiceTransport = fakeTransport(func(req *http.Request) (*http.Response, error) {
+	path := strings.ToLower(req.URL.Path)
+	switch {
+	case strings.HasSuffix(path, "/providers/microsoft.web/serverfarms"):
+		return newJSONResponse(req, map[string]any{"value": []any{appServicePlan1}})
+	case strings.HasSuffix(path, "/providers/microsoft.web/sites"):
+		return newJSONResponse(req, map[string]any{"value": []any{webApp1, functionApp1}})
+	default:
+		return &http.Response{StatusCode: http.StatusNotFound, Body: http.NoBody, Request: req}, nil
+	}
+})
+
+func TestAssetsAzure_collectAzureAppServiceAssets(t *testing.T) {
+	for _, tt := range []struct {
+		name           string
+		regions        []string
+		resourceGroups []string
+		assetTypes     []string
+		expectedEvents []beat.Event
+	}{
+		{
+			name:           "all asset types",
+			expectedEvents: []beat.Event{expectedAppServicePlan1Event, expectedWebApp1Event, expectedFunctionApp1Event},
+		},
+		{
+			name:           "web apps only, in a region",
+			regions:        []string{"westeurope"},
+			assetTypes:     []string{"azure.web_app"},
+			expectedEvents: []beat.Event{expectedWebApp1Event, expectedFunctionApp1Event},
+		},
+		{
+			name:    "in another region",
+			regions: []string{"northeurope"},
+		},
+		{
+			name:           "in a resource group",
+			resourceGroups: []string{resourceGroup2},
+			expectedEvents: []beat.Event{expectedFunctionApp1Event},
+		},
+		{
+			// the function app is in another resource group, so the plan doesn't list it
+			name:           "in the resource group of the plan",
+			resourceGroups: []string{resourceGroup1},
+			expectedEvents: []beat.Event{withAssetChildren(expectedAppServicePlan1Event, "service:"+strings.ToLower(webAppID1)), expectedWebApp1Event},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			publisher := testutil.NewInMemoryPublisher()
+			options := &arm.ClientOptions{ClientOptions: azcore.ClientOptions{Transport: appServiceTransport}}
+			plansClient, err := armappservice.NewPlansClient(subscriptionId, azfake.NewTokenCredential(), options)
+			assert.NoError(t, err)
+			webAppsClient, err := armappservice.NewWebAppsClient(subscriptionId, azfake.NewTokenCredential(), options)
+			assert.NoError(t, err)
+
+			err = collectAzureAppServiceAssets(context.Background(), plansClient, webAppsClient, subscriptionId, tt.regions, tt.resourceGroups, tt.assetTypes, logp.NewLogger("test"), publisher)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedEvents, publisher.Events)
+		})
+	}
+}
@@ -0,0 +1,40 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package azure
+
+import (
+	"github.com/elastic/assetbeat/input/internal"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+func WithAssetTags(value mapstr.M) internal.AssetOption {
+	return internal.WithAssetMetadata(mapstr.M{
+		"tags": value,
+	})
+}
+
+// flattenAzureTags converts the Azure tag format to a simple `map[string]string`
+func flattenAzureTags(tags map[string]*string) mapstr.M {
+	out := mapstr.M{}
+	for k, v := range tags {
+		if v != nil {
+			out[k] = *v
+		}
+	}
+	return out
+}
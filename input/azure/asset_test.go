@@ -0,0 +1,71 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package azure
+
+import (
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/elastic/assetbeat/input/internal"
+	"github.com/elastic/assetbeat/input/testutil"
+	"github.com/elastic/beats/v7/libbeat/beat"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestWithAssetTags(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+
+		opts          []internal.AssetOption
+		expectedEvent beat.Event
+	}{
+		{
+			name: "with valid tags",
+			opts: []internal.AssetOption{
+				internal.WithAssetCloudProvider("azure"),
+				WithAssetTags(flattenAzureTags(map[string]*string{"tag1": to.Ptr("a"), "tag2": to.Ptr("b"), "tag3": nil})),
+			},
+			expectedEvent: beat.Event{Fields: mapstr.M{
+				"cloud.provider":           "azure",
+				"asset.metadata.tags.tag1": "a",
+				"asset.metadata.tags.tag2": "b",
+			}, Meta: mapstr.M{"index": internal.GetDefaultIndexName()}},
+		},
+		{
+			name: "with valid tags and metadata",
+			opts: []internal.AssetOption{
+				internal.WithAssetCloudProvider("azure"),
+				internal.WithAssetMetadata(mapstr.M{"foo": "bar"}),
+				WithAssetTags(mapstr.M{"tag1": "a"}),
+			},
+			expectedEvent: beat.Event{Fields: mapstr.M{
+				"cloud.provider":           "azure",
+				"asset.metadata.foo":       "bar",
+				"asset.metadata.tags.tag1": "a",
+			}, Meta: mapstr.M{"index": internal.GetDefaultIndexName()}},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			publisher := testutil.NewInMemoryPublisher()
+
+			internal.Publish(publisher, nil, tt.opts...)
+			assert.Equal(t, 1, len(publisher.Events))
+			assert.Equal(t, tt.expectedEvent, publisher.Events[0])
+		})
+	}
+}
@@ -19,17 +19,25 @@ package azure
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/appservice/armappservice/v2"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v5"
-	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/subscription/armsubscription"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerservice/armcontainerservice/v4"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v4"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resourcegraph/armresourcegraph"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/sql/armsql"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/storage/armstorage"
 	"github.com/elastic/assetbeat/input/internal"
 	input "github.com/elastic/beats/v7/filebeat/input/v2"
 	stateless "github.com/elastic/beats/v7/filebeat/input/v2/input-stateless"
 	"github.com/elastic/beats/v7/libbeat/feature"
 	conf "github.com/elastic/elastic-agent-libs/config"
 	"github.com/elastic/elastic-agent-libs/logp"
+	"github.com/elastic/elastic-agent-libs/match"
 	"github.com/elastic/go-concert/ctxtool"
 	"time"
 )
@@ -58,13 +66,86 @@ func newAssetsAzure(cfg config) (*assetsAzure, error) {
 }
 
 type config struct {
-	internal.BaseConfig `config:",inline"`
-	Regions             []string `config:"regions"`
-	ClientID            string   `config:"client_id"`
-	ClientSecret        string   `config:"client_secret"`
-	SubscriptionID      string   `config:"subscription_id"`
-	TenantID            string   `config:"tenant_id"`
-	ResourceGroup       string   `config:"resource_group"`
+	internal.BaseConfig       `config:",inline"`
+	Regions                   []string        `config:"regions"`
+	ClientID                  string          `config:"client_id"`
+	ClientSecret              string          `config:"client_secret"`
+	SubscriptionID            string          `config:"subscription_id"`
+	SubscriptionIDs           []string        `config:"subscription_ids"`
+	ManagementGroups          []string        `config:"management_groups"`
+	IncludeSubscriptions      []match.Matcher `config:"include_subscriptions"`
+	ExcludeSubscriptions      []match.Matcher `config:"exclude_subscriptions"`
+	TenantID                  string          `config:"tenant_id"`
+	ResourceGroup             string          `config:"resource_group"`
+	ResourceGroups            []string        `config:"resource_groups"`
+	Backend                   string          `config:"backend"`
+	ManagedIdentityClientID   string          `config:"managed_identity_client_id"`
+	WorkloadIdentity          bool            `config:"workload_identity"`
+	ClientCertificatePath     string          `config:"client_certificate_path"`
+	ClientCertificatePassword string          `config:"client_certificate_password"`
+	Cloud                     string          `config:"cloud"`
+	ResourceManagerEndpoint   string          `config:"resource_manager_endpoint"`
+}
+
+const (
+	// backendARM lists the resources of each subscription through the Azure Resource Manager APIs.
+	backendARM = "arm"
+	// backendResourceGraph queries the resources of all the subscriptions at once through Azure Resource Graph.
+	backendResourceGraph = "resource_graph"
+)
+
+// azureAssetTypes are all the asset types collected by the input.
+var azureAssetTypes = []string{
+	"azure.resource_group",
+	"azure.vm.instance",
+	"azure.vnet",
+	"azure.subnet",
+	"azure.vmss",
+	"azure.vmss.instance",
+	"k8s.cluster",
+	"azure.storage.account",
+	"azure.sql.server",
+	"azure.sql.database",
+	"azure.app_service.plan",
+	"azure.web_app",
+}
+
+// Validate checks the collection backend and authentication settings.
+func (c *config) Validate() error {
+	switch c.Backend {
+	case backendARM, backendResourceGraph:
+	default:
+		return fmt.Errorf("unknown backend %q", c.Backend)
+	}
+	if _, err := getAzureCloudConfiguration(*c); err != nil {
+		return err
+	}
+	if c.ClientCertificatePath != "" && (c.TenantID == "" || c.ClientID == "") {
+		return fmt.Errorf("client_certificate_path requires tenant_id and client_id")
+	}
+	return nil
+}
+
+// getSubscriptionIDs returns the subscriptions listed with subscription_ids and subscription_id.
+func (c *config) getSubscriptionIDs() []string {
+	if c.SubscriptionID != "" {
+		return append([]string{c.SubscriptionID}, c.SubscriptionIDs...)
+	}
+	return c.SubscriptionIDs
+}
+
+// getResourceGroups returns the resource groups listed with resource_groups and resource_group.
+func (c *config) getResourceGroups() []string {
+	if c.ResourceGroup != "" {
+		return append([]string{c.ResourceGroup}, c.ResourceGroups...)
+	}
+	return c.ResourceGroups
+}
+
+// selectsSubscriptions reports whether the assets are collected from a selection of subscriptions,
+// rather than from every subscription the credentials have access to.
+func (c *config) selectsSubscriptions() bool {
+	return len(c.getSubscriptionIDs()) > 0 || len(c.ManagementGroups) > 0 || len(c.IncludeSubscriptions) > 0 || len(c.ExcludeSubscriptions) > 0
 }
 
 func defaultConfig() config {
@@ -79,6 +160,8 @@ func defaultConfig() config {
 		SubscriptionID: "",
 		TenantID:       "",
 		ResourceGroup:  "",
+		Backend:        backendARM,
+		Cloud:          cloudAzurePublic,
 	}
 }
 
@@ -88,10 +171,19 @@ type assetsAzure struct {
 
 func (s *assetsAzure) Name() string { return "assets_azure" }
 
-func (s *assetsAzure) Test(_ input.TestContext) error {
-	return nil
+// Test checks that the configured credentials can authenticate to Azure.
+func (s *assetsAzure) Test(testCtx input.TestContext) error {
+	ctx := ctxtool.FromCanceller(testCtx.Cancelation)
+	clientOptions, err := getAzureClientOptions(s.Config)
+	if err != nil {
+		return err
+	}
+	_, err = authenticateAzure(ctx, s.Config, clientOptions, testCtx.Logger)
+	return err
 }
 
+// Run collects the assets once per period. It stops when authenticating to Azure fails, as no asset can be
+// collected until the credentials are fixed, while the other errors are logged and collection is retried.
 func (s *assetsAzure) Run(inputCtx input.Context, publisher stateless.Publisher) error {
 	ctx := ctxtool.FromCanceller(inputCtx.Cancelation)
 	log := inputCtx.Logger.With("assets_azure")
@@ -107,76 +199,247 @@ func (s *assetsAzure) Run(inputCtx input.Context, publisher stateless.Publisher)
 	case <-ctx.Done():
 		return nil
 	default:
-		collectAzureAssets(ctx, log, cfg, publisher)
+		if err := runAzureCollectionCycle(ctx, log, cfg, publisher); err != nil {
+			return err
+		}
 	}
 	for {
 		select {
 		case <-ctx.Done():
 			return nil
 		case <-ticker.C:
-			collectAzureAssets(ctx, log, cfg, publisher)
+			if err := runAzureCollectionCycle(ctx, log, cfg, publisher); err != nil {
+				return err
+			}
 		}
 	}
 }
 
-func getAzureCredentials(cfg config, log *logp.Logger) (azcore.TokenCredential, error) {
-	if cfg.TenantID != "" && cfg.ClientID != "" && cfg.ClientSecret != "" {
-		log.Debug("Retrieving Azure credentials from assetbeat configuration...")
-		return azidentity.NewClientSecretCredential(cfg.TenantID, cfg.ClientID, cfg.ClientSecret, nil)
-	} else {
-		log.Debug("No Client or Tenant configuration provided. Retrieving default Azure credentials")
-		return azidentity.NewDefaultAzureCredential(nil)
+// runAzureCollectionCycle collects the assets once, and returns the fatal errors only.
+func runAzureCollectionCycle(ctx context.Context, log *logp.Logger, cfg config, publisher stateless.Publisher) error {
+	err := collectAzureAssets(ctx, log, cfg, publisher)
+	var authErr *azureAuthenticationError
+	switch {
+	case err == nil || ctx.Err() != nil:
+		return nil
+	case errors.As(err, &authErr):
+		log.Errorf("Stopping Azure asset collection: %v", err)
+		return err
+	default:
+		log.Errorf("Error while collecting Azure assets: %v", err)
+		return nil
 	}
 }
 
-func collectAzureAssets(ctx context.Context, log *logp.Logger, cfg config, publisher stateless.Publisher) {
-	cred, err := getAzureCredentials(cfg, log)
+// collectAzureAssets collects the assets of all the subscriptions, and waits for the collection to complete.
+// A failing subscription or asset type doesn't prevent collecting the others, their errors are all returned.
+// With the Resource Graph backend, the asset types Resource Graph doesn't support are collected through the
+// Azure Resource Manager APIs.
+func collectAzureAssets(ctx context.Context, log *logp.Logger, cfg config, publisher stateless.Publisher) error {
+	clientOptions, err := getAzureClientOptions(cfg)
 	if err != nil {
-		log.Errorf("Error while retrieving Azure credentials: %v")
+		return fmt.Errorf("error configuring Azure clients: %w", err)
 	}
-	subscriptions, err := getAzureSubscriptions(ctx, cfg, cred)
+	cred, err := authenticateAzure(ctx, cfg, clientOptions, log)
 	if err != nil {
-		log.Errorf("Error while retrieving Azure subscriptions list: %v")
+		return err
+	}
+	var errs []error
+	if cfg.Backend == backendResourceGraph {
+		err := collectAzureResourceGraphAssets(ctx, log, cfg, cred, clientOptions, publisher)
+		var authErr *azureAuthenticationError
+		if errors.As(err, &authErr) {
+			return err
+		}
+		cfg.AssetTypes = getResourceGraphUnsupportedAssetTypes(cfg.AssetTypes)
+		if len(cfg.AssetTypes) == 0 {
+			return err
+		}
+		log.Debugf("Collecting %v assets through the Azure Resource Manager APIs, as Azure Resource Graph doesn't support them", cfg.AssetTypes)
+		errs = append(errs, err)
+	}
+	subscriptions, err := getAzureSubscriptions(ctx, cfg, cred, clientOptions)
+	if err != nil {
+		if isAzureAuthenticationError(err) {
+			return &azureAuthenticationError{err: err}
+		}
+		return errors.Join(append(errs, fmt.Errorf("error retrieving Azure subscriptions list: %w", err))...)
 	}
 
+	var collection azureCollection
 	for _, sub := range subscriptions {
-		if internal.IsTypeEnabled(cfg.AssetTypes, "azure.vm.instance") {
-			clientFactory, err := armcompute.NewClientFactory(sub, cred, nil)
-			if err != nil {
-				log.Errorf("Error creating Azure Compute Client Factory: %v", err)
-				return
-			}
-			client := clientFactory.NewVirtualMachinesClient()
-			go func(currentSub string) {
-				err = collectAzureVMAssets(ctx, client, currentSub, cfg.Regions, cfg.ResourceGroup, log, publisher)
-				if err != nil {
-					log.Errorf("Error while collecting Azure VM assets: %v", err)
-				}
-			}(sub)
-		}
+		collectAzureSubscriptionAssets(ctx, log, cfg, sub, cred, clientOptions, &collection, publisher)
 	}
+	return errors.Join(append(errs, collection.wait())...)
 }
 
-func getAzureSubscriptions(ctx context.Context, cfg config, cred azcore.TokenCredential) ([]string, error) {
-	var subscriptions []string
-	if cfg.SubscriptionID != "" {
-		subscriptions = append(subscriptions, cfg.SubscriptionID)
-	} else {
-		subscriptionClientFactory, _ := armsubscription.NewClientFactory(cred, nil)
-		client := subscriptionClientFactory.NewSubscriptionsClient()
-
-		pager := client.NewListPager(nil)
-
-		for pager.More() {
-			page, err := pager.NextPage(ctx)
+// collectAzureSubscriptionAssets starts the collection of each enabled asset type of a subscription.
+func collectAzureSubscriptionAssets(ctx context.Context, log *logp.Logger, cfg config, sub string, cred azcore.TokenCredential, clientOptions *arm.ClientOptions, collection *azureCollection, publisher stateless.Publisher) {
+	resourceGroups := cfg.getResourceGroups()
+	if internal.IsTypeEnabled(cfg.AssetTypes, "azure.resource_group") {
+		collection.run(sub, func() error {
+			clientFactory, err := armresources.NewClientFactory(sub, cred, clientOptions)
+			if err != nil {
+				return fmt.Errorf("error creating Azure Resources Client Factory: %w", err)
+			}
+			err = collectAzureResourceGroupAssets(ctx, clientFactory.NewResourceGroupsClient(), sub, resourceGroups, log, publisher)
+			if err != nil {
+				return fmt.Errorf("error collecting Azure resource group assets: %w", err)
+			}
+			return nil
+		})
+	}
+	if internal.IsTypeEnabled(cfg.AssetTypes, "azure.vm.instance") {
+		collection.run(sub, func() error {
+			clientFactory, err := armcompute.NewClientFactory(sub, cred, clientOptions)
+			if err != nil {
+				return fmt.Errorf("error creating Azure Compute Client Factory: %w", err)
+			}
+			nics, err := getAzureNetworkInterfaces(ctx, sub, cred, clientOptions)
+			if err != nil {
+				log.Warnf("Error while retrieving Azure network interfaces of subscription %s, VMs are published without their network configuration: %v", sub, err)
+			}
+			err = collectAzureVMAssets(ctx, clientFactory.NewVirtualMachinesClient(), nics, sub, cfg.Regions, resourceGroups, log, publisher)
+			if err != nil {
+				return fmt.Errorf("error collecting Azure VM assets: %w", err)
+			}
+			return nil
+		})
+	}
+	if internal.IsTypeEnabled(cfg.AssetTypes, "azure.vnet") || internal.IsTypeEnabled(cfg.AssetTypes, "azure.subnet") {
+		collection.run(sub, func() error {
+			clientFactory, err := armnetwork.NewClientFactory(sub, cred, clientOptions)
 			if err != nil {
-				return nil, fmt.Errorf("failed to advance page: %v", err)
+				return fmt.Errorf("error creating Azure Network Client Factory: %w", err)
 			}
-			for _, v := range page.Value {
-				subscriptions = append(subscriptions, *v.SubscriptionID)
+			err = collectAzureVNetAssets(ctx, clientFactory.NewVirtualNetworksClient(), sub, cfg.Regions, resourceGroups, cfg.AssetTypes, log, publisher)
+			if err != nil {
+				return fmt.Errorf("error collecting Azure virtual network assets: %w", err)
+			}
+			return nil
+		})
+	}
+	if internal.IsTypeEnabled(cfg.AssetTypes, "azure.vmss") || internal.IsTypeEnabled(cfg.AssetTypes, "azure.vmss.instance") {
+		collection.run(sub, func() error {
+			computeClientFactory, err := armcompute.NewClientFactory(sub, cred, clientOptions)
+			if err != nil {
+				return fmt.Errorf("error creating Azure Compute Client Factory: %w", err)
+			}
+			networkClientFactory, err := armnetwork.NewClientFactory(sub, cred, clientOptions)
+			if err != nil {
+				return fmt.Errorf("error creating Azure Network Client Factory: %w", err)
+			}
+			scaleSetClient := computeClientFactory.NewVirtualMachineScaleSetsClient()
+			scaleSetVMClient := computeClientFactory.NewVirtualMachineScaleSetVMsClient()
+			interfacesClient := networkClientFactory.NewInterfacesClient()
+			publicIPsClient := networkClientFactory.NewPublicIPAddressesClient()
+			err = collectAzureVMSSAssets(ctx, scaleSetClient, scaleSetVMClient, interfacesClient, publicIPsClient, sub, cfg.Regions, resourceGroups, cfg.AssetTypes, log, publisher)
+			if err != nil {
+				return fmt.Errorf("error collecting Azure VM scale set assets: %w", err)
+			}
+			return nil
+		})
+	}
+	if internal.IsTypeEnabled(cfg.AssetTypes, "k8s.cluster") {
+		collection.run(sub, func() error {
+			containerServiceClientFactory, err := armcontainerservice.NewClientFactory(sub, cred, clientOptions)
+			if err != nil {
+				return fmt.Errorf("error creating Azure Container Service Client Factory: %w", err)
 			}
+			computeClientFactory, err := armcompute.NewClientFactory(sub, cred, clientOptions)
+			if err != nil {
+				return fmt.Errorf("error creating Azure Compute Client Factory: %w", err)
+			}
+			clusterClient := containerServiceClientFactory.NewManagedClustersClient()
+			scaleSetClient := computeClientFactory.NewVirtualMachineScaleSetsClient()
+			scaleSetVMClient := computeClientFactory.NewVirtualMachineScaleSetVMsClient()
+			err = collectAzureAKSAssets(ctx, clusterClient, scaleSetClient, scaleSetVMClient, sub, cfg.Regions, resourceGroups, log, publisher)
+			if err != nil {
+				return fmt.Errorf("error collecting Azure AKS assets: %w", err)
+			}
+			return nil
+		})
+	}
+	if internal.IsTypeEnabled(cfg.AssetTypes, "azure.storage.account") {
+		collection.run(sub, func() error {
+			clientFactory, err := armstorage.NewClientFactory(sub, cred, clientOptions)
+			if err != nil {
+				return fmt.Errorf("error creating Azure Storage Client Factory: %w", err)
+			}
+			err = collectAzureStorageAccountAssets(ctx, clientFactory.NewAccountsClient(), sub, cfg.Regions, resourceGroups, log, publisher)
+			if err != nil {
+				return fmt.Errorf("error collecting Azure storage account assets: %w", err)
+			}
+			return nil
+		})
+	}
+	if internal.IsTypeEnabled(cfg.AssetTypes, "azure.sql.server") || internal.IsTypeEnabled(cfg.AssetTypes, "azure.sql.database") {
+		collection.run(sub, func() error {
+			clientFactory, err := armsql.NewClientFactory(sub, cred, clientOptions)
+			if err != nil {
+				return fmt.Errorf("error creating Azure SQL Client Factory: %w", err)
+			}
+			serversClient := clientFactory.NewServersClient()
+			databasesClient := clientFactory.NewDatabasesClient()
+			vnetRulesClient := clientFactory.NewVirtualNetworkRulesClient()
+			err = collectAzureSQLAssets(ctx, serversClient, databasesClient, vnetRulesClient, sub, cfg.Regions, resourceGroups, cfg.AssetTypes, log, publisher)
+			if err != nil {
+				return fmt.Errorf("error collecting Azure SQL assets: %w", err)
+			}
+			return nil
+		})
+	}
+	if internal.IsTypeEnabled(cfg.AssetTypes, "azure.app_service.plan") || internal.IsTypeEnabled(cfg.AssetTypes, "azure.web_app") {
+		collection.run(sub, func() error {
+			clientFactory, err := armappservice.NewClientFactory(sub, cred, clientOptions)
+			if err != nil {
+				return fmt.Errorf("error creating Azure App Service Client Factory: %w", err)
+			}
+			plansClient := clientFactory.NewPlansClient()
+			webAppsClient := clientFactory.NewWebAppsClient()
+			err = collectAzureAppServiceAssets(ctx, plansClient, webAppsClient, sub, cfg.Regions, resourceGroups, cfg.AssetTypes, log, publisher)
+			if err != nil {
+				return fmt.Errorf("error collecting Azure App Service assets: %w", err)
+			}
+			return nil
+		})
+	}
+}
+
+// collectAzureResourceGraphAssets collects the assets of the configured subscriptions, or of every
+// subscription the credentials have access to, with a few Azure Resource Graph queries.
+func collectAzureResourceGraphAssets(ctx context.Context, log *logp.Logger, cfg config, cred azcore.TokenCredential, clientOptions *arm.ClientOptions, publisher stateless.Publisher) error {
+	client, err := armresourcegraph.NewClient(cred, clientOptions)
+	if err != nil {
+		return fmt.Errorf("error creating Azure Resource Graph client: %w", err)
+	}
+	// Resource Graph queries all the subscriptions when given none, they are only listed to select some of them
+	var subscriptions []string
+	if cfg.selectsSubscriptions() {
+		subscriptions, err = getAzureSubscriptions(ctx, cfg, cred, clientOptions)
+		if err != nil {
+			if isAzureAuthenticationError(err) {
+				return &azureAuthenticationError{err: err}
+			}
+			return fmt.Errorf("error retrieving Azure subscriptions list: %w", err)
+		}
+		if len(subscriptions) == 0 {
+			log.Warn("No Azure subscription matches the configuration, no assets are collected")
+			return nil
 		}
 	}
+	err = collectResourceGraphAssets(ctx, client, subscriptions, cfg.Regions, cfg.getResourceGroups(), cfg.AssetTypes, log, publisher)
+	if err != nil {
+		return fmt.Errorf("error collecting Azure Resource Graph assets: %w", err)
+	}
+	return nil
+}
 
-	return subscriptions, nil
+// getAzureNetworkInterfaces indexes the network interfaces of a subscription, to resolve the network configuration of its VMs.
+func getAzureNetworkInterfaces(ctx context.Context, subscriptionID string, cred azcore.TokenCredential, clientOptions *arm.ClientOptions) (*azureNetworkInterfaces, error) {
+	clientFactory, err := armnetwork.NewClientFactory(subscriptionID, cred, clientOptions)
+	if err != nil {
+		return nil, fmt.Errorf("error creating Azure Network Client Factory: %w", err)
+	}
+	return listAzureNetworkInterfaces(ctx, clientFactory.NewInterfacesClient(), clientFactory.NewPublicIPAddressesClient())
 }
@@ -23,6 +23,8 @@ import (
 	v2 "github.com/elastic/beats/v7/filebeat/input/v2"
 	"github.com/elastic/elastic-agent-libs/logp"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"path/filepath"
 	"sync"
 	"testing"
 	"time"
@@ -51,7 +53,11 @@ func TestAssetsAzure_Run(t *testing.T) {
 	go func() {
 		defer wg.Done()
 		err = input.Run(inputCtx, publisher)
-		assert.NoError(t, err)
+		// without Azure credentials in the test environment, Run stops either when canceled or when failing to authenticate
+		if err != nil {
+			var authErr *azureAuthenticationError
+			assert.ErrorAs(t, err, &authErr)
+		}
 	}()
 
 	time.Sleep(time.Second)
@@ -69,3 +75,76 @@ func TestAssetsAzure_Run(t *testing.T) {
 		// Waitgroup finished in time, nothing to do
 	}
 }
+
+func TestAssetsAzure_Run_authenticationError(t *testing.T) {
+	publisher := testutil.NewInMemoryPublisher()
+	cfg := defaultConfig()
+	cfg.ClientCertificatePath = filepath.Join(t.TempDir(), "missing.pem")
+	cfg.TenantID = "tenant"
+	cfg.ClientID = "client"
+	input, err := newAssetsAzure(cfg)
+	require.NoError(t, err)
+
+	err = input.Run(v2.Context{Logger: logp.NewLogger("test"), Cancelation: context.Background()}, publisher)
+	var authErr *azureAuthenticationError
+	assert.ErrorAs(t, err, &authErr)
+	assert.Empty(t, publisher.Events)
+
+	err = input.Test(v2.TestContext{Logger: logp.NewLogger("test"), Cancelation: context.Background()})
+	assert.ErrorAs(t, err, &authErr)
+}
+
+func TestConfig_Validate(t *testing.T) {
+	for _, tt := range []struct {
+		name        string
+		cfg         func(cfg *config)
+		expectedErr string
+	}{
+		{
+			name: "arm backend",
+			cfg:  func(cfg *config) { cfg.Backend = backendARM },
+		},
+		{
+			name: "resource graph backend",
+			cfg:  func(cfg *config) { cfg.Backend = backendResourceGraph },
+		},
+		{
+			name:        "unknown backend",
+			cfg:         func(cfg *config) { cfg.Backend = "other" },
+			expectedErr: `unknown backend "other"`,
+		},
+		{
+			name: "china cloud",
+			cfg:  func(cfg *config) { cfg.Cloud = cloudAzureChina },
+		},
+		{
+			name:        "unknown cloud",
+			cfg:         func(cfg *config) { cfg.Cloud = "other" },
+			expectedErr: `unknown cloud "other"`,
+		},
+		{
+			name: "client certificate",
+			cfg: func(cfg *config) {
+				cfg.ClientCertificatePath = "cert.pem"
+				cfg.TenantID = "tenant"
+				cfg.ClientID = "client"
+			},
+		},
+		{
+			name:        "client certificate without client ID",
+			cfg:         func(cfg *config) { cfg.ClientCertificatePath = "cert.pem" },
+			expectedErr: "client_certificate_path requires tenant_id and client_id",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := defaultConfig()
+			tt.cfg(&cfg)
+			err := cfg.Validate()
+			if tt.expectedErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.expectedErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package azure
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// azureSubscriptionError is an error collecting assets of a subscription. It doesn't prevent collecting
+// the assets of the other subscriptions, nor the other assets of the same subscription.
+type azureSubscriptionError struct {
+	subscriptionID string
+	err            error
+}
+
+func (e *azureSubscriptionError) Error() string {
+	return fmt.Sprintf("subscription %s: %v", e.subscriptionID, e.err)
+}
+
+func (e *azureSubscriptionError) Unwrap() error {
+	return e.err
+}
+
+// azureCollection runs the collections of the asset types of the subscriptions concurrently,
+// and records their errors so that a failing collection doesn't stop the others.
+type azureCollection struct {
+	wg   sync.WaitGroup
+	mu   sync.Mutex
+	errs []error
+}
+
+// run starts collecting assets of a subscription, recording the error of collect, if any.
+func (c *azureCollection) run(subscriptionID string, collect func() error) {
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		if err := collect(); err != nil {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			c.errs = append(c.errs, &azureSubscriptionError{subscriptionID: subscriptionID, err: err})
+		}
+	}()
+}
+
+// wait waits for all the collections to complete, and returns their errors.
+func (c *azureCollection) wait() error {
+	c.wg.Wait()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return errors.Join(c.errs...)
+}
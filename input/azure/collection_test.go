@@ -0,0 +1,56 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package azure
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"sync/atomic"
+	"testing"
+)
+
+func TestAzureCollection(t *testing.T) {
+	var collection azureCollection
+	var collected atomic.Int32
+	collection.run(prodSubscriptionID, func() error {
+		collected.Add(1)
+		return errors.New("forbidden")
+	})
+	collection.run(prodSubscriptionID, func() error {
+		collected.Add(1)
+		return nil
+	})
+	collection.run(devSubscriptionID, func() error {
+		collected.Add(1)
+		return nil
+	})
+
+	err := collection.wait()
+	assert.Equal(t, int32(3), collected.Load())
+	assert.EqualError(t, err, "subscription "+prodSubscriptionID+": forbidden")
+	var subErr *azureSubscriptionError
+	if assert.ErrorAs(t, err, &subErr) {
+		assert.Equal(t, prodSubscriptionID, subErr.subscriptionID)
+	}
+}
+
+func TestAzureCollection_noErrors(t *testing.T) {
+	var collection azureCollection
+	collection.run(devSubscriptionID, func() error { return nil })
+	assert.NoError(t, collection.wait())
+}
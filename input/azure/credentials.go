@@ -0,0 +1,195 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package azure
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/cloud"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/elastic/elastic-agent-libs/logp"
+	"net/http"
+	"os"
+)
+
+const (
+	cloudAzurePublic       = "azure_public"
+	cloudAzureChina        = "azure_china"
+	cloudAzureUSGovernment = "azure_us_government"
+)
+
+// getAzureCloudConfiguration returns the authority and Azure Resource Manager endpoints of the configured cloud,
+// with the Resource Manager endpoint overridden when resource_manager_endpoint is set.
+func getAzureCloudConfiguration(cfg config) (cloud.Configuration, error) {
+	var base cloud.Configuration
+	switch cfg.Cloud {
+	case cloudAzurePublic:
+		base = cloud.AzurePublic
+	case cloudAzureChina:
+		base = cloud.AzureChina
+	case cloudAzureUSGovernment:
+		base = cloud.AzureGovernment
+	default:
+		return cloud.Configuration{}, fmt.Errorf("unknown cloud %q", cfg.Cloud)
+	}
+
+	// the services of the predefined configurations are shared, they are copied before being modified
+	configuration := cloud.Configuration{
+		ActiveDirectoryAuthorityHost: base.ActiveDirectoryAuthorityHost,
+		Services:                     make(map[cloud.ServiceName]cloud.ServiceConfiguration, len(base.Services)),
+	}
+	for name, service := range base.Services {
+		configuration.Services[name] = service
+	}
+	if cfg.ResourceManagerEndpoint != "" {
+		resourceManager := configuration.Services[cloud.ResourceManager]
+		resourceManager.Endpoint = cfg.ResourceManagerEndpoint
+		configuration.Services[cloud.ResourceManager] = resourceManager
+	}
+	return configuration, nil
+}
+
+// getAzureClientOptions returns the options of the Azure SDK clients, for the configured cloud.
+func getAzureClientOptions(cfg config) (*arm.ClientOptions, error) {
+	configuration, err := getAzureCloudConfiguration(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &arm.ClientOptions{ClientOptions: azcore.ClientOptions{Cloud: configuration}}, nil
+}
+
+// azureAuthenticationError is an error authenticating to Azure because of invalid or rejected credentials.
+// It is fatal, as no asset can be collected until the credentials are fixed.
+type azureAuthenticationError struct {
+	err error
+}
+
+func (e *azureAuthenticationError) Error() string {
+	return fmt.Sprintf("error authenticating to Azure: %v", e.err)
+}
+
+func (e *azureAuthenticationError) Unwrap() error {
+	return e.err
+}
+
+// authenticateAzure returns the configured credentials, once checked by retrieving a token for the Azure Resource Manager APIs.
+// Failing to retrieve the token is only an authentication error when the credentials are rejected, other failures
+// like an unreachable or unavailable identity endpoint may not happen again on the next collection.
+func authenticateAzure(ctx context.Context, cfg config, clientOptions *arm.ClientOptions, log *logp.Logger) (azcore.TokenCredential, error) {
+	cred, err := getAzureCredentials(cfg, clientOptions.ClientOptions, log)
+	if err != nil {
+		return nil, &azureAuthenticationError{err: err}
+	}
+	audience := clientOptions.Cloud.Services[cloud.ResourceManager].Audience
+	_, err = cred.GetToken(ctx, policy.TokenRequestOptions{Scopes: []string{audience + "/.default"}})
+	if err != nil {
+		// a canceled input didn't fail to authenticate
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if isAzureAuthenticationError(err) {
+			return nil, &azureAuthenticationError{err: err}
+		}
+		return nil, fmt.Errorf("error retrieving an Azure token: %w", err)
+	}
+	return cred, nil
+}
+
+// isAzureAuthenticationError reports whether an Azure API call failed because the credentials were rejected.
+func isAzureAuthenticationError(err error) bool {
+	var authErr *azidentity.AuthenticationFailedError
+	if errors.As(err, &authErr) {
+		return isAzureCredentialRejection(authErr.RawResponse)
+	}
+	var respErr *azcore.ResponseError
+	return errors.As(err, &respErr) && respErr.StatusCode == http.StatusUnauthorized
+}
+
+// isAzureCredentialRejection reports whether a failed token request was rejected by Microsoft Entra ID
+// because of the client itself, e.g. an unknown client ID, or an invalid or expired secret or certificate.
+func isAzureCredentialRejection(resp *http.Response) bool {
+	if resp == nil || (resp.StatusCode != http.StatusBadRequest && resp.StatusCode != http.StatusUnauthorized) {
+		return false
+	}
+	body, err := runtime.Payload(resp)
+	if err != nil {
+		return false
+	}
+	var tokenErr struct {
+		Error string `json:"error"`
+	}
+	if err := json.Unmarshal(body, &tokenErr); err != nil {
+		return false
+	}
+	return tokenErr.Error == "invalid_client" || tokenErr.Error == "unauthorized_client"
+}
+
+// getAzureCredentials returns the credentials of the first configured authentication method among:
+// user-assigned managed identity, workload identity, client certificate and client secret.
+// The default Azure credentials are used when none is configured.
+func getAzureCredentials(cfg config, clientOptions azcore.ClientOptions, log *logp.Logger) (azcore.TokenCredential, error) {
+	switch {
+	case cfg.ManagedIdentityClientID != "":
+		log.Debug("Retrieving Azure credentials of the user-assigned managed identity...")
+		return azidentity.NewManagedIdentityCredential(&azidentity.ManagedIdentityCredentialOptions{
+			ClientOptions: clientOptions,
+			ID:            azidentity.ClientID(cfg.ManagedIdentityClientID),
+		})
+	case cfg.WorkloadIdentity:
+		// client and tenant IDs default to the AZURE_CLIENT_ID and AZURE_TENANT_ID environment variables set by AKS
+		log.Debug("Retrieving Azure credentials from the workload identity...")
+		return azidentity.NewWorkloadIdentityCredential(&azidentity.WorkloadIdentityCredentialOptions{
+			ClientOptions: clientOptions,
+			ClientID:      cfg.ClientID,
+			TenantID:      cfg.TenantID,
+		})
+	case cfg.ClientCertificatePath != "":
+		log.Debug("Retrieving Azure credentials from the client certificate...")
+		data, err := os.ReadFile(cfg.ClientCertificatePath)
+		if err != nil {
+			return nil, fmt.Errorf("error reading client certificate: %w", err)
+		}
+		var password []byte
+		if cfg.ClientCertificatePassword != "" {
+			password = []byte(cfg.ClientCertificatePassword)
+		}
+		certs, key, err := azidentity.ParseCertificates(data, password)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing client certificate: %w", err)
+		}
+		return azidentity.NewClientCertificateCredential(cfg.TenantID, cfg.ClientID, certs, key, &azidentity.ClientCertificateCredentialOptions{
+			ClientOptions: clientOptions,
+		})
+	case cfg.TenantID != "" && cfg.ClientID != "" && cfg.ClientSecret != "":
+		log.Debug("Retrieving Azure credentials from assetbeat configuration...")
+		return azidentity.NewClientSecretCredential(cfg.TenantID, cfg.ClientID, cfg.ClientSecret, &azidentity.ClientSecretCredentialOptions{
+			ClientOptions: clientOptions,
+		})
+	default:
+		log.Debug("No Client or Tenant configuration provided. Retrieving default Azure credentials")
+		return azidentity.NewDefaultAzureCredential(&azidentity.DefaultAzureCredentialOptions{
+			ClientOptions: clientOptions,
+		})
+	}
+}
@@ -0,0 +1,224 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package azure
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/cloud"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/elastic/elastic-agent-libs/logp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGetAzureCloudConfiguration(t *testing.T) {
+	for _, tt := range []struct {
+		name                    string
+		cloud                   string
+		resourceManagerEndpoint string
+		expectedAuthorityHost   string
+		expectedEndpoint        string
+	}{
+		{
+			name:                  "public cloud",
+			cloud:                 cloudAzurePublic,
+			expectedAuthorityHost: "https://login.microsoftonline.com/",
+			expectedEndpoint:      "https://management.azure.com",
+		},
+		{
+			name:                  "china cloud",
+			cloud:                 cloudAzureChina,
+			expectedAuthorityHost: "https://login.chinacloudapi.cn/",
+			expectedEndpoint:      "https://management.chinacloudapi.cn",
+		},
+		{
+			name:                  "US government cloud",
+			cloud:                 cloudAzureUSGovernment,
+			expectedAuthorityHost: "https://login.microsoftonline.us/",
+			expectedEndpoint:      "https://management.usgovcloudapi.net",
+		},
+		{
+			name:                    "resource manager endpoint override",
+			cloud:                   cloudAzurePublic,
+			resourceManagerEndpoint: "http://localhost:8080",
+			expectedAuthorityHost:   "https://login.microsoftonline.com/",
+			expectedEndpoint:        "http://localhost:8080",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := defaultConfig()
+			cfg.Cloud = tt.cloud
+			cfg.ResourceManagerEndpoint = tt.resourceManagerEndpoint
+
+			configuration, err := getAzureCloudConfiguration(cfg)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedAuthorityHost, configuration.ActiveDirectoryAuthorityHost)
+			assert.Equal(t, tt.expectedEndpoint, configuration.Services[cloud.ResourceManager].Endpoint)
+			// the predefined configurations are left untouched
+			assert.Equal(t, "https://management.azure.com", cloud.AzurePublic.Services[cloud.ResourceManager].Endpoint)
+		})
+	}
+}
+
+func TestGetAzureCredentials(t *testing.T) {
+	certificatePath := writeTestCertificate(t)
+	t.Setenv("AZURE_FEDERATED_TOKEN_FILE", filepath.Join(t.TempDir(), "token"))
+
+	for _, tt := range []struct {
+		name     string
+		cfg      func(cfg *config)
+		expected azcore.TokenCredential
+	}{
+		{
+			name:     "user-assigned managed identity",
+			cfg:      func(cfg *config) { cfg.ManagedIdentityClientID = "client" },
+			expected: &azidentity.ManagedIdentityCredential{},
+		},
+		{
+			name: "workload identity",
+			cfg: func(cfg *config) {
+				cfg.WorkloadIdentity = true
+				cfg.TenantID = "tenant"
+				cfg.ClientID = "client"
+			},
+			expected: &azidentity.WorkloadIdentityCredential{},
+		},
+		{
+			name: "client certificate",
+			cfg: func(cfg *config) {
+				cfg.ClientCertificatePath = certificatePath
+				cfg.TenantID = "tenant"
+				cfg.ClientID = "client"
+			},
+			expected: &azidentity.ClientCertificateCredential{},
+		},
+		{
+			name: "client secret",
+			cfg: func(cfg *config) {
+				cfg.TenantID = "tenant"
+				cfg.ClientID = "client"
+				cfg.ClientSecret = "secret"
+			},
+			expected: &azidentity.ClientSecretCredential{},
+		},
+		{
+			name:     "default credentials",
+			cfg:      func(cfg *config) {},
+			expected: &azidentity.DefaultAzureCredential{},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := defaultConfig()
+			tt.cfg(&cfg)
+			clientOptions, err := getAzureClientOptions(cfg)
+			require.NoError(t, err)
+
+			cred, err := getAzureCredentials(cfg, clientOptions.ClientOptions, logp.NewLogger("test"))
+			require.NoError(t, err)
+			assert.IsType(t, tt.expected, cred)
+		})
+	}
+}
+
+func TestGetAzureCredentials_invalidCertificate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cert.pem")
+	require.NoError(t, os.WriteFile(path, []byte("not a certificate"), 0600))
+	cfg := defaultConfig()
+	cfg.ClientCertificatePath = path
+	cfg.TenantID = "tenant"
+	cfg.ClientID = "client"
+
+	_, err := getAzureCredentials(cfg, azcore.ClientOptions{}, logp.NewLogger("test"))
+	assert.ErrorContains(t, err, "error parsing client certificate")
+}
+
+func TestAuthenticateAzure_invalidCredentials(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.ClientCertificatePath = filepath.Join(t.TempDir(), "missing.pem")
+	cfg.TenantID = "tenant"
+	cfg.ClientID = "client"
+	clientOptions, err := getAzureClientOptions(cfg)
+	require.NoError(t, err)
+
+	_, err = authenticateAzure(context.Background(), cfg, clientOptions, logp.NewLogger("test"))
+	var authErr *azureAuthenticationError
+	assert.ErrorAs(t, err, &authErr)
+	assert.ErrorContains(t, err, "error authenticating to Azure: error reading client certificate")
+}
+
+func TestIsAzureAuthenticationError(t *testing.T) {
+	req, err := http.NewRequest(http.MethodGet, "https://management.azure.com/subscriptions", nil)
+	require.NoError(t, err)
+	responseError := func(statusCode int) error {
+		return fmt.Errorf("failed to advance page: %w", runtime.NewResponseError(&http.Response{StatusCode: statusCode, Body: http.NoBody, Request: req}))
+	}
+
+	assert.True(t, isAzureAuthenticationError(responseError(http.StatusUnauthorized)))
+	assert.False(t, isAzureAuthenticationError(responseError(http.StatusForbidden)))
+	assert.False(t, isAzureAuthenticationError(errors.New("other error")))
+
+	tokenError := func(statusCode int, body string) error {
+		resp := &http.Response{StatusCode: statusCode, Body: io.NopCloser(strings.NewReader(body)), Request: req}
+		return fmt.Errorf("failed to retrieve a token: %w", &azidentity.AuthenticationFailedError{RawResponse: resp})
+	}
+	assert.True(t, isAzureAuthenticationError(tokenError(http.StatusUnauthorized, `{"error": "invalid_client", "error_codes": [7000215]}`)))
+	assert.True(t, isAzureAuthenticationError(tokenError(http.StatusBadRequest, `{"error": "unauthorized_client", "error_codes": [700016]}`)))
+	assert.False(t, isAzureAuthenticationError(tokenError(http.StatusBadRequest, `{"error": "invalid_request", "error_codes": [90002]}`)))
+	assert.False(t, isAzureAuthenticationError(tokenError(http.StatusServiceUnavailable, `{"error": "temporarily_unavailable"}`)))
+	assert.False(t, isAzureAuthenticationError(tokenError(http.StatusTooManyRequests, "")))
+	assert.False(t, isAzureAuthenticationError(&azidentity.AuthenticationFailedError{}))
+}
+
+// writeTestCertificate writes a self-signed certificate and its RSA private key to a PEM file.
+func writeTestCertificate(t *testing.T) string {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "assetbeat"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	cert, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyBytes, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+
+	data := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert})
+	data = append(data, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyBytes})...)
+	path := filepath.Join(t.TempDir(), "cert.pem")
+	require.NoError(t, os.WriteFile(path, data, 0600))
+	return path
+}
@@ -0,0 +1,137 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package azure
+
+import (
+	"context"
+	"fmt"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v5"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v4"
+	"strings"
+)
+
+// azureNetworkInterfaces indexes the network interfaces of a subscription, and the addresses of its public IPs,
+// to resolve the network configuration of VMs, which only reference their network interfaces by ID.
+// Azure resource IDs are case-insensitive, so they are indexed in lowercase.
+type azureNetworkInterfaces struct {
+	interfaces map[string]*armnetwork.Interface
+	publicIPs  map[string]string
+}
+
+// azureVMNetwork is the network configuration of a VM, gathered from all its network interfaces.
+type azureVMNetwork struct {
+	PrivateIPAddresses []string
+	PublicIPAddresses  []string
+	SubnetIDs          []string
+}
+
+func newAzureNetworkInterfaces(interfaces []*armnetwork.Interface, publicIPs []*armnetwork.PublicIPAddress) *azureNetworkInterfaces {
+	n := &azureNetworkInterfaces{
+		interfaces: make(map[string]*armnetwork.Interface, len(interfaces)),
+		publicIPs:  make(map[string]string, len(publicIPs)),
+	}
+	for _, nic := range interfaces {
+		if nic.ID != nil {
+			n.interfaces[strings.ToLower(*nic.ID)] = nic
+		}
+	}
+	for _, ip := range publicIPs {
+		if ip.ID != nil && ip.Properties != nil && ip.Properties.IPAddress != nil {
+			n.publicIPs[strings.ToLower(*ip.ID)] = *ip.Properties.IPAddress
+		}
+	}
+	return n
+}
+
+// listAzureNetworkInterfaces lists the network interfaces and public IPs of a subscription.
+func listAzureNetworkInterfaces(ctx context.Context, interfacesClient *armnetwork.InterfacesClient, publicIPsClient *armnetwork.PublicIPAddressesClient) (*azureNetworkInterfaces, error) {
+	var interfaces []*armnetwork.Interface
+	interfacesPager := interfacesClient.NewListAllPager(nil)
+	for interfacesPager.More() {
+		page, err := interfacesPager.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list network interfaces: %w", err)
+		}
+		interfaces = append(interfaces, page.Value...)
+	}
+
+	var publicIPs []*armnetwork.PublicIPAddress
+	publicIPsPager := publicIPsClient.NewListAllPager(nil)
+	for publicIPsPager.More() {
+		page, err := publicIPsPager.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list public IP addresses: %w", err)
+		}
+		publicIPs = append(publicIPs, page.Value...)
+	}
+
+	return newAzureNetworkInterfaces(interfaces, publicIPs), nil
+}
+
+// getVMNetwork resolves the network configuration of a VM. Interfaces missing from the index are skipped.
+func (n *azureNetworkInterfaces) getVMNetwork(vm *armcompute.VirtualMachine) azureVMNetwork {
+	if vm.Properties == nil {
+		return azureVMNetwork{}
+	}
+	return n.getNetwork(vm.Properties.NetworkProfile)
+}
+
+// getNetwork resolves the network configuration of the network profile of a VM or of a VM scale set instance.
+func (n *azureNetworkInterfaces) getNetwork(profile *armcompute.NetworkProfile) azureVMNetwork {
+	var network azureVMNetwork
+	if n == nil || profile == nil {
+		return network
+	}
+	seenSubnets := map[string]bool{}
+	for _, ref := range profile.NetworkInterfaces {
+		if ref == nil || ref.ID == nil {
+			continue
+		}
+		nic, ok := n.interfaces[strings.ToLower(*ref.ID)]
+		if !ok || nic.Properties == nil {
+			continue
+		}
+		for _, ipConfig := range nic.Properties.IPConfigurations {
+			if ipConfig == nil || ipConfig.Properties == nil {
+				continue
+			}
+			props := ipConfig.Properties
+			if props.PrivateIPAddress != nil {
+				network.PrivateIPAddresses = append(network.PrivateIPAddresses, *props.PrivateIPAddress)
+			}
+			if props.PublicIPAddress != nil && props.PublicIPAddress.ID != nil {
+				if address, ok := n.publicIPs[strings.ToLower(*props.PublicIPAddress.ID)]; ok {
+					network.PublicIPAddresses = append(network.PublicIPAddresses, address)
+				}
+			}
+			if props.Subnet != nil && props.Subnet.ID != nil && !seenSubnets[strings.ToLower(*props.Subnet.ID)] {
+				seenSubnets[strings.ToLower(*props.Subnet.ID)] = true
+				network.SubnetIDs = append(network.SubnetIDs, *props.Subnet.ID)
+			}
+		}
+	}
+	return network
+}
+
+// getVNetIDFromSubnetID returns the ID of the virtual network a subnet belongs to.
+func getVNetIDFromSubnetID(subnetID string) string {
+	if i := strings.LastIndex(strings.ToLower(subnetID), "/subnets/"); i > 0 {
+		return subnetID[:i]
+	}
+	return ""
+}
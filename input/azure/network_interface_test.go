@@ -0,0 +1,85 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package azure
+
+import (
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v5"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v4"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestAzureNetworkInterfaces_getVMNetwork(t *testing.T) {
+	nics := newAzureNetworkInterfaces([]*armnetwork.Interface{&nic1}, []*armnetwork.PublicIPAddress{&publicIP1})
+	vmWithInterfaces := func(ids ...string) *armcompute.VirtualMachine {
+		var refs []*armcompute.NetworkInterfaceReference
+		for _, id := range ids {
+			refs = append(refs, &armcompute.NetworkInterfaceReference{ID: to.Ptr(id)})
+		}
+		return &armcompute.VirtualMachine{
+			Properties: &armcompute.VirtualMachineProperties{
+				NetworkProfile: &armcompute.NetworkProfile{NetworkInterfaces: refs},
+			},
+		}
+	}
+
+	for _, tt := range []struct {
+		name            string
+		nics            *azureNetworkInterfaces
+		vm              *armcompute.VirtualMachine
+		expectedNetwork azureVMNetwork
+	}{
+		{
+			name: "with a known network interface",
+			nics: nics,
+			vm:   vmWithInterfaces(nicID1),
+			expectedNetwork: azureVMNetwork{
+				PrivateIPAddresses: []string{"10.0.0.4"},
+				PublicIPAddresses:  []string{"20.0.0.1"},
+				SubnetIDs:          []string{subnetID1},
+			},
+		},
+		{
+			name:            "with an unknown network interface",
+			nics:            nics,
+			vm:              vmWithInterfaces(nicID1 + "-unknown"),
+			expectedNetwork: azureVMNetwork{},
+		},
+		{
+			name:            "without network interfaces index",
+			vm:              vmWithInterfaces(nicID1),
+			expectedNetwork: azureVMNetwork{},
+		},
+		{
+			name:            "without network profile",
+			nics:            nics,
+			vm:              &armcompute.VirtualMachine{},
+			expectedNetwork: azureVMNetwork{},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expectedNetwork, tt.nics.getVMNetwork(tt.vm))
+		})
+	}
+}
+
+func TestGetVNetIDFromSubnetID(t *testing.T) {
+	assert.Equal(t, vnetID1, getVNetIDFromSubnetID(subnetID1))
+	assert.Equal(t, "", getVNetIDFromSubnetID(vnetID1))
+}
@@ -0,0 +1,307 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package azure
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v5"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v4"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resourcegraph/armresourcegraph"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	"github.com/elastic/assetbeat/input/internal"
+	stateless "github.com/elastic/beats/v7/filebeat/input/v2/input-stateless"
+	"github.com/elastic/elastic-agent-libs/logp"
+	"strings"
+)
+
+// resourceGraphPageSize is the maximum number of rows Azure Resource Graph returns per page.
+const resourceGraphPageSize = 1000
+
+type resourceGraphClient interface {
+	Resources(ctx context.Context, query armresourcegraph.QueryRequest, options *armresourcegraph.ClientResourcesOptions) (armresourcegraph.ClientResourcesResponse, error)
+}
+
+// resourceGraphRow is a row of a Resource Graph query result, by column name.
+type resourceGraphRow map[string]any
+
+// resourceGraphAssetType describes how an asset type is collected through Azure Resource Graph:
+// the table and Azure resource type to query, the columns to project and how to publish each row.
+// Types relating their assets to network resources are given the network interfaces of all the subscriptions.
+type resourceGraphAssetType struct {
+	assetType              string
+	table                  string
+	resourceType           string
+	projection             string
+	anyRegion              bool
+	needsNetworkInterfaces bool
+	publish                func(row resourceGraphRow, nics *azureNetworkInterfaces, publisher stateless.Publisher) error
+}
+
+var resourceGraphAssetTypes = []resourceGraphAssetType{
+	{
+		assetType:              "azure.vm.instance",
+		resourceType:           "microsoft.compute/virtualmachines",
+		projection:             "id, name, location, subscriptionId, tags, zones, properties, state = tostring(properties.extended.instanceView.powerState.displayStatus)",
+		needsNetworkInterfaces: true,
+		publish:                publishResourceGraphVMInstance,
+	},
+	{
+		assetType:    "azure.vnet",
+		resourceType: "microsoft.network/virtualnetworks",
+		projection:   "id, name, location, subscriptionId, tags, properties",
+		publish:      publishResourceGraphVNet,
+	},
+	{
+		// subnets are not resources of their own in Resource Graph, they are read from their virtual network
+		assetType:    "azure.subnet",
+		resourceType: "microsoft.network/virtualnetworks",
+		projection:   "id, name, location, subscriptionId, properties",
+		publish:      publishResourceGraphSubnets,
+	},
+	{
+		// resource groups are not resources, they are listed along with subscriptions and management groups.
+		// Their location is only where their metadata is stored, so they are not filtered by region.
+		assetType:    "azure.resource_group",
+		table:        "resourcecontainers",
+		resourceType: "microsoft.resources/subscriptions/resourcegroups",
+		projection:   "id, name, location, subscriptionId, tags, managedBy, properties",
+		anyRegion:    true,
+		publish:      publishResourceGraphResourceGroup,
+	},
+}
+
+// getResourceGraphUnsupportedAssetTypes returns the enabled asset types that aren't collected through Resource Graph.
+func getResourceGraphUnsupportedAssetTypes(assetTypes []string) []string {
+	supported := make(map[string]bool, len(resourceGraphAssetTypes))
+	for _, t := range resourceGraphAssetTypes {
+		supported[t.assetType] = true
+	}
+	var unsupported []string
+	for _, assetType := range azureAssetTypes {
+		if internal.IsTypeEnabled(assetTypes, assetType) && !supported[assetType] {
+			unsupported = append(unsupported, assetType)
+		}
+	}
+	return unsupported
+}
+
+// collectResourceGraphAssets queries Azure Resource Graph once per enabled asset type, across all the given
+// subscriptions, or across every subscription the credentials have access to when none is given.
+// A failing query doesn't prevent collecting the other asset types, the errors of all the queries are returned.
+func collectResourceGraphAssets(ctx context.Context, client resourceGraphClient, subscriptions []string, regions []string, resourceGroups []string, assetTypes []string, log *logp.Logger, publisher stateless.Publisher) error {
+	var nics *azureNetworkInterfaces
+	var errs []error
+	for _, t := range resourceGraphAssetTypes {
+		if !internal.IsTypeEnabled(assetTypes, t.assetType) {
+			continue
+		}
+		if t.needsNetworkInterfaces && nics == nil {
+			var err error
+			nics, err = queryResourceGraphNetworkInterfaces(ctx, client, subscriptions)
+			if err != nil {
+				log.Warnf("Error while retrieving Azure network interfaces, assets are published without their network configuration: %v", err)
+			}
+		}
+		query := getResourceGraphQuery(t, regions, resourceGroups)
+		rows, err := queryResourceGraph(ctx, client, query, subscriptions)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("error collecting %s assets: %w", t.assetType, err))
+			continue
+		}
+		log.Debugf("Publishing %d %s assets from Azure Resource Graph", len(rows), t.assetType)
+		for _, row := range rows {
+			if err := t.publish(row, nics, publisher); err != nil {
+				log.Warnf("Skipping %s asset %s: %v", t.assetType, row.getString("id"), err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// queryResourceGraphNetworkInterfaces indexes the network interfaces and public IPs of all the subscriptions.
+func queryResourceGraphNetworkInterfaces(ctx context.Context, client resourceGraphClient, subscriptions []string) (*azureNetworkInterfaces, error) {
+	nicsQuery := getResourceGraphQuery(resourceGraphAssetType{resourceType: "microsoft.network/networkinterfaces", projection: "id, properties"}, nil, nil)
+	nicRows, err := queryResourceGraph(ctx, client, nicsQuery, subscriptions)
+	if err != nil {
+		return nil, err
+	}
+	interfaces := make([]*armnetwork.Interface, 0, len(nicRows))
+	for _, row := range nicRows {
+		var nic armnetwork.Interface
+		if err := row.decode(&nic); err != nil {
+			return nil, fmt.Errorf("error decoding network interface %s: %w", row.getString("id"), err)
+		}
+		interfaces = append(interfaces, &nic)
+	}
+
+	publicIPsQuery := getResourceGraphQuery(resourceGraphAssetType{resourceType: "microsoft.network/publicipaddresses", projection: "id, properties"}, nil, nil)
+	publicIPRows, err := queryResourceGraph(ctx, client, publicIPsQuery, subscriptions)
+	if err != nil {
+		return nil, err
+	}
+	publicIPs := make([]*armnetwork.PublicIPAddress, 0, len(publicIPRows))
+	for _, row := range publicIPRows {
+		var publicIP armnetwork.PublicIPAddress
+		if err := row.decode(&publicIP); err != nil {
+			return nil, fmt.Errorf("error decoding public IP address %s: %w", row.getString("id"), err)
+		}
+		publicIPs = append(publicIPs, &publicIP)
+	}
+
+	return newAzureNetworkInterfaces(interfaces, publicIPs), nil
+}
+
+// getResourceGraphQuery returns the Kusto query listing the resources of an asset type,
+// filtered on the configured regions and resource groups.
+func getResourceGraphQuery(t resourceGraphAssetType, regions []string, resourceGroups []string) string {
+	table := t.table
+	if table == "" {
+		table = "resources"
+	}
+	clauses := []string{
+		table,
+		"where type =~ " + quoteKustoString(t.resourceType),
+	}
+	if len(regions) > 0 && !t.anyRegion {
+		clauses = append(clauses, "where location in~ ("+quoteKustoStrings(regions)+")")
+	}
+	if len(resourceGroups) > 0 {
+		clauses = append(clauses, "where resourceGroup in~ ("+quoteKustoStrings(resourceGroups)+")")
+	}
+	clauses = append(clauses, "project "+t.projection)
+	return strings.Join(clauses, "\n| ")
+}
+
+// queryResourceGraph runs a query and returns the rows of all its pages.
+func queryResourceGraph(ctx context.Context, client resourceGraphClient, query string, subscriptions []string) ([]resourceGraphRow, error) {
+	request := armresourcegraph.QueryRequest{
+		Query:         to.Ptr(query),
+		Subscriptions: to.SliceOfPtrs(subscriptions...),
+		Options: &armresourcegraph.QueryRequestOptions{
+			ResultFormat: to.Ptr(armresourcegraph.ResultFormatObjectArray),
+			Top:          to.Ptr(int32(resourceGraphPageSize)),
+		},
+	}
+
+	var rows []resourceGraphRow
+	for {
+		resp, err := client.Resources(ctx, request, nil)
+		if err != nil {
+			return nil, fmt.Errorf("error querying Azure Resource Graph: %w", err)
+		}
+		data, ok := resp.Data.([]any)
+		if !ok && resp.Data != nil {
+			return nil, fmt.Errorf("unexpected Azure Resource Graph result format %T", resp.Data)
+		}
+		for _, d := range data {
+			if row, ok := d.(map[string]any); ok {
+				rows = append(rows, row)
+			}
+		}
+		if resp.SkipToken == nil || *resp.SkipToken == "" {
+			return rows, nil
+		}
+		request.Options.SkipToken = resp.SkipToken
+	}
+}
+
+// quoteKustoString returns s as a single-quoted Kusto string literal.
+func quoteKustoString(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(s) + "'"
+}
+
+// quoteKustoStrings returns the items of a Kusto list of string literals.
+func quoteKustoStrings(values []string) string {
+	quoted := make([]string, 0, len(values))
+	for _, v := range values {
+		quoted = append(quoted, quoteKustoString(v))
+	}
+	return strings.Join(quoted, ", ")
+}
+
+func publishResourceGraphResourceGroup(row resourceGraphRow, _ *azureNetworkInterfaces, publisher stateless.Publisher) error {
+	var group armresources.ResourceGroup
+	if err := row.decode(&group); err != nil {
+		return err
+	}
+	if group.ID == nil || group.Location == nil {
+		return fmt.Errorf("missing resource group identifiers")
+	}
+	publishAzureResourceGroup(publisher, row.getString("subscriptionId"), &group)
+	return nil
+}
+
+// publishResourceGraphVMInstance publishes a VM row, which has the same shape as the VMs of the Azure Resource Manager API.
+func publishResourceGraphVMInstance(row resourceGraphRow, nics *azureNetworkInterfaces, publisher stateless.Publisher) error {
+	var vm armcompute.VirtualMachine
+	if err := row.decode(&vm); err != nil {
+		return err
+	}
+	if vm.ID == nil || vm.Name == nil || vm.Location == nil || vm.Properties == nil || vm.Properties.VMID == nil {
+		return fmt.Errorf("missing VM identifiers")
+	}
+	publishAzureVMInstance(publisher, newAzureVMInstance(&vm, row.getString("subscriptionId"), row.getString("state"), nics))
+	return nil
+}
+
+func publishResourceGraphVNet(row resourceGraphRow, _ *azureNetworkInterfaces, publisher stateless.Publisher) error {
+	vnet, err := decodeResourceGraphVNet(row)
+	if err != nil {
+		return err
+	}
+	publishAzureVNet(publisher, row.getString("subscriptionId"), vnet)
+	return nil
+}
+
+func publishResourceGraphSubnets(row resourceGraphRow, _ *azureNetworkInterfaces, publisher stateless.Publisher) error {
+	vnet, err := decodeResourceGraphVNet(row)
+	if err != nil {
+		return err
+	}
+	publishAzureSubnets(publisher, row.getString("subscriptionId"), vnet)
+	return nil
+}
+
+func decodeResourceGraphVNet(row resourceGraphRow) (*armnetwork.VirtualNetwork, error) {
+	var vnet armnetwork.VirtualNetwork
+	if err := row.decode(&vnet); err != nil {
+		return nil, err
+	}
+	if vnet.ID == nil || vnet.Location == nil {
+		return nil, fmt.Errorf("missing virtual network identifiers")
+	}
+	return &vnet, nil
+}
+
+// decode converts a row to the model of the Azure SDK for its resource type.
+func (r resourceGraphRow) decode(v any) error {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+func (r resourceGraphRow) getString(column string) string {
+	s, _ := r[column].(string)
+	return s
+}
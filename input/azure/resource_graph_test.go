@@ -0,0 +1,339 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package azure
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/cloud"
+	azfake "github.com/Azure/azure-sdk-for-go/sdk/azcore/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resourcegraph/armresourcegraph"
+	"github.com/elastic/assetbeat/input/internal"
+	"github.com/elastic/assetbeat/input/testutil"
+	"github.com/elastic/beats/v7/libbeat/beat"
+	"github.com/elastic/elastic-agent-libs/logp"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// resourceGraphQueryRequest is the body of the requests sent to the Azure Resource Graph endpoint.
+type resourceGraphQueryRequest struct {
+	Query         string   `json:"query"`
+	Subscriptions []string `json:"subscriptions"`
+	Options       struct {
+		ResultFormat string `json:"resultFormat"`
+		SkipToken    string `json:"$skipToken"`
+	} `json:"options"`
+}
+
+// newResourceGraphTestClient returns a Resource Graph client sending its requests to a fake endpoint,
+// which answers the query of each resource type with its pages of results keyed by skip token, the first page
+// having none. Resource types without pages have no results, and no request succeeds when pages is nil.
+func newResourceGraphTestClient(t *testing.T, pages map[string]map[string]map[string]any, requests *[]resourceGraphQueryRequest) *armresourcegraph.Client {
+	resourceTypeRegexp := regexp.MustCompile(`type =~ '([^']+)'`)
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request resourceGraphQueryRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil || pages == nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		*requests = append(*requests, request)
+		var resourceType string
+		if match := resourceTypeRegexp.FindStringSubmatch(request.Query); match != nil {
+			resourceType = match[1]
+		}
+		page := map[string]any{"count": 0, "totalRecords": 0, "resultTruncated": "false", "data": []any{}}
+		if typePages, ok := pages[resourceType]; ok {
+			if page, ok = typePages[request.Options.SkipToken]; !ok {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(page)
+	}))
+	t.Cleanup(srv.Close)
+
+	client, err := armresourcegraph.NewClient(azfake.NewTokenCredential(), &arm.ClientOptions{
+		ClientOptions: azcore.ClientOptions{
+			Cloud: cloud.Configuration{
+				Services: map[cloud.ServiceName]cloud.ServiceConfiguration{
+					cloud.ResourceManager: {Endpoint: srv.URL, Audience: "https://management.azure.com"},
+				},
+			},
+			Retry:     policy.RetryOptions{MaxRetries: -1},
+			Transport: srv.Client(),
+		},
+	})
+	require.NoError(t, err)
+	return client
+}
+
+func TestAssetsAzure_collectResourceGraphAssets(t *testing.T) {
+	pages := map[string]map[string]map[string]any{
+		"microsoft.compute/virtualmachines": {
+			"": {
+				"count":           1,
+				"totalRecords":    2,
+				"resultTruncated": "false",
+				"$skipToken":      "page2",
+				"data": []map[string]any{
+					{
+						"id":             instanceid1,
+						"name":           instance1Name,
+						"location":       "westeurope",
+						"subscriptionId": subscriptionId,
+						"tags":           map[string]any{"env": "test"},
+						"zones":          []string{"1"},
+						"properties": map[string]any{
+							"vmId":            instanceVMId1,
+							"hardwareProfile": map[string]any{"vmSize": "Standard_B1s"},
+							"storageProfile": map[string]any{
+								"osDisk":         map[string]any{"osType": "Linux", "managedDisk": map[string]any{"id": osDiskID1}},
+								"imageReference": map[string]any{"publisher": "Canonical", "offer": "0001-com-ubuntu-server-jammy", "sku": "22_04-lts-gen2", "version": "latest"},
+							},
+							"networkProfile": map[string]any{"networkInterfaces": []map[string]any{{"id": nicID1}}},
+						},
+						"state": "VM running",
+					},
+				},
+			},
+			"page2": {
+				"count":           1,
+				"totalRecords":    2,
+				"resultTruncated": "false",
+				"data": []map[string]any{
+					{
+						"id":             instanceIdDiffResourceGroup,
+						"name":           instance4Name,
+						"location":       "northeurope",
+						"subscriptionId": subscriptionId,
+						"tags":           nil,
+						"zones":          nil,
+						"properties":     map[string]any{"vmId": instanceVMId4},
+						"state":          "VM deallocated",
+					},
+				},
+			},
+		},
+		"microsoft.network/networkinterfaces": {
+			"": {
+				"data": []map[string]any{
+					{
+						"id": nicID1,
+						"properties": map[string]any{
+							"ipConfigurations": []map[string]any{
+								{
+									"properties": map[string]any{
+										"privateIPAddress": "10.0.0.4",
+										"publicIPAddress":  map[string]any{"id": publicIPID1},
+										"subnet":           map[string]any{"id": subnetID1},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		"microsoft.network/publicipaddresses": {
+			"": {
+				"data": []map[string]any{
+					{"id": publicIPID1, "properties": map[string]any{"ipAddress": "20.0.0.1"}},
+				},
+			},
+		},
+	}
+	var requests []resourceGraphQueryRequest
+	client := newResourceGraphTestClient(t, pages, &requests)
+
+	expectedEvents := []beat.Event{
+		{
+			Fields: mapstr.M{
+				"asset.ean":                           "host:" + instanceVMId1,
+				"asset.id":                            instanceVMId1,
+				"asset.name":                          instance1Name,
+				"asset.type":                          "azure.vm.instance",
+				"asset.kind":                          "host",
+				"asset.parents":                       []string{subnetEAN1, vnetEAN1, resourceGroupEAN1},
+				"asset.metadata.state":                "VM running",
+				"asset.metadata.resource_group":       resourceGroup1,
+				"asset.metadata.tags.env":             "test",
+				"asset.metadata.zones":                []string{"1"},
+				"asset.metadata.size":                 "Standard_B1s",
+				"asset.metadata.os_type":              "Linux",
+				"asset.metadata.image.publisher":      "Canonical",
+				"asset.metadata.image.offer":          "0001-com-ubuntu-server-jammy",
+				"asset.metadata.image.sku":            "22_04-lts-gen2",
+				"asset.metadata.image.version":        "latest",
+				"asset.metadata.disks":                []string{osDiskID1},
+				"asset.metadata.private_ip_addresses": []string{"10.0.0.4"},
+				"asset.metadata.public_ip_addresses":  []string{"20.0.0.1"},
+				"cloud.account.id":                    subscriptionId,
+				"cloud.provider":                      "azure",
+				"cloud.region":                        "westeurope",
+			},
+			Meta: mapstr.M{
+				"index": internal.GetDefaultIndexName(),
+			},
+		},
+		{
+			Fields: mapstr.M{
+				"asset.ean":                     "host:" + instanceVMId4,
+				"asset.id":                      instanceVMId4,
+				"asset.name":                    instance4Name,
+				"asset.type":                    "azure.vm.instance",
+				"asset.parents":                 []string{resourceGroupEAN2},
+				"asset.kind":                    "host",
+				"asset.metadata.state":          "VM deallocated",
+				"asset.metadata.resource_group": resourceGroup2,
+				"cloud.account.id":              subscriptionId,
+				"cloud.provider":                "azure",
+				"cloud.region":                  "northeurope",
+			},
+			Meta: mapstr.M{
+				"index": internal.GetDefaultIndexName(),
+			},
+		},
+	}
+
+	publisher := testutil.NewInMemoryPublisher()
+	log := logp.NewLogger("test")
+	err := collectResourceGraphAssets(context.Background(), client, []string{subscriptionId}, nil, nil, nil, log, publisher)
+	assert.NoError(t, err)
+	assert.Equal(t, expectedEvents, publisher.Events)
+
+	var vmRequests []resourceGraphQueryRequest
+	for _, request := range requests {
+		assert.Equal(t, []string{subscriptionId}, request.Subscriptions)
+		assert.Equal(t, "objectArray", request.Options.ResultFormat)
+		if strings.Contains(request.Query, "where type =~ 'microsoft.compute/virtualmachines'") {
+			vmRequests = append(vmRequests, request)
+		}
+	}
+	require.Len(t, vmRequests, 2)
+	assert.Equal(t, "page2", vmRequests[1].Options.SkipToken)
+}
+
+func TestAssetsAzure_collectResourceGraphAssets_vnets(t *testing.T) {
+	row := func(vnet map[string]any) map[string]any {
+		r := map[string]any{"subscriptionId": subscriptionId}
+		for k, v := range vnet {
+			r[k] = v
+		}
+		return r
+	}
+	pages := map[string]map[string]map[string]any{
+		"microsoft.network/virtualnetworks": {
+			"": {"data": []map[string]any{row(vnet1), row(vnet2)}},
+		},
+	}
+	var requests []resourceGraphQueryRequest
+	client := newResourceGraphTestClient(t, pages, &requests)
+
+	publisher := testutil.NewInMemoryPublisher()
+	log := logp.NewLogger("test")
+	err := collectResourceGraphAssets(context.Background(), client, nil, nil, nil, []string{"azure.vnet", "azure.subnet"}, log, publisher)
+	assert.NoError(t, err)
+	assert.Equal(t, []beat.Event{expectedVNet1Event, expectedVNet2Event, expectedSubnet1Event}, publisher.Events)
+	assert.Len(t, requests, 2)
+}
+
+func TestAssetsAzure_collectResourceGraphAssets_error(t *testing.T) {
+	var requests []resourceGraphQueryRequest
+	client := newResourceGraphTestClient(t, nil, &requests)
+
+	publisher := testutil.NewInMemoryPublisher()
+	log := logp.NewLogger("test")
+	err := collectResourceGraphAssets(context.Background(), client, nil, nil, nil, []string{"azure.vm.instance"}, log, publisher)
+	assert.ErrorContains(t, err, "error collecting azure.vm.instance assets: error querying Azure Resource Graph")
+	assert.Empty(t, publisher.Events)
+}
+
+func TestAssetsAzure_collectResourceGraphAssets_partialError(t *testing.T) {
+	pages := map[string]map[string]map[string]any{
+		// the first page of VMs is missing, their query fails
+		"microsoft.compute/virtualmachines": {"page2": {"data": []map[string]any{}}},
+		"microsoft.network/virtualnetworks": {
+			"": {"data": []map[string]any{{"id": vnetID2, "name": "vnet2", "location": "northeurope", "subscriptionId": subscriptionId, "properties": vnet2["properties"]}}},
+		},
+	}
+	var requests []resourceGraphQueryRequest
+	client := newResourceGraphTestClient(t, pages, &requests)
+
+	publisher := testutil.NewInMemoryPublisher()
+	log := logp.NewLogger("test")
+	err := collectResourceGraphAssets(context.Background(), client, nil, nil, nil, []string{"azure.vm.instance", "azure.vnet"}, log, publisher)
+	assert.ErrorContains(t, err, "error collecting azure.vm.instance assets")
+	assert.Equal(t, []beat.Event{expectedVNet2Event}, publisher.Events)
+}
+
+func TestGetResourceGraphUnsupportedAssetTypes(t *testing.T) {
+	assert.Equal(t, []string{
+		"azure.vmss",
+		"azure.vmss.instance",
+		"k8s.cluster",
+		"azure.storage.account",
+		"azure.sql.server",
+		"azure.sql.database",
+		"azure.app_service.plan",
+		"azure.web_app",
+	}, getResourceGraphUnsupportedAssetTypes(nil))
+	assert.Equal(t, []string{"azure.vmss.instance"}, getResourceGraphUnsupportedAssetTypes([]string{"azure.vm.instance", "azure.vmss.instance"}))
+	assert.Empty(t, getResourceGraphUnsupportedAssetTypes([]string{"azure.vm.instance", "azure.subnet"}))
+}
+
+func TestGetResourceGraphQuery(t *testing.T) {
+	vmType := resourceGraphAssetTypes[0]
+	for _, tt := range []struct {
+		name           string
+		regions        []string
+		resourceGroups []string
+		expectedQuery  string
+	}{
+		{
+			name: "without filters",
+			expectedQuery: "resources\n" +
+				"| where type =~ 'microsoft.compute/virtualmachines'\n" +
+				"| project " + vmType.projection,
+		},
+		{
+			name:           "with regions and resource groups",
+			regions:        []string{"westeurope", "northeurope"},
+			resourceGroups: []string{"it's-mine", "TESTVM"},
+			expectedQuery: "resources\n" +
+				"| where type =~ 'microsoft.compute/virtualmachines'\n" +
+				"| where location in~ ('westeurope', 'northeurope')\n" +
+				"| where resourceGroup in~ ('it\\'s-mine', 'TESTVM')\n" +
+				"| project " + vmType.projection,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expectedQuery, getResourceGraphQuery(vmType, tt.regions, tt.resourceGroups))
+		})
+	}
+}
@@ -0,0 +1,113 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package azure
+
+import (
+	"context"
+	"fmt"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	"github.com/elastic/assetbeat/input/internal"
+	stateless "github.com/elastic/beats/v7/filebeat/input/v2/input-stateless"
+	"github.com/elastic/elastic-agent-libs/logp"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+	"strings"
+)
+
+// collectAzureResourceGroupAssets publishes the resource groups of a subscription. They are not filtered by region:
+// the location of a resource group is only where its metadata is stored, its resources can be in any region.
+func collectAzureResourceGroupAssets(ctx context.Context, client *armresources.ResourceGroupsClient, subscriptionId string, resourceGroups []string, log *logp.Logger, publisher stateless.Publisher) error {
+	groups, err := getAllAzureResourceGroups(ctx, client, resourceGroups)
+	if err != nil {
+		return err
+	}
+
+	log.Debug("Publishing Azure resource groups")
+
+	for _, group := range groups {
+		publishAzureResourceGroup(publisher, subscriptionId, group)
+	}
+
+	return nil
+}
+
+func getAllAzureResourceGroups(ctx context.Context, client *armresources.ResourceGroupsClient, resourceGroups []string) ([]*armresources.ResourceGroup, error) {
+	var groups []*armresources.ResourceGroup
+	pager := client.NewListPager(nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to advance page: %v", err)
+		}
+		for _, v := range page.Value {
+			if v.ID != nil && v.Location != nil && wantResourceGroup(*v.ID, resourceGroups) {
+				groups = append(groups, v)
+			}
+		}
+	}
+	return groups, nil
+}
+
+// publishAzureResourceGroup publishes a resource group, whichever backend it was collected with.
+func publishAzureResourceGroup(publisher stateless.Publisher, subscriptionId string, group *armresources.ResourceGroup) {
+	assetType := "azure.resource_group"
+	assetKind := "resource_group"
+
+	metadata := mapstr.M{}
+	if group.ManagedBy != nil {
+		metadata["managed_by"] = *group.ManagedBy
+	}
+	if group.Properties != nil && group.Properties.ProvisioningState != nil {
+		metadata["provisioning_state"] = *group.Properties.ProvisioningState
+	}
+
+	internal.Publish(publisher, nil,
+		internal.WithAssetCloudProvider("azure"),
+		internal.WithAssetRegion(*group.Location),
+		internal.WithAssetAccountID(subscriptionId),
+		internal.WithAssetKindAndID(assetKind, getAzureResourceGroupID(*group.ID)),
+		internal.WithAssetType(assetType),
+		internal.WithAssetName(stringValue(group.Name)),
+		WithAssetTags(flattenAzureTags(group.Tags)),
+		internal.WithAssetMetadata(metadata),
+	)
+}
+
+// azureEAN returns the EAN of an asset identified by its Azure resource ID. Resource IDs are case-insensitive
+// and their casing differs between the Azure APIs, so assets are published with their lowercase resource ID,
+// and the EANs referencing them are lowercased to match.
+func azureEAN(kind string, id string) string {
+	return kind + ":" + strings.ToLower(id)
+}
+
+// getAzureResourceGroupID returns the ID of the resource group of a resource, or of a resource group itself.
+// It is lowercased, as the casing of resource group names differs between the Azure APIs.
+func getAzureResourceGroupID(id string) string {
+	s := strings.Split(id, "/")
+	if len(s) < 5 {
+		return ""
+	}
+	return strings.ToLower(strings.Join(s[:5], "/"))
+}
+
+// withAzureResourceGroupParent appends the EAN of the resource group of a resource to its other parents.
+func withAzureResourceGroupParent(id string, parents []string) []string {
+	if resourceGroupID := getAzureResourceGroupID(id); resourceGroupID != "" {
+		parents = append(parents, azureEAN("resource_group", resourceGroupID))
+	}
+	return parents
+}
@@ -0,0 +1,165 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package azure
+
+import (
+	"context"
+	"fmt"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	azfake "github.com/Azure/azure-sdk-for-go/sdk/azcore/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	"github.com/elastic/assetbeat/input/internal"
+	"github.com/elastic/assetbeat/input/testutil"
+	"github.com/elastic/beats/v7/libbeat/beat"
+	"github.com/elastic/elastic-agent-libs/logp"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+var resourceGroupID1 = fmt.Sprintf("/subscriptions/%s/resourceGroups/%s", subscriptionId, resourceGroup1)
+var resourceGroupID2 = fmt.Sprintf("/subscriptions/%s/resourceGroups/%s", subscriptionId, resourceGroup2)
+
+// resource groups are related to the resources they contain by their lowercased IDs
+var resourceGroupEAN1 = "resource_group:" + strings.ToLower(resourceGroupID1)
+var resourceGroupEAN2 = "resource_group:" + strings.ToLower(resourceGroupID2)
+
+// resourceGroup1JSON has the JSON shape of the resource groups of both the Azure Resource Manager API and Azure Resource Graph.
+var resourceGroup1JSON = map[string]any{
+	"id":         resourceGroupID1,
+	"name":       resourceGroup1,
+	"location":   "westeurope",
+	"tags":       map[string]any{"env": "test"},
+	"properties": map[string]any{"provisioningState": "Succeeded"},
+}
+
+var resourceGroup2JSON = map[string]any{
+	"id":         resourceGroupID2,
+	"name":       resourceGroup2,
+	"location":   "northeurope",
+	"managedBy":  aksClusterID1,
+	"properties": map[string]any{"provisioningState": "Succeeded"},
+}
+
+var expectedResourceGroup1Event = beat.Event{
+	Fields: mapstr.M{
+		"asset.ean":                         resourceGroupEAN1,
+		"asset.id":                          strings.ToLower(resourceGroupID1),
+		"asset.name":                        resourceGroup1,
+		"asset.type":                        "azure.resource_group",
+		"asset.kind":                        "resource_group",
+		"asset.metadata.provisioning_state": "Succeeded",
+		"asset.metadata.tags.env":           "test",
+		"cloud.account.id":                  subscriptionId,
+		"cloud.provider":                    "azure",
+		"cloud.region":                      "westeurope",
+	},
+	Meta: mapstr.M{
+		"index": internal.GetDefaultIndexName(),
+	},
+}
+
+var expectedResourceGroup2Event = beat.Event{
+	Fields: mapstr.M{
+		"asset.ean":                         resourceGroupEAN2,
+		"asset.id":                          strings.ToLower(resourceGroupID2),
+		"asset.name":                        resourceGroup2,
+		"asset.type":                        "azure.resource_group",
+		"asset.kind":                        "resource_group",
+		"asset.metadata.managed_by":         aksClusterID1,
+		"asset.metadata.provisioning_state": "Succeeded",
+		"cloud.account.id":                  subscriptionId,
+		"cloud.provider":                    "azure",
+		"cloud.region":                      "northeurope",
+	},
+	Meta: mapstr.M{
+		"index": internal.GetDefaultIndexName(),
+	},
+}
+
+func TestAssetsAzure_collectAzureResourceGroupAssets(t *testing.T) {
+	for _, tt := range []struct {
+		name           string
+		resourceGroups []string
+		expectedEvents []beat.Event
+	}{
+		{
+			name:           "all resource groups",
+			expectedEvents: []beat.Event{expectedResourceGroup1Event, expectedResourceGroup2Event},
+		},
+		{
+			name:           "resource groups with another case",
+			resourceGroups: []string{strings.ToLower(resourceGroup1), "other"},
+			expectedEvents: []beat.Event{expectedResourceGroup1Event},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			publisher := testutil.NewInMemoryPublisher()
+			client, err := armresources.NewResourceGroupsClient(subscriptionId, azfake.NewTokenCredential(), &arm.ClientOptions{
+				ClientOptions: azcore.ClientOptions{
+					Transport: fakeTransport(func(req *http.Request) (*http.Response, error) {
+						return newJSONResponse(req, map[string]any{"value": []any{resourceGroup1JSON, resourceGroup2JSON}})
+					}),
+				},
+			})
+			assert.NoError(t, err)
+
+			err = collectAzureResourceGroupAssets(context.Background(), client, subscriptionId, tt.resourceGroups, logp.NewLogger("test"), publisher)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedEvents, publisher.Events)
+		})
+	}
+}
+
+func TestAssetsAzure_collectResourceGraphAssets_resourceGroups(t *testing.T) {
+	row := func(group map[string]any) map[string]any {
+		r := map[string]any{"subscriptionId": subscriptionId}
+		for k, v := range group {
+			r[k] = v
+		}
+		return r
+	}
+	pages := map[string]map[string]map[string]any{
+		"microsoft.resources/subscriptions/resourcegroups": {
+			"": {"data": []map[string]any{row(resourceGroup1JSON), row(resourceGroup2JSON)}},
+		},
+	}
+	var requests []resourceGraphQueryRequest
+	client := newResourceGraphTestClient(t, pages, &requests)
+
+	publisher := testutil.NewInMemoryPublisher()
+	log := logp.NewLogger("test")
+	// the resources of a resource group can be in any region, whatever its own location
+	err := collectResourceGraphAssets(context.Background(), client, nil, []string{"northeurope"}, nil, []string{"azure.resource_group"}, log, publisher)
+	assert.NoError(t, err)
+	assert.Equal(t, []beat.Event{expectedResourceGroup1Event, expectedResourceGroup2Event}, publisher.Events)
+	if assert.Len(t, requests, 1) {
+		assert.True(t, strings.HasPrefix(requests[0].Query, "resourcecontainers\n"))
+		assert.NotContains(t, requests[0].Query, "where location")
+	}
+}
+
+func TestGetAzureResourceGroupID(t *testing.T) {
+	assert.Equal(t, strings.ToLower(resourceGroupID1), getAzureResourceGroupID(resourceGroupID1))
+	assert.Equal(t, strings.ToLower(resourceGroupID1), getAzureResourceGroupID(instanceid1))
+	assert.Equal(t, strings.ToLower(resourceGroupID1), getAzureResourceGroupID(strings.ToLower(vnetID1)))
+	assert.Equal(t, "", getAzureResourceGroupID("/subscriptions/"+subscriptionId))
+}
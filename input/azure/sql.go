@@ -0,0 +1,233 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package azure
+
+import (
+	"context"
+	"fmt"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/sql/armsql"
+	"github.com/elastic/assetbeat/input/internal"
+	stateless "github.com/elastic/beats/v7/filebeat/input/v2/input-stateless"
+	"github.com/elastic/elastic-agent-libs/logp"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+	"strings"
+)
+
+// collectAzureSQLAssets publishes the SQL servers of a subscription and their databases.
+func collectAzureSQLAssets(ctx context.Context, serversClient *armsql.ServersClient, databasesClient *armsql.DatabasesClient, vnetRulesClient *armsql.VirtualNetworkRulesClient, subscriptionId string, regions []string, resourceGroups []string, assetTypes []string, log *logp.Logger, publisher stateless.Publisher) error {
+	servers, err := getAllAzureSQLServers(ctx, serversClient, regions, resourceGroups)
+	if err != nil {
+		return err
+	}
+
+	log.Debug("Publishing Azure SQL servers")
+
+	for _, server := range servers {
+		serverResourceGroup := getResourceGroupFromId(*server.ID)
+		databases, err := getAzureSQLDatabases(ctx, databasesClient, serverResourceGroup, *server.Name)
+		if err != nil {
+			log.Errorf("Error while retrieving the databases of SQL server %s: %v", *server.ID, err)
+		}
+		if internal.IsTypeEnabled(assetTypes, "azure.sql.server") {
+			subnetIDs, err := getAzureSQLServerSubnetIDs(ctx, vnetRulesClient, serverResourceGroup, *server.Name)
+			if err != nil {
+				log.Warnf("Error while retrieving the virtual network rules of SQL server %s, it is published without them: %v", *server.ID, err)
+			}
+			publishAzureSQLServer(publisher, subscriptionId, server, databases, subnetIDs)
+		}
+		if internal.IsTypeEnabled(assetTypes, "azure.sql.database") {
+			for _, database := range databases {
+				publishAzureSQLDatabase(publisher, subscriptionId, *server.ID, database)
+			}
+		}
+	}
+
+	return nil
+}
+
+func getAllAzureSQLServers(ctx context.Context, client *armsql.ServersClient, regions []string, resourceGroups []string) ([]*armsql.Server, error) {
+	var servers []*armsql.Server
+	pager := client.NewListPager(nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to advance page: %v", err)
+		}
+		for _, v := range page.Value {
+			if v.ID != nil && v.Name != nil && v.Location != nil && wantRegion(*v.Location, regions) && wantResourceGroup(*v.ID, resourceGroups) {
+				servers = append(servers, v)
+			}
+		}
+	}
+	return servers, nil
+}
+
+// getAzureSQLDatabases lists the user databases of a SQL server, without the master database.
+func getAzureSQLDatabases(ctx context.Context, client *armsql.DatabasesClient, resourceGroup string, serverName string) ([]*armsql.Database, error) {
+	var databases []*armsql.Database
+	pager := client.NewListByServerPager(resourceGroup, serverName, nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to advance page: %v", err)
+		}
+		for _, v := range page.Value {
+			if v.ID != nil && v.Location != nil && !isAzureSQLSystemDatabase(v) {
+				databases = append(databases, v)
+			}
+		}
+	}
+	return databases, nil
+}
+
+// isAzureSQLSystemDatabase tells whether a database is a system database, of kind "v12.0,system".
+func isAzureSQLSystemDatabase(database *armsql.Database) bool {
+	if database.Kind == nil {
+		return false
+	}
+	for _, kind := range strings.Split(*database.Kind, ",") {
+		if kind == "system" {
+			return true
+		}
+	}
+	return false
+}
+
+// getAzureSQLServerSubnetIDs returns the IDs of the subnets granted access to a SQL server through virtual network rules.
+func getAzureSQLServerSubnetIDs(ctx context.Context, client *armsql.VirtualNetworkRulesClient, resourceGroup string, serverName string) ([]string, error) {
+	var subnetIDs []string
+	pager := client.NewListByServerPager(resourceGroup, serverName, nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to advance page: %v", err)
+		}
+		for _, rule := range page.Value {
+			if rule.Properties != nil && rule.Properties.VirtualNetworkSubnetID != nil {
+				subnetIDs = append(subnetIDs, *rule.Properties.VirtualNetworkSubnetID)
+			}
+		}
+	}
+	return subnetIDs, nil
+}
+
+func publishAzureSQLServer(publisher stateless.Publisher, subscriptionId string, server *armsql.Server, databases []*armsql.Database, subnetIDs []string) {
+	assetType := "azure.sql.server"
+	assetKind := "database_server"
+
+	metadata := mapstr.M{
+		"resource_group": getResourceGroupFromId(*server.ID),
+	}
+	if server.Kind != nil {
+		metadata["kind"] = *server.Kind
+	}
+	if props := server.Properties; props != nil {
+		if props.State != nil {
+			metadata["state"] = *props.State
+		}
+		if props.Version != nil {
+			metadata["version"] = *props.Version
+		}
+		if props.FullyQualifiedDomainName != nil {
+			metadata["fqdn"] = *props.FullyQualifiedDomainName
+		}
+		if props.PublicNetworkAccess != nil {
+			metadata["public_network_access"] = string(*props.PublicNetworkAccess)
+		}
+	}
+	// virtual network rules only grant subnets access to the SQL server, which is not attached to them
+	if len(subnetIDs) > 0 {
+		metadata["network_rules"] = mapstr.M{"subnet_ids": subnetIDs}
+	}
+
+	var children []string
+	for _, database := range databases {
+		children = append(children, azureEAN("database", *database.ID))
+	}
+
+	options := []internal.AssetOption{
+		internal.WithAssetCloudProvider("azure"),
+		internal.WithAssetRegion(*server.Location),
+		internal.WithAssetAccountID(subscriptionId),
+		internal.WithAssetKindAndID(assetKind, strings.ToLower(*server.ID)),
+		internal.WithAssetType(assetType),
+		internal.WithAssetName(*server.Name),
+		WithAssetTags(flattenAzureTags(server.Tags)),
+		internal.WithAssetMetadata(metadata),
+	}
+	if parents := withAzureResourceGroupParent(*server.ID, nil); len(parents) > 0 {
+		options = append(options, internal.WithAssetParents(parents))
+	}
+	if len(children) > 0 {
+		options = append(options, internal.WithAssetChildren(children))
+	}
+	internal.Publish(publisher, nil, options...)
+}
+
+func publishAzureSQLDatabase(publisher stateless.Publisher, subscriptionId string, serverID string, database *armsql.Database) {
+	assetType := "azure.sql.database"
+	assetKind := "database"
+
+	metadata := mapstr.M{
+		"resource_group": getResourceGroupFromId(*database.ID),
+	}
+	if database.Kind != nil {
+		metadata["kind"] = *database.Kind
+	}
+	if sku := database.SKU; sku != nil {
+		skuMetadata := mapstr.M{}
+		if sku.Name != nil {
+			skuMetadata["name"] = *sku.Name
+		}
+		if sku.Tier != nil {
+			skuMetadata["tier"] = *sku.Tier
+		}
+		if sku.Capacity != nil {
+			skuMetadata["capacity"] = *sku.Capacity
+		}
+		if len(skuMetadata) > 0 {
+			metadata["sku"] = skuMetadata
+		}
+	}
+	if props := database.Properties; props != nil {
+		if props.Status != nil {
+			metadata["state"] = string(*props.Status)
+		}
+		if props.MaxSizeBytes != nil {
+			metadata["max_size_bytes"] = *props.MaxSizeBytes
+		}
+		if props.ZoneRedundant != nil {
+			metadata["zone_redundant"] = *props.ZoneRedundant
+		}
+		if props.ElasticPoolID != nil {
+			metadata["elastic_pool_id"] = *props.ElasticPoolID
+		}
+	}
+
+	internal.Publish(publisher, nil,
+		internal.WithAssetCloudProvider("azure"),
+		internal.WithAssetRegion(*database.Location),
+		internal.WithAssetAccountID(subscriptionId),
+		internal.WithAssetKindAndID(assetKind, strings.ToLower(*database.ID)),
+		internal.WithAssetType(assetType),
+		internal.WithAssetName(stringValue(database.Name)),
+		internal.WithAssetParents([]string{azureEAN("database_server", serverID)}),
+		WithAssetTags(flattenAzureTags(database.Tags)),
+		internal.WithAssetMetadata(metadata),
+	)
+}
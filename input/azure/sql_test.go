@@ -0,0 +1,214 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package azure
+
+import (
+	"context"
+	"fmt"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	azfake "github.com/Azure/azure-sdk-for-go/sdk/azcore/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/sql/armsql"
+	"github.com/elastic/assetbeat/input/internal"
+	"github.com/elastic/assetbeat/input/testutil"
+	"github.com/elastic/beats/v7/libbeat/beat"
+	"github.com/elastic/elastic-agent-libs/logp"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+var sqlServerID1 = fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Sql/servers/sqlserver1", subscriptionId, resourceGroup1)
+var sqlServerID2 = fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Sql/servers/sqlserver2", subscriptionId, resourceGroup2)
+var sqlDatabaseID1 = sqlServerID1 + "/databases/db1"
+
+var sqlServer1 = armsql.Server{
+	ID:       to.Ptr(sqlServerID1),
+	Name:     to.Ptr("sqlserver1"),
+	Location: to.Ptr("westeurope"),
+	Kind:     to.Ptr("v12.0"),
+	Tags:     map[string]*string{"env": to.Ptr("test")},
+	Properties: &armsql.ServerProperties{
+		State:                    to.Ptr("Ready"),
+		Version:                  to.Ptr("12.0"),
+		FullyQualifiedDomainName: to.Ptr("sqlserver1.database.windows.net"),
+		PublicNetworkAccess:      to.Ptr(armsql.ServerNetworkAccessFlagDisabled),
+	},
+}
+
+var sqlServer2 = armsql.Server{
+	ID:       to.Ptr(sqlServerID2),
+	Name:     to.Ptr("sqlserver2"),
+	Location: to.Ptr("northeurope"),
+}
+
+var sqlDatabase1 = armsql.Database{
+	ID:       to.Ptr(sqlDatabaseID1),
+	Name:     to.Ptr("db1"),
+	Location: to.Ptr("westeurope"),
+	Kind:     to.Ptr("v12.0,user"),
+	SKU:      &armsql.SKU{Name: to.Ptr("GP_Gen5"), Tier: to.Ptr("GeneralPurpose"), Capacity: to.Ptr[int32](2)},
+	Properties: &armsql.DatabaseProperties{
+		Status:        to.Ptr(armsql.DatabaseStatusOnline),
+		MaxSizeBytes:  to.Ptr[int64](34359738368),
+		ZoneRedundant: to.Ptr(false),
+	},
+}
+
+var sqlMasterDatabase = armsql.Database{
+	ID:       to.Ptr(sqlServerID1 + "/databases/master"),
+	Name:     to.Ptr("master"),
+	Location: to.Ptr("westeurope"),
+	Kind:     to.Ptr("v12.0,system"),
+}
+
+var expectedSQLServer1Event = beat.Event{
+	Fields: mapstr.M{
+		"asset.ean":     "database_server:" + strings.ToLower(sqlServerID1),
+		"asset.id":      strings.ToLower(sqlServerID1),
+		"asset.name":    "sqlserver1",
+		"asset.type":    "azure.sql.server",
+		"asset.kind":    "database_server",
+		"asset.parents": []string{resourceGroupEAN1},
+		"asset.metadata.network_rules.subnet_ids": []string{subnetID1},
+		"asset.children":                       []string{"database:" + strings.ToLower(sqlDatabaseID1)},
+		"asset.metadata.resource_group":        resourceGroup1,
+		"asset.metadata.kind":                  "v12.0",
+		"asset.metadata.state":                 "Ready",
+		"asset.metadata.version":               "12.0",
+		"asset.metadata.fqdn":                  "sqlserver1.database.windows.net",
+		"asset.metadata.public_network_access": "Disabled",
+		"asset.metadata.tags.env":              "test",
+		"cloud.account.id":                     subscriptionId,
+		"cloud.provider":                       "azure",
+		"cloud.region":                         "westeurope",
+	},
+	Meta: mapstr.M{
+		"index": internal.GetDefaultIndexName(),
+	},
+}
+
+var expectedSQLDatabase1Event = beat.Event{
+	Fields: mapstr.M{
+		"asset.ean":                     "database:" + strings.ToLower(sqlDatabaseID1),
+		"asset.id":                      strings.ToLower(sqlDatabaseID1),
+		"asset.name":                    "db1",
+		"asset.type":                    "azure.sql.database",
+		"asset.kind":                    "database",
+		"asset.parents":                 []string{"database_server:" + strings.ToLower(sqlServerID1)},
+		"asset.metadata.resource_group": resourceGroup1,
+		"asset.metadata.kind":           "v12.0,user",
+		"asset.metadata.sku.name":       "GP_Gen5",
+		"asset.metadata.sku.tier":       "GeneralPurpose",
+		"asset.metadata.sku.capacity":   int32(2),
+		"asset.metadata.state":          "Online",
+		"asset.metadata.max_size_bytes": int64(34359738368),
+		"asset.metadata.zone_redundant": false,
+		"cloud.account.id":              subscriptionId,
+		"cloud.provider":                "azure",
+		"cloud.region":                  "westeurope",
+	},
+	Meta: mapstr.M{
+		"index": internal.GetDefaultIndexName(),
+	},
+}
+
+var expectedSQLServer2Event = beat.Event{
+	Fields: mapstr.M{
+		"asset.ean":                     "database_server:" + strings.ToLower(sqlServerID2),
+		"asset.id":                      strings.ToLower(sqlServerID2),
+		"asset.name":                    "sqlserver2",
+		"asset.type":                    "azure.sql.server",
+		"asset.parents":                 []string{resourceGroupEAN2},
+		"asset.kind":                    "database_server",
+		"asset.metadata.resource_group": resourceGroup2,
+		"cloud.account.id":              subscriptionId,
+		"cloud.provider":                "azure",
+		"cloud.region":                  "northeurope",
+	},
+	Meta: mapstr.M{
+		"index": internal.GetDefaultIndexName(),
+	},
+}
+
+// sqlTransport answers the requests listing the SQL servers of the subscription, and the databases and
+// virtual network rules of sqlserver1.
+var sqlTransport = fakeTransport(func(req *http.Request) (*http.Response, error) {
+	path := strings.ToLower(req.URL.Path)
+	switch {
+	case strings.HasSuffix(path, "/providers/microsoft.sql/servers"):
+		return newJSONResponse(req, map[string]any{"value": []any{sqlServer1, sqlServer2}})
+	case strings.HasSuffix(path, "/servers/sqlserver1/databases"):
+		return newJSONResponse(req, map[string]any{"value": []any{sqlMasterDatabase, sqlDatabase1}})
+	case strings.HasSuffix(path, "/servers/sqlserver1/virtualnetworkrules"):
+		return newJSONResponse(req, map[string]any{"value": []any{
+			armsql.VirtualNetworkRule{Properties: &armsql.VirtualNetworkRuleProperties{VirtualNetworkSubnetID: to.Ptr(subnetID1)}},
+		}})
+	default:
+		return newJSONResponse(req, map[string]any{"value": []any{}})
+	}
+})
+
+func TestAssetsAzure_collectAzureSQLAssets(t *testing.T) {
+	for _, tt := range []struct {
+		name           string
+		regions        []string
+		resourceGroups []string
+		assetTypes     []string
+		expectedEvents []beat.Event
+	}{
+		{
+			name:           "all asset types",
+			expectedEvents: []beat.Event{expectedSQLServer1Event, expectedSQLDatabase1Event, expectedSQLServer2Event},
+		},
+		{
+			name:           "servers only",
+			assetTypes:     []string{"azure.sql.server"},
+			expectedEvents: []beat.Event{expectedSQLServer1Event, expectedSQLServer2Event},
+		},
+		{
+			name:           "databases only, in a region",
+			regions:        []string{"westeurope"},
+			assetTypes:     []string{"azure.sql.database"},
+			expectedEvents: []beat.Event{expectedSQLDatabase1Event},
+		},
+		{
+			name:           "in a resource group",
+			resourceGroups: []string{resourceGroup2},
+			expectedEvents: []beat.Event{expectedSQLServer2Event},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			publisher := testutil.NewInMemoryPublisher()
+			options := &arm.ClientOptions{ClientOptions: azcore.ClientOptions{Transport: sqlTransport}}
+			serversClient, err := armsql.NewServersClient(subscriptionId, azfake.NewTokenCredential(), options)
+			assert.NoError(t, err)
+			databasesClient, err := armsql.NewDatabasesClient(subscriptionId, azfake.NewTokenCredential(), options)
+			assert.NoError(t, err)
+			vnetRulesClient, err := armsql.NewVirtualNetworkRulesClient(subscriptionId, azfake.NewTokenCredential(), options)
+			assert.NoError(t, err)
+
+			err = collectAzureSQLAssets(context.Background(), serversClient, databasesClient, vnetRulesClient, subscriptionId, tt.regions, tt.resourceGroups, tt.assetTypes, logp.NewLogger("test"), publisher)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedEvents, publisher.Events)
+		})
+	}
+}
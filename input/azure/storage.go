@@ -0,0 +1,123 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package azure
+
+import (
+	"context"
+	"fmt"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/storage/armstorage"
+	"github.com/elastic/assetbeat/input/internal"
+	stateless "github.com/elastic/beats/v7/filebeat/input/v2/input-stateless"
+	"github.com/elastic/elastic-agent-libs/logp"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+	"strings"
+)
+
+func collectAzureStorageAccountAssets(ctx context.Context, client *armstorage.AccountsClient, subscriptionId string, regions []string, resourceGroups []string, log *logp.Logger, publisher stateless.Publisher) error {
+	accounts, err := getAllAzureStorageAccounts(ctx, client, regions, resourceGroups)
+	if err != nil {
+		return err
+	}
+
+	log.Debug("Publishing Azure storage accounts")
+
+	for _, account := range accounts {
+		publishAzureStorageAccount(publisher, subscriptionId, account)
+	}
+
+	return nil
+}
+
+func getAllAzureStorageAccounts(ctx context.Context, client *armstorage.AccountsClient, regions []string, resourceGroups []string) ([]*armstorage.Account, error) {
+	var accounts []*armstorage.Account
+	pager := client.NewListPager(nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to advance page: %v", err)
+		}
+		for _, v := range page.Value {
+			if v.ID != nil && v.Location != nil && wantRegion(*v.Location, regions) && wantResourceGroup(*v.ID, resourceGroups) {
+				accounts = append(accounts, v)
+			}
+		}
+	}
+	return accounts, nil
+}
+
+func publishAzureStorageAccount(publisher stateless.Publisher, subscriptionId string, account *armstorage.Account) {
+	assetType := "azure.storage.account"
+	assetKind := "bucket"
+
+	metadata := mapstr.M{
+		"resource_group": getResourceGroupFromId(*account.ID),
+	}
+	if account.Kind != nil {
+		metadata["kind"] = string(*account.Kind)
+	}
+	if sku := account.SKU; sku != nil {
+		skuMetadata := mapstr.M{}
+		if sku.Name != nil {
+			skuMetadata["name"] = string(*sku.Name)
+		}
+		if sku.Tier != nil {
+			skuMetadata["tier"] = string(*sku.Tier)
+		}
+		if len(skuMetadata) > 0 {
+			metadata["sku"] = skuMetadata
+		}
+	}
+	// storage accounts are not attached to virtual networks, subnets are only granted access through network rules
+	var subnetIDs []string
+	if props := account.Properties; props != nil {
+		if props.StatusOfPrimary != nil {
+			metadata["state"] = string(*props.StatusOfPrimary)
+		}
+		if props.ProvisioningState != nil {
+			metadata["provisioning_state"] = string(*props.ProvisioningState)
+		}
+		if props.AccessTier != nil {
+			metadata["access_tier"] = string(*props.AccessTier)
+		}
+		if props.NetworkRuleSet != nil {
+			for _, rule := range props.NetworkRuleSet.VirtualNetworkRules {
+				if rule != nil && rule.VirtualNetworkResourceID != nil {
+					subnetIDs = append(subnetIDs, *rule.VirtualNetworkResourceID)
+				}
+			}
+		}
+	}
+	if len(subnetIDs) > 0 {
+		metadata["network_rules"] = mapstr.M{"subnet_ids": subnetIDs}
+	}
+
+	options := []internal.AssetOption{
+		internal.WithAssetCloudProvider("azure"),
+		internal.WithAssetRegion(*account.Location),
+		internal.WithAssetAccountID(subscriptionId),
+		internal.WithAssetKindAndID(assetKind, strings.ToLower(*account.ID)),
+		internal.WithAssetType(assetType),
+		internal.WithAssetName(stringValue(account.Name)),
+		WithAssetTags(flattenAzureTags(account.Tags)),
+		internal.WithAssetMetadata(metadata),
+	}
+	if parents := withAzureResourceGroupParent(*account.ID, nil); len(parents) > 0 {
+		options = append(options, internal.WithAssetParents(parents))
+	}
+	internal.Publish(publisher, nil, options...)
+}
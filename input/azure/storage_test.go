@@ -0,0 +1,149 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package azure
+
+import (
+	"context"
+	"fmt"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	azfake "github.com/Azure/azure-sdk-for-go/sdk/azcore/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/storage/armstorage"
+	"github.com/elastic/assetbeat/input/internal"
+	"github.com/elastic/assetbeat/input/testutil"
+	"github.com/elastic/beats/v7/libbeat/beat"
+	"github.com/elastic/elastic-agent-libs/logp"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+var storageAccountID1 = fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Storage/storageAccounts/storage1", subscriptionId, resourceGroup1)
+var storageAccountID2 = fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Storage/storageAccounts/storage2", subscriptionId, resourceGroup2)
+
+var storageAccount1 = armstorage.Account{
+	ID:       to.Ptr(storageAccountID1),
+	Name:     to.Ptr("storage1"),
+	Location: to.Ptr("westeurope"),
+	Kind:     to.Ptr(armstorage.KindStorageV2),
+	SKU:      &armstorage.SKU{Name: to.Ptr(armstorage.SKUNameStandardLRS), Tier: to.Ptr(armstorage.SKUTierStandard)},
+	Tags:     map[string]*string{"env": to.Ptr("test")},
+	Properties: &armstorage.AccountProperties{
+		StatusOfPrimary:   to.Ptr(armstorage.AccountStatusAvailable),
+		ProvisioningState: to.Ptr(armstorage.ProvisioningStateSucceeded),
+		AccessTier:        to.Ptr(armstorage.AccessTierHot),
+		NetworkRuleSet: &armstorage.NetworkRuleSet{
+			VirtualNetworkRules: []*armstorage.VirtualNetworkRule{{VirtualNetworkResourceID: to.Ptr(subnetID1)}},
+		},
+	},
+}
+
+var storageAccount2 = armstorage.Account{
+	ID:       to.Ptr(storageAccountID2),
+	Name:     to.Ptr("storage2"),
+	Location: to.Ptr("northeurope"),
+	Kind:     to.Ptr(armstorage.KindBlobStorage),
+}
+
+var expectedStorageAccount1Event = beat.Event{
+	Fields: mapstr.M{
+		"asset.ean":     "bucket:" + strings.ToLower(storageAccountID1),
+		"asset.id":      strings.ToLower(storageAccountID1),
+		"asset.name":    "storage1",
+		"asset.type":    "azure.storage.account",
+		"asset.kind":    "bucket",
+		"asset.parents": []string{resourceGroupEAN1},
+		"asset.metadata.network_rules.subnet_ids": []string{subnetID1},
+		"asset.metadata.resource_group":           resourceGroup1,
+		"asset.metadata.kind":                     "StorageV2",
+		"asset.metadata.sku.name":                 "Standard_LRS",
+		"asset.metadata.sku.tier":                 "Standard",
+		"asset.metadata.state":                    "available",
+		"asset.metadata.provisioning_state":       "Succeeded",
+		"asset.metadata.access_tier":              "Hot",
+		"asset.metadata.tags.env":                 "test",
+		"cloud.account.id":                        subscriptionId,
+		"cloud.provider":                          "azure",
+		"cloud.region":                            "westeurope",
+	},
+	Meta: mapstr.M{
+		"index": internal.GetDefaultIndexName(),
+	},
+}
+
+var expectedStorageAccount2Event = beat.Event{
+	Fields: mapstr.M{
+		"asset.ean":                     "bucket:" + strings.ToLower(storageAccountID2),
+		"asset.id":                      strings.ToLower(storageAccountID2),
+		"asset.name":                    "storage2",
+		"asset.type":                    "azure.storage.account",
+		"asset.parents":                 []string{resourceGroupEAN2},
+		"asset.kind":                    "bucket",
+		"asset.metadata.resource_group": resourceGroup2,
+		"asset.metadata.kind":           "BlobStorage",
+		"cloud.account.id":              subscriptionId,
+		"cloud.provider":                "azure",
+		"cloud.region":                  "northeurope",
+	},
+	Meta: mapstr.M{
+		"index": internal.GetDefaultIndexName(),
+	},
+}
+
+func TestAssetsAzure_collectAzureStorageAccountAssets(t *testing.T) {
+	for _, tt := range []struct {
+		name           string
+		regions        []string
+		resourceGroups []string
+		expectedEvents []beat.Event
+	}{
+		{
+			name:           "all storage accounts",
+			expectedEvents: []beat.Event{expectedStorageAccount1Event, expectedStorageAccount2Event},
+		},
+		{
+			name:           "in a region",
+			regions:        []string{"westeurope"},
+			expectedEvents: []beat.Event{expectedStorageAccount1Event},
+		},
+		{
+			name:           "in a resource group",
+			resourceGroups: []string{resourceGroup2},
+			expectedEvents: []beat.Event{expectedStorageAccount2Event},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			publisher := testutil.NewInMemoryPublisher()
+			client, err := armstorage.NewAccountsClient(subscriptionId, azfake.NewTokenCredential(), &arm.ClientOptions{
+				ClientOptions: azcore.ClientOptions{
+					Transport: fakeTransport(func(req *http.Request) (*http.Response, error) {
+						return newJSONResponse(req, map[string]any{"value": []any{storageAccount1, storageAccount2}})
+					}),
+				},
+			})
+			assert.NoError(t, err)
+
+			err = collectAzureStorageAccountAssets(context.Background(), client, subscriptionId, tt.regions, tt.resourceGroups, logp.NewLogger("test"), publisher)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedEvents, publisher.Events)
+		})
+	}
+}
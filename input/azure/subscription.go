@@ -0,0 +1,151 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package azure
+
+import (
+	"context"
+	"fmt"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/managementgroups/armmanagementgroups"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/subscription/armsubscription"
+	"github.com/elastic/elastic-agent-libs/match"
+	"strings"
+)
+
+// azureSubscription is a subscription found by listing the subscriptions of the credentials or of a management group.
+type azureSubscription struct {
+	ID   string
+	Name string
+}
+
+// getAzureSubscriptions returns the IDs of the subscriptions to collect the assets of.
+func getAzureSubscriptions(ctx context.Context, cfg config, cred azcore.TokenCredential, clientOptions *arm.ClientOptions) ([]string, error) {
+	subscriptionsClient, err := armsubscription.NewSubscriptionsClient(cred, clientOptions)
+	if err != nil {
+		return nil, fmt.Errorf("error creating Azure Subscriptions client: %w", err)
+	}
+	managementGroupsClient, err := armmanagementgroups.NewClient(cred, clientOptions)
+	if err != nil {
+		return nil, fmt.Errorf("error creating Azure Management Groups client: %w", err)
+	}
+	return selectAzureSubscriptions(ctx, subscriptionsClient, managementGroupsClient, cfg)
+}
+
+// selectAzureSubscriptions returns the subscriptions listed in the configuration, followed by the ones of the configured
+// management groups or, when neither is configured, every subscription the credentials have access to.
+// The include and exclude patterns apply to the names of the subscriptions that are found, not to the listed ones.
+func selectAzureSubscriptions(ctx context.Context, subscriptionsClient *armsubscription.SubscriptionsClient, managementGroupsClient *armmanagementgroups.Client, cfg config) ([]string, error) {
+	subscriptionIDs := cfg.getSubscriptionIDs()
+
+	var found []azureSubscription
+	if len(subscriptionIDs) == 0 && len(cfg.ManagementGroups) == 0 {
+		subscriptions, err := listAzureSubscriptions(ctx, subscriptionsClient)
+		if err != nil {
+			return nil, err
+		}
+		found = subscriptions
+	}
+	for _, group := range cfg.ManagementGroups {
+		subscriptions, err := listAzureManagementGroupSubscriptions(ctx, managementGroupsClient, group)
+		if err != nil {
+			return nil, err
+		}
+		found = append(found, subscriptions...)
+	}
+	for _, sub := range found {
+		if wantSubscription(sub.Name, cfg.IncludeSubscriptions, cfg.ExcludeSubscriptions) {
+			subscriptionIDs = append(subscriptionIDs, sub.ID)
+		}
+	}
+
+	return deduplicateSubscriptionIDs(subscriptionIDs), nil
+}
+
+func listAzureSubscriptions(ctx context.Context, client *armsubscription.SubscriptionsClient) ([]azureSubscription, error) {
+	var subscriptions []azureSubscription
+	pager := client.NewListPager(nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to advance page: %w", err)
+		}
+		for _, v := range page.Value {
+			if v != nil && v.SubscriptionID != nil {
+				subscriptions = append(subscriptions, azureSubscription{ID: *v.SubscriptionID, Name: stringValue(v.DisplayName)})
+			}
+		}
+	}
+	return subscriptions, nil
+}
+
+// listAzureManagementGroupSubscriptions returns the subscriptions of a management group and of all its descendant management groups.
+func listAzureManagementGroupSubscriptions(ctx context.Context, client *armmanagementgroups.Client, group string) ([]azureSubscription, error) {
+	var subscriptions []azureSubscription
+	pager := client.NewGetDescendantsPager(group, nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error listing the descendants of management group %s: %w", group, err)
+		}
+		for _, v := range page.Value {
+			// descendants are either management groups or subscriptions, of type "Microsoft.Management/managementGroups/subscriptions"
+			if v == nil || v.Name == nil || v.Type == nil || !strings.HasSuffix(strings.ToLower(*v.Type), "/subscriptions") {
+				continue
+			}
+			sub := azureSubscription{ID: *v.Name}
+			if v.Properties != nil {
+				sub.Name = stringValue(v.Properties.DisplayName)
+			}
+			subscriptions = append(subscriptions, sub)
+		}
+	}
+	return subscriptions, nil
+}
+
+// wantSubscription reports whether the name of a subscription matches one of the include patterns, if any,
+// and none of the exclude patterns.
+func wantSubscription(name string, include []match.Matcher, exclude []match.Matcher) bool {
+	if len(include) > 0 && !matchAnyString(include, name) {
+		return false
+	}
+	return !matchAnyString(exclude, name)
+}
+
+func matchAnyString(matchers []match.Matcher, s string) bool {
+	for _, m := range matchers {
+		if m.MatchString(s) {
+			return true
+		}
+	}
+	return false
+}
+
+// deduplicateSubscriptionIDs returns the distinct subscription IDs of a slice, in order.
+// Subscription IDs are GUIDs, they are compared regardless of their case.
+func deduplicateSubscriptionIDs(in []string) []string {
+	var out []string
+	seen := make(map[string]bool, len(in))
+	for _, id := range in {
+		if key := strings.ToLower(id); !seen[key] {
+			seen[key] = true
+			out = append(out, id)
+		}
+	}
+	return out
+}
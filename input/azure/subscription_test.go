@@ -0,0 +1,170 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package azure
+
+import (
+	"context"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	azfake "github.com/Azure/azure-sdk-for-go/sdk/azcore/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/managementgroups/armmanagementgroups"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/subscription/armsubscription"
+	conf "github.com/elastic/elastic-agent-libs/config"
+	"github.com/elastic/elastic-agent-libs/match"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+const (
+	prodSubscriptionID    = "11111111-1111-1111-1111-111111111111"
+	devSubscriptionID     = "22222222-2222-2222-2222-222222222222"
+	sandboxSubscriptionID = "33333333-3333-3333-3333-333333333333"
+)
+
+// subscriptionsTransport answers the list of the subscriptions of the credentials, and the descendants
+// of the "platform" management group, which has a child management group.
+var subscriptionsTransport = fakeTransport(func(req *http.Request) (*http.Response, error) {
+	path := strings.ToLower(req.URL.Path)
+	switch {
+	case strings.HasSuffix(path, "/managementgroups/platform/descendants"):
+		return newJSONResponse(req, map[string]any{"value": []any{
+			map[string]any{
+				"id":         "/providers/Microsoft.Management/managementGroups/platform-prod",
+				"name":       "platform-prod",
+				"type":       "Microsoft.Management/managementGroups",
+				"properties": map[string]any{"displayName": "Platform production"},
+			},
+			map[string]any{
+				"id":         "/subscriptions/" + prodSubscriptionID,
+				"name":       prodSubscriptionID,
+				"type":       "Microsoft.Management/managementGroups/subscriptions",
+				"properties": map[string]any{"displayName": "prod-platform"},
+			},
+			map[string]any{
+				"id":         "/subscriptions/" + sandboxSubscriptionID,
+				"name":       sandboxSubscriptionID,
+				"type":       "/subscriptions",
+				"properties": map[string]any{"displayName": "sandbox"},
+			},
+		}})
+	case strings.HasSuffix(path, "/subscriptions"):
+		return newJSONResponse(req, map[string]any{"value": []any{
+			map[string]any{"subscriptionId": prodSubscriptionID, "displayName": "prod-platform"},
+			map[string]any{"subscriptionId": devSubscriptionID, "displayName": "dev-platform"},
+			map[string]any{"subscriptionId": sandboxSubscriptionID, "displayName": "sandbox"},
+		}})
+	}
+	return &http.Response{StatusCode: http.StatusNotFound, Body: http.NoBody, Request: req}, nil
+})
+
+func TestSelectAzureSubscriptions(t *testing.T) {
+	for _, tt := range []struct {
+		name                  string
+		cfg                   func(cfg *config)
+		expectedSubscriptions []string
+	}{
+		{
+			name:                  "all subscriptions",
+			cfg:                   func(cfg *config) {},
+			expectedSubscriptions: []string{prodSubscriptionID, devSubscriptionID, sandboxSubscriptionID},
+		},
+		{
+			name: "listed subscriptions",
+			cfg: func(cfg *config) {
+				cfg.SubscriptionID = devSubscriptionID
+				cfg.SubscriptionIDs = []string{prodSubscriptionID, strings.ToUpper(devSubscriptionID)}
+			},
+			expectedSubscriptions: []string{devSubscriptionID, prodSubscriptionID},
+		},
+		{
+			name: "included subscriptions",
+			cfg: func(cfg *config) {
+				cfg.IncludeSubscriptions = []match.Matcher{match.MustCompile("-platform$")}
+				cfg.ExcludeSubscriptions = []match.Matcher{match.MustCompile("^dev-")}
+			},
+			expectedSubscriptions: []string{prodSubscriptionID},
+		},
+		{
+			name: "excluded subscriptions",
+			cfg: func(cfg *config) {
+				cfg.ExcludeSubscriptions = []match.Matcher{match.MustCompile("^prod-"), match.MustCompile("^sandbox$")}
+			},
+			expectedSubscriptions: []string{devSubscriptionID},
+		},
+		{
+			name: "management group and listed subscriptions",
+			cfg: func(cfg *config) {
+				cfg.SubscriptionIDs = []string{devSubscriptionID}
+				cfg.ManagementGroups = []string{"platform"}
+				cfg.ExcludeSubscriptions = []match.Matcher{match.MustCompile("^sandbox$")}
+			},
+			expectedSubscriptions: []string{devSubscriptionID, prodSubscriptionID},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			clientOptions := &arm.ClientOptions{ClientOptions: azcore.ClientOptions{Transport: subscriptionsTransport}}
+			subscriptionsClient, err := armsubscription.NewSubscriptionsClient(azfake.NewTokenCredential(), clientOptions)
+			require.NoError(t, err)
+			managementGroupsClient, err := armmanagementgroups.NewClient(azfake.NewTokenCredential(), clientOptions)
+			require.NoError(t, err)
+
+			cfg := defaultConfig()
+			tt.cfg(&cfg)
+			subscriptions, err := selectAzureSubscriptions(context.Background(), subscriptionsClient, managementGroupsClient, cfg)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedSubscriptions, subscriptions)
+		})
+	}
+}
+
+func TestSelectAzureSubscriptions_unknownManagementGroup(t *testing.T) {
+	clientOptions := &arm.ClientOptions{ClientOptions: azcore.ClientOptions{Transport: subscriptionsTransport}}
+	subscriptionsClient, err := armsubscription.NewSubscriptionsClient(azfake.NewTokenCredential(), clientOptions)
+	require.NoError(t, err)
+	managementGroupsClient, err := armmanagementgroups.NewClient(azfake.NewTokenCredential(), clientOptions)
+	require.NoError(t, err)
+
+	cfg := defaultConfig()
+	cfg.ManagementGroups = []string{"other"}
+	_, err = selectAzureSubscriptions(context.Background(), subscriptionsClient, managementGroupsClient, cfg)
+	assert.ErrorContains(t, err, "error listing the descendants of management group other")
+}
+
+func TestConfig_subscriptionsAndResourceGroups(t *testing.T) {
+	cfg := defaultConfig()
+	err := conf.MustNewConfigFrom(map[string]any{
+		"subscription_id":       devSubscriptionID,
+		"subscription_ids":      []string{prodSubscriptionID},
+		"exclude_subscriptions": []string{"^sandbox$"},
+		"resource_group":        resourceGroup1,
+		"resource_groups":       []string{resourceGroup2},
+	}).Unpack(&cfg)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{devSubscriptionID, prodSubscriptionID}, cfg.getSubscriptionIDs())
+	assert.Equal(t, []string{resourceGroup1, resourceGroup2}, cfg.getResourceGroups())
+	assert.True(t, cfg.selectsSubscriptions())
+	assert.False(t, wantSubscription("sandbox", cfg.IncludeSubscriptions, cfg.ExcludeSubscriptions))
+	assert.True(t, wantSubscription("sandbox-2", cfg.IncludeSubscriptions, cfg.ExcludeSubscriptions))
+
+	defaultCfg := defaultConfig()
+	assert.False(t, defaultCfg.selectsSubscriptions())
+}
@@ -35,35 +35,47 @@ type AzureVMInstance struct {
 	SubscriptionID string
 	Region         string
 	Tags           map[string]*string
+	Parents        []string
 	Metadata       mapstr.M
 }
 
-func collectAzureVMAssets(ctx context.Context, client *armcompute.VirtualMachinesClient, subscriptionId string, regions []string, resourceGroup string, log *logp.Logger, publisher stateless.Publisher) error {
+func collectAzureVMAssets(ctx context.Context, client *armcompute.VirtualMachinesClient, nics *azureNetworkInterfaces, subscriptionId string, regions []string, resourceGroups []string, log *logp.Logger, publisher stateless.Publisher) error {
 
-	instances, err := getAllAzureVMInstances(ctx, client, subscriptionId, regions, resourceGroup)
+	instances, err := getAllAzureVMInstances(ctx, client, nics, subscriptionId, regions, resourceGroups)
 	if err != nil {
 		return err
 	}
 
-	assetType := "azure.vm.instance"
-	assetKind := "host"
 	log.Debug("Publishing Azure VM instances")
 
 	for _, instance := range instances {
-		internal.Publish(publisher, nil,
-			internal.WithAssetCloudProvider("azure"),
-			internal.WithAssetRegion(instance.Region),
-			internal.WithAssetAccountID(instance.SubscriptionID),
-			internal.WithAssetKindAndID(assetKind, instance.ID),
-			internal.WithAssetType(assetType),
-			internal.WithAssetMetadata(instance.Metadata),
-		)
+		publishAzureVMInstance(publisher, instance)
 	}
 
 	return nil
 }
 
-func getAllAzureVMInstances(ctx context.Context, client *armcompute.VirtualMachinesClient, subscriptionId string, regions []string, resourceGroup string) ([]AzureVMInstance, error) {
+// publishAzureVMInstance publishes a VM instance, whichever backend it was collected with.
+func publishAzureVMInstance(publisher stateless.Publisher, instance AzureVMInstance) {
+	assetType := "azure.vm.instance"
+	assetKind := "host"
+	options := []internal.AssetOption{
+		internal.WithAssetCloudProvider("azure"),
+		internal.WithAssetRegion(instance.Region),
+		internal.WithAssetAccountID(instance.SubscriptionID),
+		internal.WithAssetKindAndID(assetKind, instance.ID),
+		internal.WithAssetType(assetType),
+		internal.WithAssetName(instance.Name),
+		WithAssetTags(flattenAzureTags(instance.Tags)),
+		internal.WithAssetMetadata(instance.Metadata),
+	}
+	if len(instance.Parents) > 0 {
+		options = append(options, internal.WithAssetParents(instance.Parents))
+	}
+	internal.Publish(publisher, nil, options...)
+}
+
+func getAllAzureVMInstances(ctx context.Context, client *armcompute.VirtualMachinesClient, nics *azureNetworkInterfaces, subscriptionId string, regions []string, resourceGroups []string) ([]AzureVMInstance, error) {
 	var vmInstances []AzureVMInstance
 	pager := client.NewListAllPager(&armcompute.VirtualMachinesClientListAllOptions{StatusOnly: to.Ptr("true")})
 	for pager.More() {
@@ -72,51 +84,162 @@ func getAllAzureVMInstances(ctx context.Context, client *armcompute.VirtualMachi
 			return nil, fmt.Errorf("failed to advance page: %v", err)
 		}
 		for _, v := range page.Value {
-			if wantRegion(v, regions) && wantResourceGroup(v, resourceGroup) {
+			if wantRegion(*v.Location, regions) && wantResourceGroup(*v.ID, resourceGroups) {
 				var status string
-				if v.Properties != nil && v.Properties.InstanceView != nil && len(v.Properties.InstanceView.Statuses) > 1 {
-					status = *v.Properties.InstanceView.Statuses[1].DisplayStatus
-				}
-				vmInstance := AzureVMInstance{
-					ID:             *v.Properties.VMID,
-					Name:           *v.Name,
-					SubscriptionID: subscriptionId,
-					Region:         *v.Location,
-					Tags:           v.Tags,
-					Metadata: mapstr.M{
-						"state":          status,
-						"resource_group": getResourceGroupFromId(*v.ID),
-					},
+				if v.Properties != nil && v.Properties.InstanceView != nil {
+					status = getAzureVMPowerState(v.Properties.InstanceView.Statuses)
 				}
-				vmInstances = append(vmInstances, vmInstance)
+				vmInstances = append(vmInstances, newAzureVMInstance(v, subscriptionId, status, nics))
 			}
 		}
 	}
 	return vmInstances, nil
 }
 
-func wantResourceGroup(v *armcompute.VirtualMachine, resourceGroup string) bool {
-	if resourceGroup == "" {
-		return true
+// newAzureVMInstance builds the asset of a VM, with the network configuration resolved from its network interfaces.
+func newAzureVMInstance(v *armcompute.VirtualMachine, subscriptionId string, status string, nics *azureNetworkInterfaces) AzureVMInstance {
+	var size string
+	var storage *armcompute.StorageProfile
+	if props := v.Properties; props != nil {
+		if props.HardwareProfile != nil && props.HardwareProfile.VMSize != nil {
+			size = string(*props.HardwareProfile.VMSize)
+		}
+		storage = props.StorageProfile
+	}
+	network := nics.getVMNetwork(v)
+
+	return AzureVMInstance{
+		ID:             *v.Properties.VMID,
+		Name:           *v.Name,
+		SubscriptionID: subscriptionId,
+		Region:         *v.Location,
+		Tags:           v.Tags,
+		Parents:        withAzureResourceGroupParent(*v.ID, getAzureNetworkParents(network.SubnetIDs)),
+		Metadata:       getAzureVMMetadata(*v.ID, status, v.Zones, size, storage, network),
+	}
+}
+
+// getAzureVMMetadata builds the metadata shared by standalone VMs and VM scale set instances.
+func getAzureVMMetadata(id string, status string, zones []*string, size string, storage *armcompute.StorageProfile, network azureVMNetwork) mapstr.M {
+	metadata := mapstr.M{
+		"state":          status,
+		"resource_group": getResourceGroupFromId(id),
+	}
+	if len(zones) > 0 {
+		metadata["zones"] = stringValues(zones)
+	}
+	if size != "" {
+		metadata["size"] = size
+	}
+	if storage != nil {
+		if storage.OSDisk != nil && storage.OSDisk.OSType != nil {
+			metadata["os_type"] = string(*storage.OSDisk.OSType)
+		}
+		if image := getAzureVMImageMetadata(storage.ImageReference); len(image) > 0 {
+			metadata["image"] = image
+		}
+		if disks := getAzureVMManagedDiskIDs(storage); len(disks) > 0 {
+			metadata["disks"] = disks
+		}
+	}
+	if len(network.PrivateIPAddresses) > 0 {
+		metadata["private_ip_addresses"] = network.PrivateIPAddresses
+	}
+	if len(network.PublicIPAddresses) > 0 {
+		metadata["public_ip_addresses"] = network.PublicIPAddresses
 	}
-	if getResourceGroupFromId(*v.ID) == resourceGroup {
+	return metadata
+}
+
+// getAzureVMPowerState returns the display status of the power state of a VM, which follows its provisioning state.
+func getAzureVMPowerState(statuses []*armcompute.InstanceViewStatus) string {
+	if len(statuses) > 1 && statuses[1] != nil && statuses[1].DisplayStatus != nil {
+		return *statuses[1].DisplayStatus
+	}
+	return ""
+}
+
+// getAzureNetworkParents returns the EANs of the given subnets, followed by the ones of their virtual networks.
+func getAzureNetworkParents(subnetIDs []string) []string {
+	var parents []string
+	seenVNets := map[string]bool{}
+	var vnets []string
+	for _, subnetID := range subnetIDs {
+		parents = append(parents, azureEAN("network", subnetID))
+		if vnetID := getVNetIDFromSubnetID(subnetID); vnetID != "" && !seenVNets[strings.ToLower(vnetID)] {
+			seenVNets[strings.ToLower(vnetID)] = true
+			vnets = append(vnets, azureEAN("network", vnetID))
+		}
+	}
+	return append(parents, vnets...)
+}
+
+// getAzureVMImageMetadata describes the image a VM was created from: either a marketplace image,
+// or a custom image identified by its resource ID.
+func getAzureVMImageMetadata(image *armcompute.ImageReference) mapstr.M {
+	metadata := mapstr.M{}
+	if image == nil {
+		return metadata
+	}
+	for key, value := range map[string]*string{
+		"publisher":     image.Publisher,
+		"offer":         image.Offer,
+		"sku":           image.SKU,
+		"version":       image.Version,
+		"exact_version": image.ExactVersion,
+		"id":            image.ID,
+	} {
+		if value != nil && *value != "" {
+			metadata[key] = *value
+		}
+	}
+	return metadata
+}
+
+// getAzureVMManagedDiskIDs returns the IDs of the managed disks attached to a VM, OS disk first.
+func getAzureVMManagedDiskIDs(storage *armcompute.StorageProfile) []string {
+	var disks []string
+	if storage.OSDisk != nil && storage.OSDisk.ManagedDisk != nil && storage.OSDisk.ManagedDisk.ID != nil {
+		disks = append(disks, *storage.OSDisk.ManagedDisk.ID)
+	}
+	for _, disk := range storage.DataDisks {
+		if disk != nil && disk.ManagedDisk != nil && disk.ManagedDisk.ID != nil {
+			disks = append(disks, *disk.ManagedDisk.ID)
+		}
+	}
+	return disks
+}
+
+// wantResourceGroup reports whether a resource is in one of the given resource groups, whose names are case-insensitive.
+func wantResourceGroup(id string, resourceGroups []string) bool {
+	if len(resourceGroups) == 0 {
 		return true
 	}
+	for _, resourceGroup := range resourceGroups {
+		if strings.EqualFold(getResourceGroupFromId(id), resourceGroup) {
+			return true
+		}
+	}
 	return false
 }
 
-func wantRegion(v *armcompute.VirtualMachine, regions []string) bool {
+func wantRegion(location string, regions []string) bool {
 	if len(regions) == 0 {
 		return true
 	}
 	for _, region := range regions {
-		if *v.Location == region {
+		if normalizeAzureLocation(location) == normalizeAzureLocation(region) {
 			return true
 		}
 	}
 	return false
 }
 
+// normalizeAzureLocation returns the name of a location, as some APIs, like App Service, return its display name instead.
+func normalizeAzureLocation(location string) string {
+	return strings.ToLower(strings.ReplaceAll(location, " ", ""))
+}
+
 func getResourceGroupFromId(res string) string {
 	s := strings.Split(res, "/")
 	return s[4]
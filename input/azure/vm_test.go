@@ -26,6 +26,7 @@ import (
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v5"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v5/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v4"
 	"github.com/elastic/assetbeat/input/internal"
 	"github.com/elastic/assetbeat/input/testutil"
 	"github.com/elastic/beats/v7/libbeat/beat"
@@ -33,6 +34,7 @@ import (
 	"github.com/elastic/elastic-agent-libs/mapstr"
 	"github.com/stretchr/testify/assert"
 	"net/http"
+	"strings"
 	"testing"
 )
 
@@ -60,6 +62,35 @@ const instanceVMId4 = "4"
 
 var instanceIdDiffResourceGroup = fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Compute/virtualMachines/%s", subscriptionId, resourceGroup2, instance4Name)
 
+var vnetID1 = fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Network/virtualNetworks/vnet1", subscriptionId, resourceGroup1)
+var subnetID1 = vnetID1 + "/subnets/default"
+var vnetEAN1 = "network:" + strings.ToLower(vnetID1)
+var subnetEAN1 = "network:" + strings.ToLower(subnetID1)
+var nicID1 = fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Network/networkInterfaces/instance1-nic", subscriptionId, resourceGroup1)
+var publicIPID1 = fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Network/publicIPAddresses/instance1-ip", subscriptionId, resourceGroup1)
+var osDiskID1 = fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Compute/disks/instance1-osdisk", subscriptionId, resourceGroup1)
+var dataDiskID1 = fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Compute/disks/instance1-datadisk", subscriptionId, resourceGroup1)
+
+var nic1 = armnetwork.Interface{
+	ID: to.Ptr(nicID1),
+	Properties: &armnetwork.InterfacePropertiesFormat{
+		IPConfigurations: []*armnetwork.InterfaceIPConfiguration{
+			{
+				Properties: &armnetwork.InterfaceIPConfigurationPropertiesFormat{
+					PrivateIPAddress: to.Ptr("10.0.0.4"),
+					PublicIPAddress:  &armnetwork.PublicIPAddress{ID: to.Ptr(publicIPID1)},
+					Subnet:           &armnetwork.Subnet{ID: to.Ptr(subnetID1)},
+				},
+			},
+		},
+	},
+}
+
+var publicIP1 = armnetwork.PublicIPAddress{
+	ID:         to.Ptr(publicIPID1),
+	Properties: &armnetwork.PublicIPAddressPropertiesFormat{IPAddress: to.Ptr("20.0.0.1")},
+}
+
 var instance1 = armcompute.VirtualMachine{
 	Location:   to.Ptr("westeurope"),
 	ID:         to.Ptr(instanceid1),
@@ -94,7 +125,7 @@ func TestAssetsAzure_collectAzureAssets(t *testing.T) {
 		regions        []string
 		fakeServer     fake.VirtualMachinesServer
 		subscriptionId string
-		resourceGroup  string
+		resourceGroups []string
 		expectedEvents []beat.Event
 	}{
 		{
@@ -121,7 +152,9 @@ func TestAssetsAzure_collectAzureAssets(t *testing.T) {
 					Fields: mapstr.M{
 						"asset.ean":                     "host:" + instanceVMId1,
 						"asset.id":                      instanceVMId1,
+						"asset.name":                    instance1Name,
 						"asset.type":                    "azure.vm.instance",
+						"asset.parents":                 []string{resourceGroupEAN1},
 						"asset.kind":                    "host",
 						"asset.metadata.state":          "",
 						"asset.metadata.resource_group": "TESTVM",
@@ -137,7 +170,9 @@ func TestAssetsAzure_collectAzureAssets(t *testing.T) {
 					Fields: mapstr.M{
 						"asset.ean":                     "host:" + instanceVMId2,
 						"asset.id":                      instanceVMId2,
+						"asset.name":                    instance2Name,
 						"asset.type":                    "azure.vm.instance",
+						"asset.parents":                 []string{resourceGroupEAN1},
 						"asset.kind":                    "host",
 						"asset.metadata.state":          "",
 						"asset.metadata.resource_group": "TESTVM",
@@ -153,7 +188,9 @@ func TestAssetsAzure_collectAzureAssets(t *testing.T) {
 					Fields: mapstr.M{
 						"asset.ean":                     "host:" + instanceVMId3,
 						"asset.id":                      instanceVMId3,
+						"asset.name":                    instance3Name,
 						"asset.type":                    "azure.vm.instance",
+						"asset.parents":                 []string{resourceGroupEAN1},
 						"asset.kind":                    "host",
 						"asset.metadata.state":          "",
 						"asset.metadata.resource_group": "TESTVM",
@@ -192,7 +229,9 @@ func TestAssetsAzure_collectAzureAssets(t *testing.T) {
 					Fields: mapstr.M{
 						"asset.ean":                     "host:" + instanceVMId1,
 						"asset.id":                      instanceVMId1,
+						"asset.name":                    instance1Name,
 						"asset.type":                    "azure.vm.instance",
+						"asset.parents":                 []string{resourceGroupEAN1},
 						"asset.kind":                    "host",
 						"asset.metadata.state":          "",
 						"asset.metadata.resource_group": "TESTVM",
@@ -208,7 +247,9 @@ func TestAssetsAzure_collectAzureAssets(t *testing.T) {
 					Fields: mapstr.M{
 						"asset.ean":                     "host:" + instanceVMId2,
 						"asset.id":                      instanceVMId2,
+						"asset.name":                    instance2Name,
 						"asset.type":                    "azure.vm.instance",
+						"asset.parents":                 []string{resourceGroupEAN1},
 						"asset.kind":                    "host",
 						"asset.metadata.state":          "",
 						"asset.metadata.resource_group": "TESTVM",
@@ -225,7 +266,7 @@ func TestAssetsAzure_collectAzureAssets(t *testing.T) {
 		{
 			name:           "Test with multiple regions specified and resource group specified",
 			regions:        []string{"westeurope", "northeurope"},
-			resourceGroup:  resourceGroup1,
+			resourceGroups: []string{resourceGroup1},
 			subscriptionId: "12cabcb4-86e8-404f-111111111111",
 			fakeServer: fake.VirtualMachinesServer{
 				NewListAllPager: func(options *armcompute.VirtualMachinesClientListAllOptions) (resp azfake.PagerResponder[armcompute.VirtualMachinesClientListAllResponse]) {
@@ -249,7 +290,9 @@ func TestAssetsAzure_collectAzureAssets(t *testing.T) {
 					Fields: mapstr.M{
 						"asset.ean":                     "host:" + instanceVMId1,
 						"asset.id":                      instanceVMId1,
+						"asset.name":                    instance1Name,
 						"asset.type":                    "azure.vm.instance",
+						"asset.parents":                 []string{resourceGroupEAN1},
 						"asset.kind":                    "host",
 						"asset.metadata.state":          "",
 						"asset.metadata.resource_group": "TESTVM",
@@ -265,7 +308,9 @@ func TestAssetsAzure_collectAzureAssets(t *testing.T) {
 					Fields: mapstr.M{
 						"asset.ean":                     "host:" + instanceVMId2,
 						"asset.id":                      instanceVMId2,
+						"asset.name":                    instance2Name,
 						"asset.type":                    "azure.vm.instance",
+						"asset.parents":                 []string{resourceGroupEAN1},
 						"asset.kind":                    "host",
 						"asset.metadata.state":          "",
 						"asset.metadata.resource_group": "TESTVM",
@@ -293,10 +338,101 @@ func TestAssetsAzure_collectAzureAssets(t *testing.T) {
 			})
 			assert.NoError(t, err)
 
-			err = collectAzureVMAssets(ctx, client, tt.subscriptionId, tt.regions, tt.resourceGroup, logger, publisher)
+			err = collectAzureVMAssets(ctx, client, nil, tt.subscriptionId, tt.regions, tt.resourceGroups, logger, publisher)
 			assert.NoError(t, err)
 			assert.Equal(t, tt.expectedEvents, publisher.Events)
 		})
 
 	}
 }
+
+func TestAssetsAzure_collectAzureVMAssets_withDetails(t *testing.T) {
+	instance := armcompute.VirtualMachine{
+		Location: to.Ptr("westeurope"),
+		ID:       to.Ptr(instanceid1),
+		Name:     to.Ptr(instance1Name),
+		Tags:     map[string]*string{"env": to.Ptr("test")},
+		Zones:    []*string{to.Ptr("1")},
+		Properties: &armcompute.VirtualMachineProperties{
+			VMID:            to.Ptr(instanceVMId1),
+			HardwareProfile: &armcompute.HardwareProfile{VMSize: to.Ptr(armcompute.VirtualMachineSizeTypesStandardB1S)},
+			StorageProfile: &armcompute.StorageProfile{
+				OSDisk: &armcompute.OSDisk{
+					OSType:      to.Ptr(armcompute.OperatingSystemTypesLinux),
+					ManagedDisk: &armcompute.ManagedDiskParameters{ID: to.Ptr(osDiskID1)},
+				},
+				DataDisks: []*armcompute.DataDisk{
+					{ManagedDisk: &armcompute.ManagedDiskParameters{ID: to.Ptr(dataDiskID1)}},
+				},
+				ImageReference: &armcompute.ImageReference{
+					Publisher: to.Ptr("Canonical"),
+					Offer:     to.Ptr("0001-com-ubuntu-server-jammy"),
+					SKU:       to.Ptr("22_04-lts-gen2"),
+					Version:   to.Ptr("latest"),
+				},
+			},
+			NetworkProfile: &armcompute.NetworkProfile{
+				NetworkInterfaces: []*armcompute.NetworkInterfaceReference{{ID: to.Ptr(strings.ToUpper(nicID1))}},
+			},
+			InstanceView: &armcompute.VirtualMachineInstanceView{
+				Statuses: []*armcompute.InstanceViewStatus{
+					{DisplayStatus: to.Ptr("Provisioning succeeded")},
+					{DisplayStatus: to.Ptr("VM running")},
+				},
+			},
+		},
+	}
+	fakeServer := fake.VirtualMachinesServer{
+		NewListAllPager: func(options *armcompute.VirtualMachinesClientListAllOptions) (resp azfake.PagerResponder[armcompute.VirtualMachinesClientListAllResponse]) {
+			resp.AddPage(http.StatusOK, armcompute.VirtualMachinesClientListAllResponse{
+				VirtualMachineListResult: armcompute.VirtualMachineListResult{Value: []*armcompute.VirtualMachine{&instance}},
+			}, nil)
+			return
+		},
+	}
+	nics := newAzureNetworkInterfaces([]*armnetwork.Interface{&nic1}, []*armnetwork.PublicIPAddress{&publicIP1})
+
+	expectedEvents := []beat.Event{
+		{
+			Fields: mapstr.M{
+				"asset.ean":                           "host:" + instanceVMId1,
+				"asset.id":                            instanceVMId1,
+				"asset.name":                          instance1Name,
+				"asset.type":                          "azure.vm.instance",
+				"asset.kind":                          "host",
+				"asset.parents":                       []string{subnetEAN1, vnetEAN1, resourceGroupEAN1},
+				"asset.metadata.state":                "VM running",
+				"asset.metadata.resource_group":       "TESTVM",
+				"asset.metadata.tags.env":             "test",
+				"asset.metadata.zones":                []string{"1"},
+				"asset.metadata.size":                 "Standard_B1s",
+				"asset.metadata.os_type":              "Linux",
+				"asset.metadata.image.publisher":      "Canonical",
+				"asset.metadata.image.offer":          "0001-com-ubuntu-server-jammy",
+				"asset.metadata.image.sku":            "22_04-lts-gen2",
+				"asset.metadata.image.version":        "latest",
+				"asset.metadata.disks":                []string{osDiskID1, dataDiskID1},
+				"asset.metadata.private_ip_addresses": []string{"10.0.0.4"},
+				"asset.metadata.public_ip_addresses":  []string{"20.0.0.1"},
+				"cloud.account.id":                    subscriptionId,
+				"cloud.provider":                      "azure",
+				"cloud.region":                        "westeurope",
+			},
+			Meta: mapstr.M{
+				"index": internal.GetDefaultIndexName(),
+			},
+		},
+	}
+
+	publisher := testutil.NewInMemoryPublisher()
+	client, err := armcompute.NewVirtualMachinesClient(subscriptionId, azfake.NewTokenCredential(), &arm.ClientOptions{
+		ClientOptions: azcore.ClientOptions{
+			Transport: fake.NewVirtualMachinesServerTransport(&fakeServer),
+		},
+	})
+	assert.NoError(t, err)
+
+	err = collectAzureVMAssets(context.Background(), client, nics, subscriptionId, nil, nil, logp.NewLogger("test"), publisher)
+	assert.NoError(t, err)
+	assert.Equal(t, expectedEvents, publisher.Events)
+}
@@ -0,0 +1,278 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package azure
+
+import (
+	"context"
+	"fmt"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v5"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v4"
+	"github.com/elastic/assetbeat/input/internal"
+	stateless "github.com/elastic/beats/v7/filebeat/input/v2/input-stateless"
+	"github.com/elastic/elastic-agent-libs/logp"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+	"strings"
+)
+
+// collectAzureVMSSAssets publishes the VM scale sets of a subscription and their instances.
+// The instances of scale sets in Flexible orchestration mode are regular VMs, collected as azure.vm.instance,
+// so only the instances of scale sets in Uniform orchestration mode are listed.
+func collectAzureVMSSAssets(ctx context.Context, scaleSetClient *armcompute.VirtualMachineScaleSetsClient, scaleSetVMClient *armcompute.VirtualMachineScaleSetVMsClient, interfacesClient *armnetwork.InterfacesClient, publicIPsClient *armnetwork.PublicIPAddressesClient, subscriptionId string, regions []string, resourceGroups []string, assetTypes []string, log *logp.Logger, publisher stateless.Publisher) error {
+	scaleSets, err := getAllAzureVMSS(ctx, scaleSetClient, regions, resourceGroups)
+	if err != nil {
+		return err
+	}
+
+	log.Debug("Publishing Azure VM scale sets")
+
+	for _, vmss := range scaleSets {
+		var instances []AzureVMInstance
+		if isUniformAzureVMSS(vmss) {
+			nics, err := listAzureVMSSNetworkInterfaces(ctx, interfacesClient, publicIPsClient, getResourceGroupFromId(*vmss.ID), *vmss.Name)
+			if err != nil {
+				log.Warnf("Error while retrieving the network interfaces of VM scale set %s, its instances are published without their network configuration: %v", *vmss.ID, err)
+			}
+			instances, err = getAllAzureVMSSInstances(ctx, scaleSetVMClient, nics, subscriptionId, vmss)
+			if err != nil {
+				log.Errorf("Error while retrieving the instances of VM scale set %s: %v", *vmss.ID, err)
+			}
+		}
+		if internal.IsTypeEnabled(assetTypes, "azure.vmss") {
+			publishAzureVMSS(publisher, subscriptionId, vmss, instances)
+		}
+		if internal.IsTypeEnabled(assetTypes, "azure.vmss.instance") {
+			for _, instance := range instances {
+				publishAzureVMSSInstance(publisher, instance)
+			}
+		}
+	}
+
+	return nil
+}
+
+func getAllAzureVMSS(ctx context.Context, client *armcompute.VirtualMachineScaleSetsClient, regions []string, resourceGroups []string) ([]*armcompute.VirtualMachineScaleSet, error) {
+	var scaleSets []*armcompute.VirtualMachineScaleSet
+	pager := client.NewListAllPager(nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to advance page: %v", err)
+		}
+		for _, v := range page.Value {
+			if v.ID != nil && v.Name != nil && v.Location != nil && wantRegion(*v.Location, regions) && wantResourceGroup(*v.ID, resourceGroups) {
+				scaleSets = append(scaleSets, v)
+			}
+		}
+	}
+	return scaleSets, nil
+}
+
+func isUniformAzureVMSS(vmss *armcompute.VirtualMachineScaleSet) bool {
+	if vmss.Properties == nil || vmss.Properties.OrchestrationMode == nil {
+		return true
+	}
+	return *vmss.Properties.OrchestrationMode == armcompute.OrchestrationModeUniform
+}
+
+// listAzureVMSSNetworkInterfaces lists the network interfaces and public IPs of the instances of a VM scale set,
+// which are not returned along with the ones of the subscription.
+func listAzureVMSSNetworkInterfaces(ctx context.Context, interfacesClient *armnetwork.InterfacesClient, publicIPsClient *armnetwork.PublicIPAddressesClient, resourceGroup string, scaleSetName string) (*azureNetworkInterfaces, error) {
+	var interfaces []*armnetwork.Interface
+	interfacesPager := interfacesClient.NewListVirtualMachineScaleSetNetworkInterfacesPager(resourceGroup, scaleSetName, nil)
+	for interfacesPager.More() {
+		page, err := interfacesPager.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list network interfaces: %w", err)
+		}
+		interfaces = append(interfaces, page.Value...)
+	}
+
+	var publicIPs []*armnetwork.PublicIPAddress
+	publicIPsPager := publicIPsClient.NewListVirtualMachineScaleSetPublicIPAddressesPager(resourceGroup, scaleSetName, nil)
+	for publicIPsPager.More() {
+		page, err := publicIPsPager.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list public IP addresses: %w", err)
+		}
+		publicIPs = append(publicIPs, page.Value...)
+	}
+
+	return newAzureNetworkInterfaces(interfaces, publicIPs), nil
+}
+
+func getAllAzureVMSSInstances(ctx context.Context, client *armcompute.VirtualMachineScaleSetVMsClient, nics *azureNetworkInterfaces, subscriptionId string, vmss *armcompute.VirtualMachineScaleSet) ([]AzureVMInstance, error) {
+	var instances []AzureVMInstance
+	pager := client.NewListPager(getResourceGroupFromId(*vmss.ID), *vmss.Name, &armcompute.VirtualMachineScaleSetVMsClientListOptions{Expand: to.Ptr("instanceView")})
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to advance page: %v", err)
+		}
+		for _, v := range page.Value {
+			if v.ID != nil {
+				instances = append(instances, newAzureVMSSInstance(v, subscriptionId, *vmss.ID, nics))
+			}
+		}
+	}
+	return instances, nil
+}
+
+// newAzureVMSSInstance builds the asset of a VM scale set instance, with the same fields as the ones of a standalone VM.
+// It is identified by its resource ID, see getAzureVMSSInstanceID, and has its scale set as first parent.
+func newAzureVMSSInstance(v *armcompute.VirtualMachineScaleSetVM, subscriptionId string, vmssID string, nics *azureNetworkInterfaces) AzureVMInstance {
+	var status, size string
+	var storage *armcompute.StorageProfile
+	var network azureVMNetwork
+	if v.SKU != nil && v.SKU.Name != nil {
+		size = *v.SKU.Name
+	}
+	if props := v.Properties; props != nil {
+		if props.InstanceView != nil {
+			status = getAzureVMPowerState(props.InstanceView.Statuses)
+		}
+		if props.HardwareProfile != nil && props.HardwareProfile.VMSize != nil {
+			size = string(*props.HardwareProfile.VMSize)
+		}
+		storage = props.StorageProfile
+		network = nics.getNetwork(props.NetworkProfile)
+	}
+
+	metadata := getAzureVMMetadata(*v.ID, status, v.Zones, size, storage, network)
+	if v.Properties != nil && v.Properties.VMID != nil {
+		metadata["vm_id"] = *v.Properties.VMID
+	}
+
+	return AzureVMInstance{
+		ID:             getAzureVMSSInstanceID(*v.ID),
+		Name:           stringValue(v.Name),
+		SubscriptionID: subscriptionId,
+		Region:         stringValue(v.Location),
+		Tags:           v.Tags,
+		Parents:        withAzureResourceGroupParent(*v.ID, append([]string{azureEAN("host_group", vmssID)}, getAzureNetworkParents(network.SubnetIDs)...)),
+		Metadata:       metadata,
+	}
+}
+
+func publishAzureVMSS(publisher stateless.Publisher, subscriptionId string, vmss *armcompute.VirtualMachineScaleSet, instances []AzureVMInstance) {
+	assetType := "azure.vmss"
+	assetKind := "host_group"
+
+	var children []string
+	for _, instance := range instances {
+		children = append(children, "host:"+instance.ID)
+	}
+
+	options := []internal.AssetOption{
+		internal.WithAssetCloudProvider("azure"),
+		internal.WithAssetRegion(*vmss.Location),
+		internal.WithAssetAccountID(subscriptionId),
+		internal.WithAssetKindAndID(assetKind, strings.ToLower(*vmss.ID)),
+		internal.WithAssetType(assetType),
+		internal.WithAssetName(*vmss.Name),
+		WithAssetTags(flattenAzureTags(vmss.Tags)),
+		internal.WithAssetMetadata(getAzureVMSSMetadata(vmss)),
+	}
+	if parents := withAzureResourceGroupParent(*vmss.ID, getAzureNetworkParents(getAzureVMSSSubnetIDs(vmss))); len(parents) > 0 {
+		options = append(options, internal.WithAssetParents(parents))
+	}
+	if len(children) > 0 {
+		options = append(options, internal.WithAssetChildren(children))
+	}
+	internal.Publish(publisher, nil, options...)
+}
+
+func getAzureVMSSMetadata(vmss *armcompute.VirtualMachineScaleSet) mapstr.M {
+	metadata := mapstr.M{
+		"resource_group": getResourceGroupFromId(*vmss.ID),
+	}
+	if len(vmss.Zones) > 0 {
+		metadata["zones"] = stringValues(vmss.Zones)
+	}
+	if sku := vmss.SKU; sku != nil {
+		if sku.Capacity != nil {
+			metadata["capacity"] = *sku.Capacity
+		}
+		skuMetadata := mapstr.M{}
+		if sku.Name != nil {
+			skuMetadata["name"] = *sku.Name
+		}
+		if sku.Tier != nil {
+			skuMetadata["tier"] = *sku.Tier
+		}
+		if len(skuMetadata) > 0 {
+			metadata["sku"] = skuMetadata
+		}
+	}
+	if props := vmss.Properties; props != nil {
+		if props.UpgradePolicy != nil && props.UpgradePolicy.Mode != nil {
+			metadata["upgrade_policy"] = string(*props.UpgradePolicy.Mode)
+		}
+		if props.OrchestrationMode != nil {
+			metadata["orchestration_mode"] = string(*props.OrchestrationMode)
+		}
+		if props.ProvisioningState != nil {
+			metadata["provisioning_state"] = *props.ProvisioningState
+		}
+	}
+	return metadata
+}
+
+// getAzureVMSSSubnetIDs returns the IDs of the subnets the instances of a VM scale set are created in.
+func getAzureVMSSSubnetIDs(vmss *armcompute.VirtualMachineScaleSet) []string {
+	if vmss.Properties == nil || vmss.Properties.VirtualMachineProfile == nil || vmss.Properties.VirtualMachineProfile.NetworkProfile == nil {
+		return nil
+	}
+	var subnetIDs []string
+	seen := map[string]bool{}
+	for _, nic := range vmss.Properties.VirtualMachineProfile.NetworkProfile.NetworkInterfaceConfigurations {
+		if nic == nil || nic.Properties == nil {
+			continue
+		}
+		for _, ipConfig := range nic.Properties.IPConfigurations {
+			if ipConfig == nil || ipConfig.Properties == nil || ipConfig.Properties.Subnet == nil || ipConfig.Properties.Subnet.ID == nil {
+				continue
+			}
+			if id := *ipConfig.Properties.Subnet.ID; !seen[strings.ToLower(id)] {
+				seen[strings.ToLower(id)] = true
+				subnetIDs = append(subnetIDs, id)
+			}
+		}
+	}
+	return subnetIDs
+}
+
+// publishAzureVMSSInstance publishes a VM scale set instance with the fields of a standalone VM.
+func publishAzureVMSSInstance(publisher stateless.Publisher, instance AzureVMInstance) {
+	assetType := "azure.vmss.instance"
+	assetKind := "host"
+	options := []internal.AssetOption{
+		internal.WithAssetCloudProvider("azure"),
+		internal.WithAssetRegion(instance.Region),
+		internal.WithAssetAccountID(instance.SubscriptionID),
+		internal.WithAssetKindAndID(assetKind, instance.ID),
+		internal.WithAssetType(assetType),
+		internal.WithAssetName(instance.Name),
+		WithAssetTags(flattenAzureTags(instance.Tags)),
+		internal.WithAssetMetadata(instance.Metadata),
+	}
+	if len(instance.Parents) > 0 {
+		options = append(options, internal.WithAssetParents(instance.Parents))
+	}
+	internal.Publish(publisher, nil, options...)
+}
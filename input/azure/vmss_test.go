@@ -0,0 +1,283 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package azure
+
+import (
+	"context"
+	"fmt"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	azfake "github.com/Azure/azure-sdk-for-go/sdk/azcore/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v5"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v4"
+	"github.com/elastic/assetbeat/input/internal"
+	"github.com/elastic/assetbeat/input/testutil"
+	"github.com/elastic/beats/v7/libbeat/beat"
+	"github.com/elastic/elastic-agent-libs/logp"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+var vmssID1 = fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Compute/virtualMachineScaleSets/vmss1", subscriptionId, resourceGroup1)
+var vmssID2 = fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Compute/virtualMachineScaleSets/vmss2", subscriptionId, resourceGroup2)
+var vmssInstanceID1 = vmssID1 + "/virtualMachines/0"
+var vmssNICID1 = vmssInstanceID1 + "/networkInterfaces/vmss1-nic"
+var vmssPublicIPID1 = vmssNICID1 + "/ipConfigurations/vmss1-ipconfig/publicIPAddresses/vmss1-ip"
+
+var vmss1 = armcompute.VirtualMachineScaleSet{
+	ID:       to.Ptr(vmssID1),
+	Name:     to.Ptr("vmss1"),
+	Location: to.Ptr("westeurope"),
+	Tags:     map[string]*string{"env": to.Ptr("test")},
+	Zones:    []*string{to.Ptr("1"), to.Ptr("2")},
+	SKU: &armcompute.SKU{
+		Name:     to.Ptr("Standard_B1s"),
+		Tier:     to.Ptr("Standard"),
+		Capacity: to.Ptr[int64](1),
+	},
+	Properties: &armcompute.VirtualMachineScaleSetProperties{
+		OrchestrationMode: to.Ptr(armcompute.OrchestrationModeUniform),
+		UpgradePolicy:     &armcompute.UpgradePolicy{Mode: to.Ptr(armcompute.UpgradeModeRolling)},
+		ProvisioningState: to.Ptr("Succeeded"),
+		VirtualMachineProfile: &armcompute.VirtualMachineScaleSetVMProfile{
+			NetworkProfile: &armcompute.VirtualMachineScaleSetNetworkProfile{
+				NetworkInterfaceConfigurations: []*armcompute.VirtualMachineScaleSetNetworkConfiguration{
+					{
+						Name: to.Ptr("vmss1-nic"),
+						Properties: &armcompute.VirtualMachineScaleSetNetworkConfigurationProperties{
+							IPConfigurations: []*armcompute.VirtualMachineScaleSetIPConfiguration{
+								{
+									Name: to.Ptr("vmss1-ipconfig"),
+									Properties: &armcompute.VirtualMachineScaleSetIPConfigurationProperties{
+										Subnet: &armcompute.APIEntityReference{ID: to.Ptr(subnetID1)},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	},
+}
+
+var vmss2 = armcompute.VirtualMachineScaleSet{
+	ID:       to.Ptr(vmssID2),
+	Name:     to.Ptr("vmss2"),
+	Location: to.Ptr("northeurope"),
+	Properties: &armcompute.VirtualMachineScaleSetProperties{
+		OrchestrationMode: to.Ptr(armcompute.OrchestrationModeFlexible),
+	},
+}
+
+var vmssInstance1 = armcompute.VirtualMachineScaleSetVM{
+	ID:         to.Ptr(vmssInstanceID1),
+	Name:       to.Ptr("vmss1_0"),
+	Location:   to.Ptr("westeurope"),
+	InstanceID: to.Ptr("0"),
+	Tags:       map[string]*string{"env": to.Ptr("test")},
+	Zones:      []*string{to.Ptr("1")},
+	SKU:        &armcompute.SKU{Name: to.Ptr("Standard_B1s"), Tier: to.Ptr("Standard")},
+	Properties: &armcompute.VirtualMachineScaleSetVMProperties{
+		VMID: to.Ptr("5"),
+		InstanceView: &armcompute.VirtualMachineScaleSetVMInstanceView{
+			Statuses: []*armcompute.InstanceViewStatus{
+				{Code: to.Ptr("ProvisioningState/succeeded"), DisplayStatus: to.Ptr("Provisioning succeeded")},
+				{Code: to.Ptr("PowerState/running"), DisplayStatus: to.Ptr("VM running")},
+			},
+		},
+		StorageProfile: &armcompute.StorageProfile{
+			ImageReference: &armcompute.ImageReference{
+				Publisher: to.Ptr("Canonical"),
+				Offer:     to.Ptr("0001-com-ubuntu-server-jammy"),
+				SKU:       to.Ptr("22_04-lts-gen2"),
+				Version:   to.Ptr("latest"),
+			},
+			OSDisk: &armcompute.OSDisk{OSType: to.Ptr(armcompute.OperatingSystemTypesLinux)},
+		},
+		NetworkProfile: &armcompute.NetworkProfile{
+			NetworkInterfaces: []*armcompute.NetworkInterfaceReference{{ID: to.Ptr(vmssNICID1)}},
+		},
+	},
+}
+
+var vmssNIC1 = armnetwork.Interface{
+	ID: to.Ptr(vmssNICID1),
+	Properties: &armnetwork.InterfacePropertiesFormat{
+		IPConfigurations: []*armnetwork.InterfaceIPConfiguration{
+			{
+				Properties: &armnetwork.InterfaceIPConfigurationPropertiesFormat{
+					PrivateIPAddress: to.Ptr("10.0.0.5"),
+					PublicIPAddress:  &armnetwork.PublicIPAddress{ID: to.Ptr(vmssPublicIPID1)},
+					Subnet:           &armnetwork.Subnet{ID: to.Ptr(subnetID1)},
+				},
+			},
+		},
+	},
+}
+
+var vmssPublicIP1 = armnetwork.PublicIPAddress{
+	ID:         to.Ptr(vmssPublicIPID1),
+	Properties: &armnetwork.PublicIPAddressPropertiesFormat{IPAddress: to.Ptr("20.1.2.4")},
+}
+
+var expectedVMSS1Event = beat.Event{
+	Fields: mapstr.M{
+		"asset.ean":                         "host_group:" + strings.ToLower(vmssID1),
+		"asset.id":                          strings.ToLower(vmssID1),
+		"asset.name":                        "vmss1",
+		"asset.type":                        "azure.vmss",
+		"asset.kind":                        "host_group",
+		"asset.parents":                     []string{subnetEAN1, vnetEAN1, resourceGroupEAN1},
+		"asset.children":                    []string{"host:" + strings.ToLower(vmssInstanceID1)},
+		"asset.metadata.resource_group":     resourceGroup1,
+		"asset.metadata.zones":              []string{"1", "2"},
+		"asset.metadata.capacity":           int64(1),
+		"asset.metadata.sku.name":           "Standard_B1s",
+		"asset.metadata.sku.tier":           "Standard",
+		"asset.metadata.upgrade_policy":     "Rolling",
+		"asset.metadata.orchestration_mode": "Uniform",
+		"asset.metadata.provisioning_state": "Succeeded",
+		"asset.metadata.tags.env":           "test",
+		"cloud.account.id":                  subscriptionId,
+		"cloud.provider":                    "azure",
+		"cloud.region":                      "westeurope",
+	},
+	Meta: mapstr.M{
+		"index": internal.GetDefaultIndexName(),
+	},
+}
+
+var expectedVMSSInstance1Event = beat.Event{
+	Fields: mapstr.M{
+		"asset.ean":                           "host:" + strings.ToLower(vmssInstanceID1),
+		"asset.id":                            strings.ToLower(vmssInstanceID1),
+		"asset.name":                          "vmss1_0",
+		"asset.type":                          "azure.vmss.instance",
+		"asset.kind":                          "host",
+		"asset.parents":                       []string{"host_group:" + strings.ToLower(vmssID1), subnetEAN1, vnetEAN1, resourceGroupEAN1},
+		"asset.metadata.state":                "VM running",
+		"asset.metadata.resource_group":       resourceGroup1,
+		"asset.metadata.zones":                []string{"1"},
+		"asset.metadata.size":                 "Standard_B1s",
+		"asset.metadata.os_type":              "Linux",
+		"asset.metadata.image.publisher":      "Canonical",
+		"asset.metadata.image.offer":          "0001-com-ubuntu-server-jammy",
+		"asset.metadata.image.sku":            "22_04-lts-gen2",
+		"asset.metadata.image.version":        "latest",
+		"asset.metadata.private_ip_addresses": []string{"10.0.0.5"},
+		"asset.metadata.public_ip_addresses":  []string{"20.1.2.4"},
+		"asset.metadata.vm_id":                "5",
+		"asset.metadata.tags.env":             "test",
+		"cloud.account.id":                    subscriptionId,
+		"cloud.provider":                      "azure",
+		"cloud.region":                        "westeurope",
+	},
+	Meta: mapstr.M{
+		"index": internal.GetDefaultIndexName(),
+	},
+}
+
+var expectedVMSS2Event = beat.Event{
+	Fields: mapstr.M{
+		"asset.ean":                         "host_group:" + strings.ToLower(vmssID2),
+		"asset.id":                          strings.ToLower(vmssID2),
+		"asset.name":                        "vmss2",
+		"asset.type":                        "azure.vmss",
+		"asset.parents":                     []string{resourceGroupEAN2},
+		"asset.kind":                        "host_group",
+		"asset.metadata.resource_group":     resourceGroup2,
+		"asset.metadata.orchestration_mode": "Flexible",
+		"cloud.account.id":                  subscriptionId,
+		"cloud.provider":                    "azure",
+		"cloud.region":                      "northeurope",
+	},
+	Meta: mapstr.M{
+		"index": internal.GetDefaultIndexName(),
+	},
+}
+
+// vmssTransport answers the requests listing the VM scale sets of the subscription, and the instances,
+// network interfaces and public IPs of vmss1.
+var vmssTransport = fakeTransport(func(req *http.Request) (*http.Response, error) {
+	path := strings.ToLower(req.URL.Path)
+	switch {
+	case strings.HasSuffix(path, "/providers/microsoft.compute/virtualmachinescalesets"):
+		return newJSONResponse(req, map[string]any{"value": []any{vmss1, vmss2}})
+	case strings.HasSuffix(path, "/virtualmachinescalesets/vmss1/virtualmachines"):
+		return newJSONResponse(req, map[string]any{"value": []any{vmssInstance1}})
+	case strings.HasSuffix(path, "/virtualmachinescalesets/vmss1/networkinterfaces"):
+		return newJSONResponse(req, map[string]any{"value": []any{vmssNIC1}})
+	case strings.HasSuffix(path, "/virtualmachinescalesets/vmss1/publicipaddresses"):
+		return newJSONResponse(req, map[string]any{"value": []any{vmssPublicIP1}})
+	default:
+		return &http.Response{StatusCode: http.StatusNotFound, Body: http.NoBody, Request: req}, nil
+	}
+})
+
+func TestAssetsAzure_collectAzureVMSSAssets(t *testing.T) {
+	for _, tt := range []struct {
+		name           string
+		regions        []string
+		resourceGroups []string
+		assetTypes     []string
+		expectedEvents []beat.Event
+	}{
+		{
+			name:           "all asset types",
+			expectedEvents: []beat.Event{expectedVMSS1Event, expectedVMSSInstance1Event, expectedVMSS2Event},
+		},
+		{
+			name:           "scale sets only",
+			assetTypes:     []string{"azure.vmss"},
+			expectedEvents: []beat.Event{expectedVMSS1Event, expectedVMSS2Event},
+		},
+		{
+			name:           "instances only, in a region",
+			regions:        []string{"westeurope"},
+			assetTypes:     []string{"azure.vmss.instance"},
+			expectedEvents: []beat.Event{expectedVMSSInstance1Event},
+		},
+		{
+			name:           "in a resource group",
+			resourceGroups: []string{resourceGroup2},
+			expectedEvents: []beat.Event{expectedVMSS2Event},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			publisher := testutil.NewInMemoryPublisher()
+			options := &arm.ClientOptions{ClientOptions: azcore.ClientOptions{Transport: vmssTransport}}
+			scaleSetClient, err := armcompute.NewVirtualMachineScaleSetsClient(subscriptionId, azfake.NewTokenCredential(), options)
+			assert.NoError(t, err)
+			scaleSetVMClient, err := armcompute.NewVirtualMachineScaleSetVMsClient(subscriptionId, azfake.NewTokenCredential(), options)
+			assert.NoError(t, err)
+			interfacesClient, err := armnetwork.NewInterfacesClient(subscriptionId, azfake.NewTokenCredential(), options)
+			assert.NoError(t, err)
+			publicIPsClient, err := armnetwork.NewPublicIPAddressesClient(subscriptionId, azfake.NewTokenCredential(), options)
+			assert.NoError(t, err)
+
+			err = collectAzureVMSSAssets(context.Background(), scaleSetClient, scaleSetVMClient, interfacesClient, publicIPsClient, subscriptionId, tt.regions, tt.resourceGroups, tt.assetTypes, logp.NewLogger("test"), publisher)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedEvents, publisher.Events)
+		})
+	}
+}
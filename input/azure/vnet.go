@@ -0,0 +1,183 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package azure
+
+import (
+	"context"
+	"fmt"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v4"
+	"github.com/elastic/assetbeat/input/internal"
+	stateless "github.com/elastic/beats/v7/filebeat/input/v2/input-stateless"
+	"github.com/elastic/elastic-agent-libs/logp"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+	"strings"
+)
+
+// collectAzureVNetAssets publishes the virtual networks of a subscription and, as they are listed
+// along with their virtual network, their subnets.
+func collectAzureVNetAssets(ctx context.Context, client *armnetwork.VirtualNetworksClient, subscriptionId string, regions []string, resourceGroups []string, assetTypes []string, log *logp.Logger, publisher stateless.Publisher) error {
+	vnets, err := getAllAzureVNets(ctx, client, regions, resourceGroups)
+	if err != nil {
+		return err
+	}
+
+	log.Debug("Publishing Azure virtual networks")
+
+	for _, vnet := range vnets {
+		if internal.IsTypeEnabled(assetTypes, "azure.vnet") {
+			publishAzureVNet(publisher, subscriptionId, vnet)
+		}
+		if internal.IsTypeEnabled(assetTypes, "azure.subnet") {
+			publishAzureSubnets(publisher, subscriptionId, vnet)
+		}
+	}
+
+	return nil
+}
+
+func getAllAzureVNets(ctx context.Context, client *armnetwork.VirtualNetworksClient, regions []string, resourceGroups []string) ([]*armnetwork.VirtualNetwork, error) {
+	var vnets []*armnetwork.VirtualNetwork
+	pager := client.NewListAllPager(nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to advance page: %v", err)
+		}
+		for _, v := range page.Value {
+			if v.ID != nil && v.Location != nil && wantRegion(*v.Location, regions) && wantResourceGroup(*v.ID, resourceGroups) {
+				vnets = append(vnets, v)
+			}
+		}
+	}
+	return vnets, nil
+}
+
+// publishAzureVNet publishes a virtual network, whichever backend it was collected with.
+func publishAzureVNet(publisher stateless.Publisher, subscriptionId string, vnet *armnetwork.VirtualNetwork) {
+	assetType := "azure.vnet"
+	assetKind := "network"
+
+	metadata := mapstr.M{
+		"resource_group": getResourceGroupFromId(*vnet.ID),
+	}
+	if props := vnet.Properties; props != nil {
+		if props.AddressSpace != nil {
+			metadata["address_space"] = stringValues(props.AddressSpace.AddressPrefixes)
+		}
+		if props.DhcpOptions != nil && len(props.DhcpOptions.DNSServers) > 0 {
+			metadata["dns_servers"] = stringValues(props.DhcpOptions.DNSServers)
+		}
+		if peerings := getAzureVNetPeerings(props.VirtualNetworkPeerings); len(peerings) > 0 {
+			metadata["peerings"] = peerings
+		}
+	}
+
+	internal.Publish(publisher, nil,
+		internal.WithAssetCloudProvider("azure"),
+		internal.WithAssetRegion(*vnet.Location),
+		internal.WithAssetAccountID(subscriptionId),
+		internal.WithAssetKindAndID(assetKind, strings.ToLower(*vnet.ID)),
+		internal.WithAssetType(assetType),
+		internal.WithAssetName(stringValue(vnet.Name)),
+		internal.WithAssetParents(withAzureResourceGroupParent(*vnet.ID, nil)),
+		WithAssetTags(flattenAzureTags(vnet.Tags)),
+		internal.WithAssetMetadata(metadata),
+	)
+}
+
+// publishAzureSubnets publishes the subnets of a virtual network, whichever backend they were collected with.
+// Subnets have no location nor tags of their own, they are published with the ones of their virtual network.
+func publishAzureSubnets(publisher stateless.Publisher, subscriptionId string, vnet *armnetwork.VirtualNetwork) {
+	if vnet.Properties == nil {
+		return
+	}
+
+	assetType := "azure.subnet"
+	assetKind := "network"
+	for _, subnet := range vnet.Properties.Subnets {
+		if subnet == nil || subnet.ID == nil {
+			continue
+		}
+		metadata := mapstr.M{
+			"resource_group": getResourceGroupFromId(*subnet.ID),
+		}
+		if props := subnet.Properties; props != nil {
+			addressPrefixes := stringValues(props.AddressPrefixes)
+			if props.AddressPrefix != nil {
+				addressPrefixes = append([]string{*props.AddressPrefix}, addressPrefixes...)
+			}
+			metadata["address_prefixes"] = addressPrefixes
+			if props.NetworkSecurityGroup != nil && props.NetworkSecurityGroup.ID != nil {
+				metadata["network_security_group_id"] = *props.NetworkSecurityGroup.ID
+			}
+			if props.RouteTable != nil && props.RouteTable.ID != nil {
+				metadata["route_table_id"] = *props.RouteTable.ID
+			}
+		}
+
+		internal.Publish(publisher, nil,
+			internal.WithAssetCloudProvider("azure"),
+			internal.WithAssetRegion(*vnet.Location),
+			internal.WithAssetAccountID(subscriptionId),
+			internal.WithAssetKindAndID(assetKind, strings.ToLower(*subnet.ID)),
+			internal.WithAssetType(assetType),
+			internal.WithAssetName(stringValue(subnet.Name)),
+			internal.WithAssetParents(withAzureResourceGroupParent(*subnet.ID, []string{azureEAN("network", *vnet.ID)})),
+			internal.WithAssetMetadata(metadata),
+		)
+	}
+}
+
+// getAzureVNetPeerings describes the peerings of a virtual network with other virtual networks.
+func getAzureVNetPeerings(peerings []*armnetwork.VirtualNetworkPeering) []mapstr.M {
+	var out []mapstr.M
+	for _, peering := range peerings {
+		if peering == nil || peering.Properties == nil {
+			continue
+		}
+		p := mapstr.M{
+			"name": stringValue(peering.Name),
+		}
+		if peering.Properties.RemoteVirtualNetwork != nil && peering.Properties.RemoteVirtualNetwork.ID != nil {
+			p["remote_vnet_id"] = *peering.Properties.RemoteVirtualNetwork.ID
+		}
+		if peering.Properties.PeeringState != nil {
+			p["state"] = string(*peering.Properties.PeeringState)
+		}
+		out = append(out, p)
+	}
+	return out
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// stringValues dereferences the non-nil strings of a slice.
+func stringValues(in []*string) []string {
+	out := make([]string, 0, len(in))
+	for _, s := range in {
+		if s != nil {
+			out = append(out, *s)
+		}
+	}
+	return out
+}
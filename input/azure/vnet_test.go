@@ -0,0 +1,213 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package azure
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	azfake "github.com/Azure/azure-sdk-for-go/sdk/azcore/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v4"
+	"github.com/elastic/assetbeat/input/internal"
+	"github.com/elastic/assetbeat/input/testutil"
+	"github.com/elastic/beats/v7/libbeat/beat"
+	"github.com/elastic/elastic-agent-libs/logp"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+var vnetID2 = fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Network/virtualNetworks/vnet2", subscriptionId, resourceGroup2)
+var nsgID1 = fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Network/networkSecurityGroups/nsg1", subscriptionId, resourceGroup1)
+var routeTableID1 = fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Network/routeTables/rt1", subscriptionId, resourceGroup1)
+
+// vnet1 has the JSON shape of the virtual networks of both the Azure Resource Manager API and Azure Resource Graph.
+var vnet1 = map[string]any{
+	"id":       vnetID1,
+	"name":     "vnet1",
+	"location": "westeurope",
+	"tags":     map[string]any{"env": "test"},
+	"properties": map[string]any{
+		"addressSpace": map[string]any{"addressPrefixes": []string{"10.0.0.0/16"}},
+		"dhcpOptions":  map[string]any{"dnsServers": []string{"10.0.0.10"}},
+		"subnets": []map[string]any{
+			{
+				"id":   subnetID1,
+				"name": "default",
+				"properties": map[string]any{
+					"addressPrefix":        "10.0.0.0/24",
+					"networkSecurityGroup": map[string]any{"id": nsgID1},
+					"routeTable":           map[string]any{"id": routeTableID1},
+				},
+			},
+		},
+		"virtualNetworkPeerings": []map[string]any{
+			{
+				"name": "vnet1-to-vnet2",
+				"properties": map[string]any{
+					"remoteVirtualNetwork": map[string]any{"id": vnetID2},
+					"peeringState":         "Connected",
+				},
+			},
+		},
+	},
+}
+
+var vnet2 = map[string]any{
+	"id":         vnetID2,
+	"name":       "vnet2",
+	"location":   "northeurope",
+	"properties": map[string]any{"addressSpace": map[string]any{"addressPrefixes": []string{"10.1.0.0/16"}}},
+}
+
+var expectedVNet1Event = beat.Event{
+	Fields: mapstr.M{
+		"asset.ean":                     vnetEAN1,
+		"asset.id":                      strings.ToLower(vnetID1),
+		"asset.name":                    "vnet1",
+		"asset.type":                    "azure.vnet",
+		"asset.parents":                 []string{resourceGroupEAN1},
+		"asset.kind":                    "network",
+		"asset.metadata.resource_group": resourceGroup1,
+		"asset.metadata.address_space":  []string{"10.0.0.0/16"},
+		"asset.metadata.dns_servers":    []string{"10.0.0.10"},
+		"asset.metadata.peerings": []mapstr.M{
+			{"name": "vnet1-to-vnet2", "remote_vnet_id": vnetID2, "state": "Connected"},
+		},
+		"asset.metadata.tags.env": "test",
+		"cloud.account.id":        subscriptionId,
+		"cloud.provider":          "azure",
+		"cloud.region":            "westeurope",
+	},
+	Meta: mapstr.M{
+		"index": internal.GetDefaultIndexName(),
+	},
+}
+
+var expectedSubnet1Event = beat.Event{
+	Fields: mapstr.M{
+		"asset.ean":                       subnetEAN1,
+		"asset.id":                        strings.ToLower(subnetID1),
+		"asset.name":                      "default",
+		"asset.type":                      "azure.subnet",
+		"asset.kind":                      "network",
+		"asset.parents":                   []string{vnetEAN1, resourceGroupEAN1},
+		"asset.metadata.resource_group":   resourceGroup1,
+		"asset.metadata.address_prefixes": []string{"10.0.0.0/24"},
+		"asset.metadata.network_security_group_id": nsgID1,
+		"asset.metadata.route_table_id":            routeTableID1,
+		"cloud.account.id":                         subscriptionId,
+		"cloud.provider":                           "azure",
+		"cloud.region":                             "westeurope",
+	},
+	Meta: mapstr.M{
+		"index": internal.GetDefaultIndexName(),
+	},
+}
+
+var expectedVNet2Event = beat.Event{
+	Fields: mapstr.M{
+		"asset.ean":                     "network:" + strings.ToLower(vnetID2),
+		"asset.id":                      strings.ToLower(vnetID2),
+		"asset.name":                    "vnet2",
+		"asset.type":                    "azure.vnet",
+		"asset.parents":                 []string{resourceGroupEAN2},
+		"asset.kind":                    "network",
+		"asset.metadata.resource_group": resourceGroup2,
+		"asset.metadata.address_space":  []string{"10.1.0.0/16"},
+		"cloud.account.id":              subscriptionId,
+		"cloud.provider":                "azure",
+		"cloud.region":                  "northeurope",
+	},
+	Meta: mapstr.M{
+		"index": internal.GetDefaultIndexName(),
+	},
+}
+
+// fakeTransport answers the requests of Azure SDK clients, for APIs without fake servers.
+type fakeTransport func(req *http.Request) (*http.Response, error)
+
+func (f fakeTransport) Do(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// newJSONResponse returns a successful response to req with body encoded as JSON.
+func newJSONResponse(req *http.Request, body any) (*http.Response, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(string(data))),
+		Request:    req,
+	}, nil
+}
+
+func TestAssetsAzure_collectAzureVNetAssets(t *testing.T) {
+	for _, tt := range []struct {
+		name           string
+		regions        []string
+		resourceGroups []string
+		assetTypes     []string
+		expectedEvents []beat.Event
+	}{
+		{
+			name:           "all asset types",
+			expectedEvents: []beat.Event{expectedVNet1Event, expectedSubnet1Event, expectedVNet2Event},
+		},
+		{
+			name:           "virtual networks only, in a region",
+			regions:        []string{"westeurope"},
+			assetTypes:     []string{"azure.vnet"},
+			expectedEvents: []beat.Event{expectedVNet1Event},
+		},
+		{
+			name:           "subnets only",
+			assetTypes:     []string{"azure.subnet"},
+			expectedEvents: []beat.Event{expectedSubnet1Event},
+		},
+		{
+			name:           "in a resource group",
+			resourceGroups: []string{resourceGroup2},
+			expectedEvents: []beat.Event{expectedVNet2Event},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			publisher := testutil.NewInMemoryPublisher()
+			client, err := armnetwork.NewVirtualNetworksClient(subscriptionId, azfake.NewTokenCredential(), &arm.ClientOptions{
+				ClientOptions: azcore.ClientOptions{
+					Transport: fakeTransport(func(req *http.Request) (*http.Response, error) {
+						return newJSONResponse(req, map[string]any{"value": []any{vnet1, vnet2}})
+					}),
+				},
+			})
+			assert.NoError(t, err)
+
+			err = collectAzureVNetAssets(context.Background(), client, subscriptionId, tt.regions, tt.resourceGroups, tt.assetTypes, logp.NewLogger("test"), publisher)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedEvents, publisher.Events)
+		})
+	}
+}
@@ -0,0 +1,139 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package gcp
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/api/cloudfunctions/v2"
+
+	"github.com/elastic/assetbeat/input/internal"
+	stateless "github.com/elastic/beats/v7/filebeat/input/v2/input-stateless"
+	"github.com/elastic/elastic-agent-libs/logp"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+	"github.com/elastic/go-freelru"
+)
+
+type listCloudFunctionsAPIClient struct {
+	List func(ctx context.Context, parent string) ([]*cloudfunctions.Function, error)
+}
+
+type cloudFunction struct {
+	ID        string
+	Name      string
+	Region    string
+	Account   string
+	Connector string
+	Labels    map[string]string
+	Metadata  mapstr.M
+}
+
+func collectCloudFunctionsAssets(ctx context.Context, cfg config, vpcAssetCache *freelru.LRU[string, *vpc], client listCloudFunctionsAPIClient, connectorClient getVPCConnectorAPIClient, publisher stateless.Publisher, log *logp.Logger) error {
+	functions, err := getAllCloudFunctions(ctx, cfg, client)
+	if err != nil {
+		return err
+	}
+
+	assetType := "gcp.cloudfunctions.function"
+	assetKind := "function"
+	log.Debug("Publishing Cloud Functions")
+	connectorVpcs := make(map[string]string)
+	for _, function := range functions {
+		var parents []string
+
+		if function.Connector != "" {
+			vpcID, err := getVpcIdFromConnector(ctx, function.Connector, connectorVpcs, vpcAssetCache, connectorClient)
+			// We should not fail hard here since the core information for the asset comes from the function data
+			if err != nil {
+				log.Warnf("Error while retrieving the VPC of Cloud Function %s: %+v", function.ID, err)
+			}
+			if len(vpcID) > 0 {
+				parents = append(parents, "network:"+vpcID)
+			}
+		}
+
+		internal.Publish(publisher, nil,
+			internal.WithAssetCloudProvider("gcp"),
+			internal.WithAssetRegion(function.Region),
+			internal.WithAssetAccountID(function.Account),
+			internal.WithAssetKindAndID(assetKind, function.ID),
+			internal.WithAssetName(function.Name),
+			internal.WithAssetType(assetType),
+			internal.WithAssetParents(parents),
+			WithAssetLabels(internal.ToMapstr(function.Labels)),
+			internal.WithAssetMetadata(function.Metadata),
+		)
+	}
+
+	return nil
+}
+
+func makeListFunctionsParents(project string, regions []string) []string {
+	if len(regions) == 0 {
+		return []string{fmt.Sprintf("projects/%s/locations/%s", project, "-")}
+	}
+	var parents []string
+	for _, region := range regions {
+		parents = append(parents, fmt.Sprintf("projects/%s/locations/%s", project, region))
+	}
+	return parents
+}
+
+func getAllCloudFunctions(ctx context.Context, cfg config, client listCloudFunctionsAPIClient) ([]cloudFunction, error) {
+	var functions []cloudFunction
+	for _, project := range cfg.Projects {
+		for _, parent := range makeListFunctionsParents(project, cfg.Regions) {
+			list, err := client.List(ctx, parent)
+			if err != nil {
+				return nil, fmt.Errorf("error retrieving Cloud Functions list for project %s: %w", project, err)
+			}
+
+			for _, f := range list {
+				metadata := mapstr.M{
+					"state":       f.State,
+					"environment": f.Environment,
+				}
+				if f.BuildConfig != nil {
+					metadata["runtime"] = f.BuildConfig.Runtime
+				}
+				var connector string
+				if sc := f.ServiceConfig; sc != nil {
+					metadata["ingress"] = sc.IngressSettings
+					metadata["service_account"] = sc.ServiceAccountEmail
+					metadata["min_instances"] = sc.MinInstanceCount
+					metadata["max_instances"] = sc.MaxInstanceCount
+					metadata["uri"] = sc.Uri
+					connector = sc.VpcConnector
+				}
+
+				functions = append(functions, cloudFunction{
+					ID:        f.Name,
+					Name:      getResourceNameFromURL(f.Name),
+					Region:    getLocationFromResourceName(f.Name),
+					Account:   project,
+					Connector: connector,
+					Labels:    f.Labels,
+					Metadata:  metadata,
+				})
+			}
+		}
+	}
+
+	return functions, nil
+}
@@ -0,0 +1,184 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package gcp
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/api/cloudfunctions/v2"
+	"google.golang.org/api/vpcaccess/v1"
+
+	"github.com/elastic/assetbeat/input/internal"
+	"github.com/elastic/assetbeat/input/testutil"
+	"github.com/elastic/beats/v7/libbeat/beat"
+	"github.com/elastic/elastic-agent-libs/logp"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+func TestCollectCloudFunctionsAssets(t *testing.T) {
+	var parents []string
+	connectorClient := getVPCConnectorAPIClient{
+		Get: func(ctx context.Context, name string) (*vpcaccess.Connector, error) {
+			if name == "projects/my_project/locations/europe-west1/connectors/my_connector" {
+				return &vpcaccess.Connector{Network: "my_network"}, nil
+			}
+			return nil, errors.New("not found")
+		},
+	}
+	functions := map[string][]*cloudfunctions.Function{
+		"projects/my_project/locations/-": {
+			{
+				Name:        "projects/my_project/locations/europe-west1/functions/resize-images",
+				Environment: "GEN_2",
+				State:       "ACTIVE",
+				Labels:      map[string]string{"team": "media"},
+				BuildConfig: &cloudfunctions.BuildConfig{Runtime: "go121"},
+				ServiceConfig: &cloudfunctions.ServiceConfig{
+					IngressSettings:     "ALLOW_INTERNAL_ONLY",
+					ServiceAccountEmail: "resizer@my_project.iam.gserviceaccount.com",
+					MinInstanceCount:    0,
+					MaxInstanceCount:    100,
+					Uri:                 "https://resize-images-abc-ew.a.run.app",
+					VpcConnector:        "projects/my_project/locations/europe-west1/connectors/my_connector",
+				},
+			},
+			{
+				Name:        "projects/my_project/locations/us-central1/functions/hello",
+				Environment: "GEN_1",
+				State:       "ACTIVE",
+				BuildConfig: &cloudfunctions.BuildConfig{Runtime: "python311"},
+			},
+		},
+		"projects/my_project/locations/us-central1": {
+			{
+				Name:        "projects/my_project/locations/us-central1/functions/hello",
+				Environment: "GEN_1",
+				State:       "ACTIVE",
+				BuildConfig: &cloudfunctions.BuildConfig{Runtime: "python311"},
+			},
+		},
+	}
+	helloEvent := beat.Event{
+		Fields: mapstr.M{
+			"asset.ean":                  "function:projects/my_project/locations/us-central1/functions/hello",
+			"asset.id":                   "projects/my_project/locations/us-central1/functions/hello",
+			"asset.name":                 "hello",
+			"asset.type":                 "gcp.cloudfunctions.function",
+			"asset.kind":                 "function",
+			"asset.parents":              parents,
+			"asset.metadata.state":       "ACTIVE",
+			"asset.metadata.environment": "GEN_1",
+			"asset.metadata.runtime":     "python311",
+			"cloud.account.id":           "my_project",
+			"cloud.provider":             "gcp",
+			"cloud.region":               "us-central1",
+		},
+		Meta: mapstr.M{
+			"index": internal.GetDefaultIndexName(),
+		},
+	}
+
+	for _, tt := range []struct {
+		name string
+
+		cfg            config
+		expectedEvents []beat.Event
+	}{
+		{
+			name: "with no project specified",
+
+			cfg: config{},
+		},
+		{
+			name: "with functions in all locations",
+
+			cfg: config{
+				Projects: []string{"my_project"},
+			},
+			expectedEvents: []beat.Event{
+				{
+					Fields: mapstr.M{
+						"asset.ean":                      "function:projects/my_project/locations/europe-west1/functions/resize-images",
+						"asset.id":                       "projects/my_project/locations/europe-west1/functions/resize-images",
+						"asset.name":                     "resize-images",
+						"asset.type":                     "gcp.cloudfunctions.function",
+						"asset.kind":                     "function",
+						"asset.parents":                  []string{"network:1"},
+						"asset.metadata.state":           "ACTIVE",
+						"asset.metadata.environment":     "GEN_2",
+						"asset.metadata.runtime":         "go121",
+						"asset.metadata.ingress":         "ALLOW_INTERNAL_ONLY",
+						"asset.metadata.service_account": "resizer@my_project.iam.gserviceaccount.com",
+						"asset.metadata.min_instances":   int64(0),
+						"asset.metadata.max_instances":   int64(100),
+						"asset.metadata.uri":             "https://resize-images-abc-ew.a.run.app",
+						"asset.metadata.labels.team":     "media",
+						"cloud.account.id":               "my_project",
+						"cloud.provider":                 "gcp",
+						"cloud.region":                   "europe-west1",
+					},
+					Meta: mapstr.M{
+						"index": internal.GetDefaultIndexName(),
+					},
+				},
+				helloEvent,
+			},
+		},
+		{
+			name: "with regions specified",
+
+			cfg: config{
+				Projects: []string{"my_project"},
+				Regions:  []string{"us-central1"},
+			},
+			expectedEvents: []beat.Event{helloEvent},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			publisher := testutil.NewInMemoryPublisher()
+
+			ctx := context.Background()
+			listClient := listCloudFunctionsAPIClient{
+				List: func(ctx context.Context, parent string) ([]*cloudfunctions.Function, error) {
+					return functions[parent], nil
+				},
+			}
+			log := logp.NewLogger("mylogger")
+			err := collectCloudFunctionsAssets(ctx, tt.cfg, getTestVpcCache(), listClient, connectorClient, publisher, log)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedEvents, publisher.Events)
+		})
+	}
+}
+
+func TestCollectCloudFunctionsAssets_listError(t *testing.T) {
+	publisher := testutil.NewInMemoryPublisher()
+	listClient := listCloudFunctionsAPIClient{
+		List: func(ctx context.Context, parent string) ([]*cloudfunctions.Function, error) {
+			return nil, errors.New("permission denied")
+		},
+	}
+	cfg := config{Projects: []string{"my_project"}}
+
+	err := collectCloudFunctionsAssets(context.Background(), cfg, getTestVpcCache(), listClient, getVPCConnectorAPIClient{}, publisher, logp.NewLogger("mylogger"))
+	assert.ErrorContains(t, err, "project my_project: permission denied")
+	assert.Empty(t, publisher.Events)
+}
@@ -0,0 +1,216 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package gcp
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/api/run/v2"
+
+	"github.com/elastic/assetbeat/input/internal"
+	stateless "github.com/elastic/beats/v7/filebeat/input/v2/input-stateless"
+	"github.com/elastic/elastic-agent-libs/logp"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+	"github.com/elastic/go-freelru"
+)
+
+type listCloudRunAPIClient struct {
+	ListLocations func(ctx context.Context, project string) ([]string, error)
+	ListServices  func(ctx context.Context, parent string) ([]*run.GoogleCloudRunV2Service, error)
+	ListRevisions func(ctx context.Context, service string) ([]*run.GoogleCloudRunV2Revision, error)
+}
+
+type cloudRunService struct {
+	ID        string
+	Name      string
+	FullName  string
+	Region    string
+	Account   string
+	Connector string
+	VPC       string
+	Labels    map[string]string
+	Metadata  mapstr.M
+}
+
+func collectCloudRunAssets(ctx context.Context, cfg config, vpcAssetCache *freelru.LRU[string, *vpc], client listCloudRunAPIClient, connectorClient getVPCConnectorAPIClient, publisher stateless.Publisher, log *logp.Logger) error {
+	services, err := getAllCloudRunServices(ctx, cfg, vpcAssetCache, client)
+	if err != nil {
+		return err
+	}
+
+	assetType := "gcp.cloudrun.service"
+	assetKind := "service"
+	log.Debug("Publishing Cloud Run services and revisions")
+	connectorVpcs := make(map[string]string)
+	for _, service := range services {
+		var parents []string
+		var children []string
+
+		vpcID := service.VPC
+		if service.Connector != "" {
+			vpcID, err = getVpcIdFromConnector(ctx, service.Connector, connectorVpcs, vpcAssetCache, connectorClient)
+			// We should not fail hard here since the core information for the asset comes from the service data
+			if err != nil {
+				log.Warnf("Error while retrieving the VPC of Cloud Run service %s: %+v", service.FullName, err)
+			}
+		}
+		if len(vpcID) > 0 {
+			parents = append(parents, "network:"+vpcID)
+		}
+
+		revisions, err := client.ListRevisions(ctx, service.FullName)
+		if err != nil {
+			log.Warnf("Error while retrieving revisions for Cloud Run service %s: %+v", service.FullName, err)
+		}
+		for _, revision := range revisions {
+			children = append(children, "revision:"+revision.Uid)
+		}
+
+		if internal.IsTypeEnabled(cfg.AssetTypes, assetType) {
+			internal.Publish(publisher, nil,
+				internal.WithAssetCloudProvider("gcp"),
+				internal.WithAssetRegion(service.Region),
+				internal.WithAssetAccountID(service.Account),
+				internal.WithAssetKindAndID(assetKind, service.ID),
+				internal.WithAssetName(service.Name),
+				internal.WithAssetType(assetType),
+				internal.WithAssetParents(parents),
+				internal.WithAssetChildren(children),
+				WithAssetLabels(internal.ToMapstr(service.Labels)),
+				internal.WithAssetMetadata(service.Metadata),
+			)
+		}
+		if internal.IsTypeEnabled(cfg.AssetTypes, "gcp.cloudrun.revision") {
+			for _, revision := range revisions {
+				publishCloudRunRevision(publisher, service, revision)
+			}
+		}
+	}
+
+	return nil
+}
+
+func publishCloudRunRevision(publisher stateless.Publisher, service cloudRunService, revision *run.GoogleCloudRunV2Revision) {
+	assetType := "gcp.cloudrun.revision"
+	assetKind := "revision"
+
+	metadata := mapstr.M{
+		"service_account":       revision.ServiceAccount,
+		"execution_environment": revision.ExecutionEnvironment,
+	}
+	if images := getCloudRunContainerImages(revision.Containers); len(images) > 0 {
+		metadata["images"] = images
+	}
+	if revision.Scaling != nil {
+		metadata["min_instances"] = revision.Scaling.MinInstanceCount
+		metadata["max_instances"] = revision.Scaling.MaxInstanceCount
+	}
+
+	internal.Publish(publisher, nil,
+		internal.WithAssetCloudProvider("gcp"),
+		internal.WithAssetRegion(service.Region),
+		internal.WithAssetAccountID(service.Account),
+		internal.WithAssetKindAndID(assetKind, revision.Uid),
+		internal.WithAssetName(getResourceNameFromURL(revision.Name)),
+		internal.WithAssetType(assetType),
+		internal.WithAssetParents([]string{"service:" + service.ID}),
+		WithAssetLabels(internal.ToMapstr(revision.Labels)),
+		internal.WithAssetMetadata(metadata),
+	)
+}
+
+func getCloudRunContainerImages(containers []*run.GoogleCloudRunV2Container) []string {
+	var images []string
+	for _, c := range containers {
+		if c.Image != "" {
+			images = append(images, c.Image)
+		}
+	}
+	return images
+}
+
+func getAllCloudRunServices(ctx context.Context, cfg config, vpcAssetCache *freelru.LRU[string, *vpc], client listCloudRunAPIClient) ([]cloudRunService, error) {
+	var services []cloudRunService
+	for _, project := range cfg.Projects {
+		// the Cloud Run API does not support listing services across all locations
+		regions := cfg.Regions
+		if len(regions) == 0 {
+			var err error
+			regions, err = client.ListLocations(ctx, project)
+			if err != nil {
+				return nil, fmt.Errorf("error retrieving Cloud Run locations for project %s: %w", project, err)
+			}
+		}
+
+		for _, region := range regions {
+			list, err := client.ListServices(ctx, fmt.Sprintf("projects/%s/locations/%s", project, region))
+			if err != nil {
+				return nil, fmt.Errorf("error retrieving Cloud Run services list for project %s in %s: %w", project, region, err)
+			}
+
+			for _, s := range list {
+				template := s.Template
+				if template == nil {
+					template = &run.GoogleCloudRunV2RevisionTemplate{}
+				}
+				// Cloud Run runs containers rather than language runtimes, the execution environment
+				// and the container images stand for the runtime of the service
+				metadata := mapstr.M{
+					"ingress":               s.Ingress,
+					"service_account":       template.ServiceAccount,
+					"uri":                   s.Uri,
+					"execution_environment": template.ExecutionEnvironment,
+				}
+				if images := getCloudRunContainerImages(template.Containers); len(images) > 0 {
+					metadata["images"] = images
+				}
+				if template.Scaling != nil {
+					metadata["min_instances"] = template.Scaling.MinInstanceCount
+					metadata["max_instances"] = template.Scaling.MaxInstanceCount
+				}
+
+				var connector, vpcID string
+				if vpcAccess := template.VpcAccess; vpcAccess != nil {
+					connector = vpcAccess.Connector
+					// Direct VPC egress references the network without going through a connector
+					for _, ni := range vpcAccess.NetworkInterfaces {
+						if ni.Network != "" {
+							vpcID = getVpcIdFromLink(getNetSelfLinkFromNetworkName(project, ni.Network), vpcAssetCache)
+							break
+						}
+					}
+				}
+
+				services = append(services, cloudRunService{
+					ID:        s.Uid,
+					Name:      getResourceNameFromURL(s.Name),
+					FullName:  s.Name,
+					Region:    getLocationFromResourceName(s.Name),
+					Account:   project,
+					Connector: connector,
+					VPC:       vpcID,
+					Labels:    s.Labels,
+					Metadata:  metadata,
+				})
+			}
+		}
+	}
+
+	return services, nil
+}
@@ -0,0 +1,350 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package gcp
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/api/run/v2"
+	"google.golang.org/api/vpcaccess/v1"
+
+	"github.com/elastic/assetbeat/input/internal"
+	"github.com/elastic/assetbeat/input/testutil"
+	"github.com/elastic/beats/v7/libbeat/beat"
+	"github.com/elastic/elastic-agent-libs/logp"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+type CloudRunClientStub struct {
+	Locations map[string][]string
+	Services  map[string][]*run.GoogleCloudRunV2Service
+	Revisions map[string][]*run.GoogleCloudRunV2Revision
+}
+
+func (s *CloudRunClientStub) apiClient() listCloudRunAPIClient {
+	return listCloudRunAPIClient{
+		ListLocations: func(ctx context.Context, project string) ([]string, error) {
+			return s.Locations[project], nil
+		},
+		ListServices: func(ctx context.Context, parent string) ([]*run.GoogleCloudRunV2Service, error) {
+			return s.Services[parent], nil
+		},
+		ListRevisions: func(ctx context.Context, service string) ([]*run.GoogleCloudRunV2Revision, error) {
+			revisions, ok := s.Revisions[service]
+			if !ok {
+				return nil, errors.New("not found")
+			}
+			return revisions, nil
+		},
+	}
+}
+
+func TestCollectCloudRunAssets(t *testing.T) {
+	var children []string
+	var parents []string
+	connectorClient := getVPCConnectorAPIClient{
+		Get: func(ctx context.Context, name string) (*vpcaccess.Connector, error) {
+			if name == "projects/my_project/locations/europe-west1/connectors/my_connector" {
+				return &vpcaccess.Connector{Network: "my_network"}, nil
+			}
+			return nil, errors.New("not found")
+		},
+	}
+	services := map[string][]*run.GoogleCloudRunV2Service{
+		"projects/my_project/locations/europe-west1": {
+			{
+				Uid:     "a1b2c3",
+				Name:    "projects/my_project/locations/europe-west1/services/frontend",
+				Ingress: "INGRESS_TRAFFIC_ALL",
+				Uri:     "https://frontend-abc-ew.a.run.app",
+				Labels:  map[string]string{"team": "web"},
+				Template: &run.GoogleCloudRunV2RevisionTemplate{
+					ServiceAccount:       "frontend@my_project.iam.gserviceaccount.com",
+					ExecutionEnvironment: "EXECUTION_ENVIRONMENT_GEN2",
+					Containers: []*run.GoogleCloudRunV2Container{
+						{Image: "europe-docker.pkg.dev/my_project/web/frontend:1.2.0"},
+					},
+					Scaling: &run.GoogleCloudRunV2RevisionScaling{
+						MinInstanceCount: 1,
+						MaxInstanceCount: 10,
+					},
+					VpcAccess: &run.GoogleCloudRunV2VpcAccess{
+						Connector: "projects/my_project/locations/europe-west1/connectors/my_connector",
+					},
+				},
+			},
+		},
+		"projects/my_project/locations/us-central1": {
+			{
+				Uid:     "d4e5f6",
+				Name:    "projects/my_project/locations/us-central1/services/backend",
+				Ingress: "INGRESS_TRAFFIC_INTERNAL_ONLY",
+				Template: &run.GoogleCloudRunV2RevisionTemplate{
+					VpcAccess: &run.GoogleCloudRunV2VpcAccess{
+						NetworkInterfaces: []*run.GoogleCloudRunV2NetworkInterface{
+							{Network: "my_network", Subnetwork: "my_subnet"},
+						},
+					},
+				},
+			},
+		},
+		"projects/my_second_project/locations/asia-east1": {
+			{
+				Uid:  "g7h8i9",
+				Name: "projects/my_second_project/locations/asia-east1/services/worker",
+				Template: &run.GoogleCloudRunV2RevisionTemplate{
+					VpcAccess: &run.GoogleCloudRunV2VpcAccess{
+						Connector: "projects/my_second_project/locations/asia-east1/connectors/deleted_connector",
+					},
+				},
+			},
+		},
+	}
+	revisions := map[string][]*run.GoogleCloudRunV2Revision{
+		"projects/my_project/locations/europe-west1/services/frontend": {
+			{
+				Uid:                  "r1",
+				Name:                 "projects/my_project/locations/europe-west1/services/frontend/revisions/frontend-00002-xyz",
+				ServiceAccount:       "frontend@my_project.iam.gserviceaccount.com",
+				ExecutionEnvironment: "EXECUTION_ENVIRONMENT_GEN2",
+				Containers: []*run.GoogleCloudRunV2Container{
+					{Image: "europe-docker.pkg.dev/my_project/web/frontend:1.2.0"},
+				},
+				Scaling: &run.GoogleCloudRunV2RevisionScaling{
+					MinInstanceCount: 1,
+					MaxInstanceCount: 10,
+				},
+				Labels: map[string]string{"team": "web"},
+			},
+			{
+				Uid:  "r2",
+				Name: "projects/my_project/locations/europe-west1/services/frontend/revisions/frontend-00001-abc",
+			},
+		},
+	}
+	revisionEvents := []beat.Event{
+		{
+			Fields: mapstr.M{
+				"asset.ean":                            "revision:r1",
+				"asset.id":                             "r1",
+				"asset.name":                           "frontend-00002-xyz",
+				"asset.type":                           "gcp.cloudrun.revision",
+				"asset.kind":                           "revision",
+				"asset.parents":                        []string{"service:a1b2c3"},
+				"asset.metadata.service_account":       "frontend@my_project.iam.gserviceaccount.com",
+				"asset.metadata.execution_environment": "EXECUTION_ENVIRONMENT_GEN2",
+				"asset.metadata.images":                []string{"europe-docker.pkg.dev/my_project/web/frontend:1.2.0"},
+				"asset.metadata.min_instances":         int64(1),
+				"asset.metadata.max_instances":         int64(10),
+				"asset.metadata.labels.team":           "web",
+				"cloud.account.id":                     "my_project",
+				"cloud.provider":                       "gcp",
+				"cloud.region":                         "europe-west1",
+			},
+			Meta: mapstr.M{
+				"index": internal.GetDefaultIndexName(),
+			},
+		},
+		{
+			Fields: mapstr.M{
+				"asset.ean":                            "revision:r2",
+				"asset.id":                             "r2",
+				"asset.name":                           "frontend-00001-abc",
+				"asset.type":                           "gcp.cloudrun.revision",
+				"asset.kind":                           "revision",
+				"asset.parents":                        []string{"service:a1b2c3"},
+				"asset.metadata.service_account":       "",
+				"asset.metadata.execution_environment": "",
+				"cloud.account.id":                     "my_project",
+				"cloud.provider":                       "gcp",
+				"cloud.region":                         "europe-west1",
+			},
+			Meta: mapstr.M{
+				"index": internal.GetDefaultIndexName(),
+			},
+		},
+	}
+
+	for _, tt := range []struct {
+		name string
+
+		cfg            config
+		locations      map[string][]string
+		expectedEvents []beat.Event
+	}{
+		{
+			name: "with no project specified",
+
+			cfg: config{},
+		},
+		{
+			name: "with locations discovered from the API",
+
+			cfg: config{
+				Projects: []string{"my_project"},
+			},
+			locations: map[string][]string{
+				"my_project": {"europe-west1", "us-central1"},
+			},
+			expectedEvents: []beat.Event{
+				{
+					Fields: mapstr.M{
+						"asset.ean":                            "service:a1b2c3",
+						"asset.id":                             "a1b2c3",
+						"asset.name":                           "frontend",
+						"asset.type":                           "gcp.cloudrun.service",
+						"asset.kind":                           "service",
+						"asset.parents":                        []string{"network:1"},
+						"asset.children":                       []string{"revision:r1", "revision:r2"},
+						"asset.metadata.ingress":               "INGRESS_TRAFFIC_ALL",
+						"asset.metadata.service_account":       "frontend@my_project.iam.gserviceaccount.com",
+						"asset.metadata.uri":                   "https://frontend-abc-ew.a.run.app",
+						"asset.metadata.execution_environment": "EXECUTION_ENVIRONMENT_GEN2",
+						"asset.metadata.images":                []string{"europe-docker.pkg.dev/my_project/web/frontend:1.2.0"},
+						"asset.metadata.min_instances":         int64(1),
+						"asset.metadata.max_instances":         int64(10),
+						"asset.metadata.labels.team":           "web",
+						"cloud.account.id":                     "my_project",
+						"cloud.provider":                       "gcp",
+						"cloud.region":                         "europe-west1",
+					},
+					Meta: mapstr.M{
+						"index": internal.GetDefaultIndexName(),
+					},
+				},
+				revisionEvents[0],
+				revisionEvents[1],
+				{
+					Fields: mapstr.M{
+						"asset.ean":                            "service:d4e5f6",
+						"asset.id":                             "d4e5f6",
+						"asset.name":                           "backend",
+						"asset.type":                           "gcp.cloudrun.service",
+						"asset.kind":                           "service",
+						"asset.parents":                        []string{"network:1"},
+						"asset.children":                       children,
+						"asset.metadata.ingress":               "INGRESS_TRAFFIC_INTERNAL_ONLY",
+						"asset.metadata.service_account":       "",
+						"asset.metadata.uri":                   "",
+						"asset.metadata.execution_environment": "",
+						"cloud.account.id":                     "my_project",
+						"cloud.provider":                       "gcp",
+						"cloud.region":                         "us-central1",
+					},
+					Meta: mapstr.M{
+						"index": internal.GetDefaultIndexName(),
+					},
+				},
+			},
+		},
+		{
+			name: "with regions specified",
+
+			cfg: config{
+				Projects: []string{"my_project"},
+				Regions:  []string{"us-central1"},
+			},
+			expectedEvents: []beat.Event{
+				{
+					Fields: mapstr.M{
+						"asset.ean":                            "service:d4e5f6",
+						"asset.id":                             "d4e5f6",
+						"asset.name":                           "backend",
+						"asset.type":                           "gcp.cloudrun.service",
+						"asset.kind":                           "service",
+						"asset.parents":                        []string{"network:1"},
+						"asset.children":                       children,
+						"asset.metadata.ingress":               "INGRESS_TRAFFIC_INTERNAL_ONLY",
+						"asset.metadata.service_account":       "",
+						"asset.metadata.uri":                   "",
+						"asset.metadata.execution_environment": "",
+						"cloud.account.id":                     "my_project",
+						"cloud.provider":                       "gcp",
+						"cloud.region":                         "us-central1",
+					},
+					Meta: mapstr.M{
+						"index": internal.GetDefaultIndexName(),
+					},
+				},
+			},
+		},
+		{
+			name: "with an unknown VPC connector",
+
+			cfg: config{
+				Projects: []string{"my_second_project"},
+				Regions:  []string{"asia-east1"},
+			},
+			expectedEvents: []beat.Event{
+				{
+					Fields: mapstr.M{
+						"asset.ean":                            "service:g7h8i9",
+						"asset.id":                             "g7h8i9",
+						"asset.name":                           "worker",
+						"asset.type":                           "gcp.cloudrun.service",
+						"asset.kind":                           "service",
+						"asset.parents":                        parents,
+						"asset.children":                       children,
+						"asset.metadata.ingress":               "",
+						"asset.metadata.service_account":       "",
+						"asset.metadata.uri":                   "",
+						"asset.metadata.execution_environment": "",
+						"cloud.account.id":                     "my_second_project",
+						"cloud.provider":                       "gcp",
+						"cloud.region":                         "asia-east1",
+					},
+					Meta: mapstr.M{
+						"index": internal.GetDefaultIndexName(),
+					},
+				},
+			},
+		},
+		{
+			name: "with only revisions enabled",
+
+			cfg: config{
+				BaseConfig: internal.BaseConfig{AssetTypes: []string{"gcp.cloudrun.revision"}},
+				Projects:   []string{"my_project"},
+				Regions:    []string{"europe-west1"},
+			},
+			expectedEvents: revisionEvents,
+		},
+		{
+			name: "with a project without services",
+
+			cfg: config{
+				Projects: []string{"my_second_project"},
+				Regions:  []string{"europe-west1"},
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			publisher := testutil.NewInMemoryPublisher()
+
+			ctx := context.Background()
+			client := CloudRunClientStub{Locations: tt.locations, Services: services, Revisions: revisions}
+			log := logp.NewLogger("mylogger")
+			err := collectCloudRunAssets(ctx, tt.cfg, getTestVpcCache(), client.apiClient(), connectorClient, publisher, log)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedEvents, publisher.Events)
+		})
+	}
+}
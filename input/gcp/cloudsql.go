@@ -0,0 +1,128 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package gcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"google.golang.org/api/sqladmin/v1"
+
+	"github.com/elastic/assetbeat/input/internal"
+	stateless "github.com/elastic/beats/v7/filebeat/input/v2/input-stateless"
+	"github.com/elastic/elastic-agent-libs/logp"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+	"github.com/elastic/go-freelru"
+)
+
+type listCloudSQLInstancesAPIClient struct {
+	List func(ctx context.Context, project string) ([]*sqladmin.DatabaseInstance, error)
+}
+
+type cloudSQLInstance struct {
+	ID       string
+	Name     string
+	Region   string
+	Account  string
+	VPC      string
+	Labels   map[string]string
+	Metadata mapstr.M
+}
+
+func collectCloudSQLAssets(ctx context.Context, cfg config, vpcAssetCache *freelru.LRU[string, *vpc], client listCloudSQLInstancesAPIClient, publisher stateless.Publisher, log *logp.Logger) error {
+	instances, err := getAllCloudSQLInstances(ctx, cfg, vpcAssetCache, client)
+	if err != nil {
+		return err
+	}
+
+	assetType := "gcp.cloudsql.instance"
+	assetKind := "database_server"
+	log.Debug("Publishing Cloud SQL instances")
+	for _, instance := range instances {
+		var parents []string
+		if len(instance.VPC) > 0 {
+			parents = append(parents, "network:"+instance.VPC)
+		}
+
+		internal.Publish(publisher, nil,
+			internal.WithAssetCloudProvider("gcp"),
+			internal.WithAssetRegion(instance.Region),
+			internal.WithAssetAccountID(instance.Account),
+			internal.WithAssetKindAndID(assetKind, instance.ID),
+			internal.WithAssetName(instance.Name),
+			internal.WithAssetType(assetType),
+			internal.WithAssetParents(parents),
+			WithAssetLabels(internal.ToMapstr(instance.Labels)),
+			internal.WithAssetMetadata(instance.Metadata),
+		)
+	}
+
+	return nil
+}
+
+func getAllCloudSQLInstances(ctx context.Context, cfg config, vpcAssetCache *freelru.LRU[string, *vpc], client listCloudSQLInstancesAPIClient) ([]cloudSQLInstance, error) {
+	var instances []cloudSQLInstance
+	for _, project := range cfg.Projects {
+		list, err := client.List(ctx, project)
+		if err != nil {
+			return nil, fmt.Errorf("error retrieving Cloud SQL instances list for project %s: %w", project, err)
+		}
+
+		for _, i := range list {
+			if !wantRegion(i.Region, cfg.Regions) {
+				continue
+			}
+			settings := i.Settings
+			if settings == nil {
+				settings = &sqladmin.Settings{}
+			}
+			var vpcID string
+			ipConfiguration := mapstr.M{}
+			if ipc := settings.IpConfiguration; ipc != nil {
+				ipConfiguration["ipv4_enabled"] = ipc.Ipv4Enabled
+				ipConfiguration["require_ssl"] = ipc.RequireSsl
+				if ipc.PrivateNetwork != "" {
+					ipConfiguration["private_network"] = ipc.PrivateNetwork
+					vpcID = getVpcIdFromLink(getNetSelfLinkFromPath(ipc.PrivateNetwork), vpcAssetCache)
+				}
+			}
+			for _, ip := range i.IpAddresses {
+				ipConfiguration[strings.ToLower(ip.Type)+"_ip_address"] = ip.IpAddress
+			}
+
+			instances = append(instances, cloudSQLInstance{
+				ID:      i.ConnectionName,
+				Name:    i.Name,
+				Region:  i.Region,
+				Account: project,
+				VPC:     vpcID,
+				Labels:  settings.UserLabels,
+				Metadata: mapstr.M{
+					"state":             i.State,
+					"database_version":  i.DatabaseVersion,
+					"tier":              settings.Tier,
+					"availability_type": settings.AvailabilityType,
+					"ip_configuration":  ipConfiguration,
+				},
+			})
+		}
+	}
+
+	return instances, nil
+}
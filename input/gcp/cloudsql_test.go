@@ -0,0 +1,220 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package gcp
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/api/sqladmin/v1"
+
+	"github.com/elastic/assetbeat/input/internal"
+	"github.com/elastic/assetbeat/input/testutil"
+	"github.com/elastic/beats/v7/libbeat/beat"
+	"github.com/elastic/elastic-agent-libs/logp"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+func TestCollectCloudSQLAssets(t *testing.T) {
+	var parents []string
+	for _, tt := range []struct {
+		name string
+
+		cfg            config
+		instances      map[string][]*sqladmin.DatabaseInstance
+		expectedEvents []beat.Event
+	}{
+		{
+			name: "with no project specified",
+
+			cfg: config{},
+		},
+		{
+			name: "with private and public instances",
+
+			cfg: config{
+				Projects: []string{"my_project"},
+			},
+			instances: map[string][]*sqladmin.DatabaseInstance{
+				"my_project": {
+					{
+						ConnectionName:  "my_project:europe-west1:private-db",
+						Name:            "private-db",
+						Region:          "europe-west1",
+						State:           "RUNNABLE",
+						DatabaseVersion: "POSTGRES_15",
+						IpAddresses: []*sqladmin.IpMapping{
+							{Type: "PRIVATE", IpAddress: "10.0.0.3"},
+						},
+						Settings: &sqladmin.Settings{
+							Tier:             "db-custom-2-7680",
+							AvailabilityType: "REGIONAL",
+							IpConfiguration: &sqladmin.IpConfiguration{
+								PrivateNetwork: "projects/my_project/global/networks/my_network",
+								RequireSsl:     true,
+							},
+							UserLabels: map[string]string{"env": "prod"},
+						},
+					},
+					{
+						ConnectionName:  "my_project:us-central1:public-db",
+						Name:            "public-db",
+						Region:          "us-central1",
+						State:           "STOPPED",
+						DatabaseVersion: "MYSQL_8_0",
+						IpAddresses: []*sqladmin.IpMapping{
+							{Type: "PRIMARY", IpAddress: "34.1.2.3"},
+						},
+						Settings: &sqladmin.Settings{
+							Tier:             "db-f1-micro",
+							AvailabilityType: "ZONAL",
+							IpConfiguration: &sqladmin.IpConfiguration{
+								Ipv4Enabled: true,
+							},
+						},
+					},
+				},
+			},
+			expectedEvents: []beat.Event{
+				{
+					Fields: mapstr.M{
+						"asset.ean":                        "database_server:my_project:europe-west1:private-db",
+						"asset.id":                         "my_project:europe-west1:private-db",
+						"asset.name":                       "private-db",
+						"asset.type":                       "gcp.cloudsql.instance",
+						"asset.kind":                       "database_server",
+						"asset.parents":                    []string{"network:1"},
+						"asset.metadata.state":             "RUNNABLE",
+						"asset.metadata.database_version":  "POSTGRES_15",
+						"asset.metadata.tier":              "db-custom-2-7680",
+						"asset.metadata.availability_type": "REGIONAL",
+						"asset.metadata.ip_configuration.ipv4_enabled":       false,
+						"asset.metadata.ip_configuration.require_ssl":        true,
+						"asset.metadata.ip_configuration.private_network":    "projects/my_project/global/networks/my_network",
+						"asset.metadata.ip_configuration.private_ip_address": "10.0.0.3",
+						"asset.metadata.labels.env":                          "prod",
+						"cloud.account.id":                                   "my_project",
+						"cloud.provider":                                     "gcp",
+						"cloud.region":                                       "europe-west1",
+					},
+					Meta: mapstr.M{
+						"index": internal.GetDefaultIndexName(),
+					},
+				},
+				{
+					Fields: mapstr.M{
+						"asset.ean":                        "database_server:my_project:us-central1:public-db",
+						"asset.id":                         "my_project:us-central1:public-db",
+						"asset.name":                       "public-db",
+						"asset.type":                       "gcp.cloudsql.instance",
+						"asset.kind":                       "database_server",
+						"asset.parents":                    parents,
+						"asset.metadata.state":             "STOPPED",
+						"asset.metadata.database_version":  "MYSQL_8_0",
+						"asset.metadata.tier":              "db-f1-micro",
+						"asset.metadata.availability_type": "ZONAL",
+						"asset.metadata.ip_configuration.ipv4_enabled":       true,
+						"asset.metadata.ip_configuration.require_ssl":        false,
+						"asset.metadata.ip_configuration.primary_ip_address": "34.1.2.3",
+						"cloud.account.id": "my_project",
+						"cloud.provider":   "gcp",
+						"cloud.region":     "us-central1",
+					},
+					Meta: mapstr.M{
+						"index": internal.GetDefaultIndexName(),
+					},
+				},
+			},
+		},
+		{
+			name: "with a region filter",
+
+			cfg: config{
+				Projects: []string{"my_project"},
+				Regions:  []string{"us-central1"},
+			},
+			instances: map[string][]*sqladmin.DatabaseInstance{
+				"my_project": {
+					{
+						ConnectionName: "my_project:europe-west1:private-db",
+						Name:           "private-db",
+						Region:         "europe-west1",
+					},
+					{
+						ConnectionName: "my_project:us-central1:public-db",
+						Name:           "public-db",
+						Region:         "us-central1",
+					},
+				},
+			},
+			expectedEvents: []beat.Event{
+				{
+					Fields: mapstr.M{
+						"asset.ean":                        "database_server:my_project:us-central1:public-db",
+						"asset.id":                         "my_project:us-central1:public-db",
+						"asset.name":                       "public-db",
+						"asset.type":                       "gcp.cloudsql.instance",
+						"asset.kind":                       "database_server",
+						"asset.parents":                    parents,
+						"asset.metadata.state":             "",
+						"asset.metadata.database_version":  "",
+						"asset.metadata.tier":              "",
+						"asset.metadata.availability_type": "",
+						"cloud.account.id":                 "my_project",
+						"cloud.provider":                   "gcp",
+						"cloud.region":                     "us-central1",
+					},
+					Meta: mapstr.M{
+						"index": internal.GetDefaultIndexName(),
+					},
+				},
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			publisher := testutil.NewInMemoryPublisher()
+
+			ctx := context.Background()
+			listClient := listCloudSQLInstancesAPIClient{
+				List: func(ctx context.Context, project string) ([]*sqladmin.DatabaseInstance, error) {
+					return tt.instances[project], nil
+				},
+			}
+			log := logp.NewLogger("mylogger")
+			err := collectCloudSQLAssets(ctx, tt.cfg, getTestVpcCache(), listClient, publisher, log)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedEvents, publisher.Events)
+		})
+	}
+}
+
+func TestCollectCloudSQLAssets_listError(t *testing.T) {
+	publisher := testutil.NewInMemoryPublisher()
+	listClient := listCloudSQLInstancesAPIClient{
+		List: func(ctx context.Context, project string) ([]*sqladmin.DatabaseInstance, error) {
+			return nil, errors.New("permission denied")
+		},
+	}
+	cfg := config{Projects: []string{"my_project"}}
+
+	err := collectCloudSQLAssets(context.Background(), cfg, getTestVpcCache(), listClient, publisher, logp.NewLogger("mylogger"))
+	assert.ErrorContains(t, err, "project my_project: permission denied")
+	assert.Empty(t, publisher.Events)
+}
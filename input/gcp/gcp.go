@@ -25,7 +25,13 @@ import (
 	"cloud.google.com/go/compute/apiv1/computepb"
 	container "cloud.google.com/go/container/apiv1"
 	"github.com/googleapis/gax-go/v2"
+	"google.golang.org/api/cloudfunctions/v2"
 	"google.golang.org/api/option"
+	runv1 "google.golang.org/api/run/v1"
+	runv2 "google.golang.org/api/run/v2"
+	"google.golang.org/api/sqladmin/v1"
+	"google.golang.org/api/storage/v1"
+	"google.golang.org/api/vpcaccess/v1"
 
 	"github.com/elastic/assetbeat/input/internal"
 	input "github.com/elastic/beats/v7/filebeat/input/v2"
@@ -217,6 +223,141 @@ func (s *assetsGCP) collectAll(ctx context.Context, log *logp.Logger, publisher
 			}
 		}()
 	}
+	if internal.IsTypeEnabled(s.config.AssetTypes, "gcp.cloudsql.instance") {
+		go func() {
+			service, err := sqladmin.NewService(ctx, buildClientOptions(s.config)...)
+			if err != nil {
+				log.Errorf("error collecting Cloud SQL assets: %+v", err)
+				return
+			}
+			listClient := listCloudSQLInstancesAPIClient{
+				List: func(ctx context.Context, project string) ([]*sqladmin.DatabaseInstance, error) {
+					var instances []*sqladmin.DatabaseInstance
+					err := service.Instances.List(project).Pages(ctx, func(resp *sqladmin.InstancesListResponse) error {
+						instances = append(instances, resp.Items...)
+						return nil
+					})
+					return instances, err
+				},
+			}
+			err = collectCloudSQLAssets(ctx, s.config, s.VpcAssetsCache, listClient, publisher, log)
+			if err != nil {
+				log.Errorf("error collecting Cloud SQL assets: %+v", err)
+			}
+		}()
+	}
+	if internal.IsTypeEnabled(s.config.AssetTypes, "gcp.gcs.bucket") {
+		go func() {
+			service, err := storage.NewService(ctx, buildClientOptions(s.config)...)
+			if err != nil {
+				log.Errorf("error collecting Cloud Storage assets: %+v", err)
+				return
+			}
+			listClient := listBucketsAPIClient{
+				List: func(ctx context.Context, project string) ([]*storage.Bucket, error) {
+					var buckets []*storage.Bucket
+					err := service.Buckets.List(project).Pages(ctx, func(resp *storage.Buckets) error {
+						buckets = append(buckets, resp.Items...)
+						return nil
+					})
+					return buckets, err
+				},
+			}
+			err = collectGCSAssets(ctx, s.config, listClient, publisher, log)
+			if err != nil {
+				log.Errorf("error collecting Cloud Storage assets: %+v", err)
+			}
+		}()
+	}
+	if internal.IsTypeEnabled(s.config.AssetTypes, "gcp.cloudrun.service") || internal.IsTypeEnabled(s.config.AssetTypes, "gcp.cloudrun.revision") {
+		go func() {
+			service, err := runv2.NewService(ctx, buildClientOptions(s.config)...)
+			if err != nil {
+				log.Errorf("error collecting Cloud Run assets: %+v", err)
+				return
+			}
+			locationsService, err := runv1.NewService(ctx, buildClientOptions(s.config)...)
+			if err != nil {
+				log.Errorf("error collecting Cloud Run assets: %+v", err)
+				return
+			}
+			connectorService, err := vpcaccess.NewService(ctx, buildClientOptions(s.config)...)
+			if err != nil {
+				log.Errorf("error collecting Cloud Run assets: %+v", err)
+				return
+			}
+			listClient := listCloudRunAPIClient{
+				ListLocations: func(ctx context.Context, project string) ([]string, error) {
+					var locations []string
+					err := locationsService.Projects.Locations.List("projects/"+project).Pages(ctx, func(resp *runv1.ListLocationsResponse) error {
+						for _, l := range resp.Locations {
+							locations = append(locations, l.LocationId)
+						}
+						return nil
+					})
+					return locations, err
+				},
+				ListServices: func(ctx context.Context, parent string) ([]*runv2.GoogleCloudRunV2Service, error) {
+					var services []*runv2.GoogleCloudRunV2Service
+					err := service.Projects.Locations.Services.List(parent).Pages(ctx, func(resp *runv2.GoogleCloudRunV2ListServicesResponse) error {
+						services = append(services, resp.Services...)
+						return nil
+					})
+					return services, err
+				},
+				ListRevisions: func(ctx context.Context, parent string) ([]*runv2.GoogleCloudRunV2Revision, error) {
+					var revisions []*runv2.GoogleCloudRunV2Revision
+					err := service.Projects.Locations.Services.Revisions.List(parent).Pages(ctx, func(resp *runv2.GoogleCloudRunV2ListRevisionsResponse) error {
+						revisions = append(revisions, resp.Revisions...)
+						return nil
+					})
+					return revisions, err
+				},
+			}
+			connectorClient := getVPCConnectorAPIClient{
+				Get: func(ctx context.Context, name string) (*vpcaccess.Connector, error) {
+					return connectorService.Projects.Locations.Connectors.Get(name).Context(ctx).Do()
+				},
+			}
+			err = collectCloudRunAssets(ctx, s.config, s.VpcAssetsCache, listClient, connectorClient, publisher, log)
+			if err != nil {
+				log.Errorf("error collecting Cloud Run assets: %+v", err)
+			}
+		}()
+	}
+	if internal.IsTypeEnabled(s.config.AssetTypes, "gcp.cloudfunctions.function") {
+		go func() {
+			service, err := cloudfunctions.NewService(ctx, buildClientOptions(s.config)...)
+			if err != nil {
+				log.Errorf("error collecting Cloud Functions assets: %+v", err)
+				return
+			}
+			connectorService, err := vpcaccess.NewService(ctx, buildClientOptions(s.config)...)
+			if err != nil {
+				log.Errorf("error collecting Cloud Functions assets: %+v", err)
+				return
+			}
+			listClient := listCloudFunctionsAPIClient{
+				List: func(ctx context.Context, parent string) ([]*cloudfunctions.Function, error) {
+					var functions []*cloudfunctions.Function
+					err := service.Projects.Locations.Functions.List(parent).Pages(ctx, func(resp *cloudfunctions.ListFunctionsResponse) error {
+						functions = append(functions, resp.Functions...)
+						return nil
+					})
+					return functions, err
+				},
+			}
+			connectorClient := getVPCConnectorAPIClient{
+				Get: func(ctx context.Context, name string) (*vpcaccess.Connector, error) {
+					return connectorService.Projects.Locations.Connectors.Get(name).Context(ctx).Do()
+				},
+			}
+			err = collectCloudFunctionsAssets(ctx, s.config, s.VpcAssetsCache, listClient, connectorClient, publisher, log)
+			if err != nil {
+				log.Errorf("error collecting Cloud Functions assets: %+v", err)
+			}
+		}()
+	}
 	return nil
 }
 
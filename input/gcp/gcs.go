@@ -0,0 +1,135 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package gcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"google.golang.org/api/storage/v1"
+
+	"github.com/elastic/assetbeat/input/internal"
+	stateless "github.com/elastic/beats/v7/filebeat/input/v2/input-stateless"
+	"github.com/elastic/elastic-agent-libs/logp"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+type listBucketsAPIClient struct {
+	List func(ctx context.Context, project string) ([]*storage.Bucket, error)
+}
+
+type gcsBucket struct {
+	ID       string
+	Region   string
+	Account  string
+	Labels   map[string]string
+	Metadata mapstr.M
+}
+
+func collectGCSAssets(ctx context.Context, cfg config, client listBucketsAPIClient, publisher stateless.Publisher, log *logp.Logger) error {
+	buckets, err := getAllGCSBuckets(ctx, cfg, client)
+	if err != nil {
+		return err
+	}
+
+	assetType := "gcp.gcs.bucket"
+	assetKind := "bucket"
+	log.Debug("Publishing Cloud Storage buckets")
+	for _, bucket := range buckets {
+		internal.Publish(publisher, nil,
+			internal.WithAssetCloudProvider("gcp"),
+			internal.WithAssetRegion(bucket.Region),
+			internal.WithAssetAccountID(bucket.Account),
+			internal.WithAssetKindAndID(assetKind, bucket.ID),
+			internal.WithAssetName(bucket.ID),
+			internal.WithAssetType(assetType),
+			WithAssetLabels(internal.ToMapstr(bucket.Labels)),
+			internal.WithAssetMetadata(bucket.Metadata),
+		)
+	}
+
+	return nil
+}
+
+func getAllGCSBuckets(ctx context.Context, cfg config, client listBucketsAPIClient) ([]gcsBucket, error) {
+	var buckets []gcsBucket
+	for _, project := range cfg.Projects {
+		list, err := client.List(ctx, project)
+		if err != nil {
+			return nil, fmt.Errorf("error retrieving Cloud Storage buckets list for project %s: %w", project, err)
+		}
+
+		for _, b := range list {
+			// bucket locations are upper case, e.g. EUROPE-WEST1 or the US multi-region
+			location := strings.ToLower(b.Location)
+			if !wantGCSBucketLocation(b, location, cfg.Regions) {
+				continue
+			}
+			buckets = append(buckets, gcsBucket{
+				ID:      b.Name,
+				Region:  location,
+				Account: project,
+				Labels:  b.Labels,
+				Metadata: mapstr.M{
+					"storage_class": b.StorageClass,
+					"location":      b.Location,
+					"location_type": b.LocationType,
+				},
+			})
+		}
+	}
+
+	return buckets, nil
+}
+
+// gcsPredefinedDualRegions are the regions of the predefined dual-regions. Configurable dual-regions list
+// their regions in the custom placement configuration of the bucket instead.
+var gcsPredefinedDualRegions = map[string][]string{
+	"asia1": {"asia-northeast1", "asia-northeast2"},
+	"eur4":  {"europe-north1", "europe-west4"},
+	"nam4":  {"us-central1", "us-east1"},
+}
+
+// wantGCSBucketLocation reports whether a bucket is in one of the configured regions, or its location is configured.
+// A dual-region bucket is in each of its two regions, and is always collected when they are unknown. A multi-region
+// bucket, whose data can be stored in any region of a large geographic area such as the US, is always collected.
+func wantGCSBucketLocation(b *storage.Bucket, location string, regions []string) bool {
+	if wantRegion(location, regions) {
+		return true
+	}
+	switch strings.ToLower(b.LocationType) {
+	case "multi-region":
+		return true
+	case "dual-region":
+		dataLocations := gcsPredefinedDualRegions[location]
+		if b.CustomPlacementConfig != nil && len(b.CustomPlacementConfig.DataLocations) > 0 {
+			dataLocations = b.CustomPlacementConfig.DataLocations
+		}
+		// the regions of dual-regions that are not known yet can't be filtered on
+		if len(dataLocations) == 0 {
+			return true
+		}
+		for _, dataLocation := range dataLocations {
+			if wantRegion(strings.ToLower(dataLocation), regions) {
+				return true
+			}
+		}
+	}
+	return false
+}
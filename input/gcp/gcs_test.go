@@ -0,0 +1,241 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package gcp
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/api/storage/v1"
+
+	"github.com/elastic/assetbeat/input/internal"
+	"github.com/elastic/assetbeat/input/testutil"
+	"github.com/elastic/beats/v7/libbeat/beat"
+	"github.com/elastic/elastic-agent-libs/logp"
+	"github.com/elastic/elastic-agent-libs/mapstr"
+)
+
+func TestCollectGCSAssets(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+
+		cfg            config
+		buckets        map[string][]*storage.Bucket
+		expectedEvents []beat.Event
+	}{
+		{
+			name: "with no project specified",
+
+			cfg: config{},
+		},
+		{
+			name: "with multiple projects specified",
+
+			cfg: config{
+				Projects: []string{"my_project", "my_second_project"},
+			},
+			buckets: map[string][]*storage.Bucket{
+				"my_project": {
+					{
+						Name:         "my-bucket",
+						Location:     "EUROPE-WEST1",
+						LocationType: "region",
+						StorageClass: "STANDARD",
+						Labels:       map[string]string{"team": "observability"},
+					},
+				},
+				"my_second_project": {
+					{
+						Name:         "my-archive",
+						Location:     "US",
+						LocationType: "multi-region",
+						StorageClass: "ARCHIVE",
+					},
+				},
+			},
+			expectedEvents: []beat.Event{
+				{
+					Fields: mapstr.M{
+						"asset.ean":                    "bucket:my-bucket",
+						"asset.id":                     "my-bucket",
+						"asset.name":                   "my-bucket",
+						"asset.type":                   "gcp.gcs.bucket",
+						"asset.kind":                   "bucket",
+						"asset.metadata.storage_class": "STANDARD",
+						"asset.metadata.location":      "EUROPE-WEST1",
+						"asset.metadata.location_type": "region",
+						"asset.metadata.labels.team":   "observability",
+						"cloud.account.id":             "my_project",
+						"cloud.provider":               "gcp",
+						"cloud.region":                 "europe-west1",
+					},
+					Meta: mapstr.M{
+						"index": internal.GetDefaultIndexName(),
+					},
+				},
+				{
+					Fields: mapstr.M{
+						"asset.ean":                    "bucket:my-archive",
+						"asset.id":                     "my-archive",
+						"asset.name":                   "my-archive",
+						"asset.type":                   "gcp.gcs.bucket",
+						"asset.kind":                   "bucket",
+						"asset.metadata.storage_class": "ARCHIVE",
+						"asset.metadata.location":      "US",
+						"asset.metadata.location_type": "multi-region",
+						"cloud.account.id":             "my_second_project",
+						"cloud.provider":               "gcp",
+						"cloud.region":                 "us",
+					},
+					Meta: mapstr.M{
+						"index": internal.GetDefaultIndexName(),
+					},
+				},
+			},
+		},
+		{
+			name: "with a region filter",
+
+			cfg: config{
+				Projects: []string{"my_project"},
+				Regions:  []string{"us"},
+			},
+			buckets: map[string][]*storage.Bucket{
+				"my_project": {
+					{
+						Name:         "my-bucket",
+						Location:     "EUROPE-WEST1",
+						StorageClass: "STANDARD",
+					},
+					{
+						Name:         "my-archive",
+						Location:     "US",
+						StorageClass: "ARCHIVE",
+					},
+				},
+			},
+			expectedEvents: []beat.Event{
+				{
+					Fields: mapstr.M{
+						"asset.ean":                    "bucket:my-archive",
+						"asset.id":                     "my-archive",
+						"asset.name":                   "my-archive",
+						"asset.type":                   "gcp.gcs.bucket",
+						"asset.kind":                   "bucket",
+						"asset.metadata.storage_class": "ARCHIVE",
+						"asset.metadata.location":      "US",
+						"asset.metadata.location_type": "",
+						"cloud.account.id":             "my_project",
+						"cloud.provider":               "gcp",
+						"cloud.region":                 "us",
+					},
+					Meta: mapstr.M{
+						"index": internal.GetDefaultIndexName(),
+					},
+				},
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			publisher := testutil.NewInMemoryPublisher()
+
+			ctx := context.Background()
+			listClient := listBucketsAPIClient{
+				List: func(ctx context.Context, project string) ([]*storage.Bucket, error) {
+					return tt.buckets[project], nil
+				},
+			}
+			log := logp.NewLogger("mylogger")
+			err := collectGCSAssets(ctx, tt.cfg, listClient, publisher, log)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedEvents, publisher.Events)
+		})
+	}
+}
+
+func TestCollectGCSAssets_listError(t *testing.T) {
+	publisher := testutil.NewInMemoryPublisher()
+	listClient := listBucketsAPIClient{
+		List: func(ctx context.Context, project string) ([]*storage.Bucket, error) {
+			return nil, errors.New("permission denied")
+		},
+	}
+	cfg := config{Projects: []string{"my_project"}}
+
+	err := collectGCSAssets(context.Background(), cfg, listClient, publisher, logp.NewLogger("mylogger"))
+	assert.ErrorContains(t, err, "project my_project: permission denied")
+	assert.Empty(t, publisher.Events)
+}
+
+func TestWantGCSBucketLocation(t *testing.T) {
+	regions := []string{"us-east1"}
+	for _, tt := range []struct {
+		name     string
+		bucket   *storage.Bucket
+		expected bool
+	}{
+		{
+			name:     "in a configured region",
+			bucket:   &storage.Bucket{Location: "US-EAST1", LocationType: "region"},
+			expected: true,
+		},
+		{
+			name:   "in another region",
+			bucket: &storage.Bucket{Location: "EUROPE-WEST1", LocationType: "region"},
+		},
+		{
+			name:     "in a multi-region",
+			bucket:   &storage.Bucket{Location: "EU", LocationType: "multi-region"},
+			expected: true,
+		},
+		{
+			name:     "in a predefined dual-region including a configured region",
+			bucket:   &storage.Bucket{Location: "NAM4", LocationType: "dual-region"},
+			expected: true,
+		},
+		{
+			name:   "in a predefined dual-region of other regions",
+			bucket: &storage.Bucket{Location: "EUR4", LocationType: "dual-region"},
+		},
+		{
+			name: "in a configurable dual-region including a configured region",
+			bucket: &storage.Bucket{Location: "US", LocationType: "dual-region", CustomPlacementConfig: &storage.BucketCustomPlacementConfig{
+				DataLocations: []string{"US-EAST1", "US-EAST4"},
+			}},
+			expected: true,
+		},
+		{
+			name: "in a configurable dual-region of other regions",
+			bucket: &storage.Bucket{Location: "EU", LocationType: "dual-region", CustomPlacementConfig: &storage.BucketCustomPlacementConfig{
+				DataLocations: []string{"EUROPE-WEST1", "EUROPE-WEST4"},
+			}},
+		},
+		{
+			name:     "in an unknown dual-region",
+			bucket:   &storage.Bucket{Location: "EUR9", LocationType: "dual-region"},
+			expected: true,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, wantGCSBucketLocation(tt.bucket, strings.ToLower(tt.bucket.Location), regions))
+		})
+	}
+}
@@ -35,6 +35,26 @@ func getResourceNameFromURL(res string) string {
 	return s[len(s)-1]
 }
 
+// name is in the form of projects/my_project/locations/us-central1/...
+func getProjectFromResourceName(name string) string {
+	return getResourceNameSegment(name, "projects")
+}
+
+// name is in the form of projects/my_project/locations/us-central1/...
+func getLocationFromResourceName(name string) string {
+	return getResourceNameSegment(name, "locations")
+}
+
+func getResourceNameSegment(name string, collection string) string {
+	s := strings.Split(name, "/")
+	for i := 0; i < len(s)-1; i++ {
+		if s[i] == collection {
+			return s[i+1]
+		}
+	}
+	return ""
+}
+
 func getRegionFromZoneURL(zone string) string {
 	z := getResourceNameFromURL(zone)
 	r := strings.Split(z, "-")
@@ -58,7 +78,13 @@ func getSubnetIdFromLink(selfLink string, subnetAssetCache *freelru.LRU[string,
 }
 
 func getNetSelfLinkFromNetConfig(networkConfig *containerpb.NetworkConfig) string {
-	network := networkConfig.Network
+	return getNetSelfLinkFromPath(networkConfig.Network)
+}
+
+// network is in the form of projects/my_project/global/networks/my_network,
+// optionally with a leading slash
+func getNetSelfLinkFromPath(network string) string {
+	network = strings.TrimPrefix(network, "/")
 	if len(network) > 0 {
 		return "https://www.googleapis.com/compute/v1/" + network
 	}
@@ -66,6 +92,21 @@ func getNetSelfLinkFromNetConfig(networkConfig *containerpb.NetworkConfig) strin
 	return ""
 }
 
+// network is either a plain network name, living in the given project, or a network path
+func getNetSelfLinkFromNetworkName(project string, network string) string {
+	if strings.HasPrefix(network, "https://") {
+		return network
+	}
+	if strings.Contains(network, "/") {
+		return getNetSelfLinkFromPath(network)
+	}
+	if len(network) > 0 {
+		return getNetSelfLinkFromPath("projects/" + project + "/global/networks/" + network)
+	}
+
+	return ""
+}
+
 func hashStringXXHASH(s string) uint32 {
 	return uint32(xxhash.Sum64String(s))
 }
@@ -135,6 +135,115 @@ func TestGetNetSelfLinkFromNetConfig(t *testing.T) {
 	}
 }
 
+func TestGetNetSelfLinkFromPath(t *testing.T) {
+
+	for _, tt := range []struct {
+		name string
+
+		network          string
+		expectedSelfLink string
+	}{
+		{
+			name: "with a non existing network",
+
+			network:          "",
+			expectedSelfLink: "",
+		},
+		{
+			name: "with a relative network path",
+
+			network:          "projects/my_project/global/networks/my_network",
+			expectedSelfLink: "https://www.googleapis.com/compute/v1/projects/my_project/global/networks/my_network",
+		},
+		{
+			name: "with a network path starting with a slash",
+
+			network:          "/projects/my_project/global/networks/my_network",
+			expectedSelfLink: "https://www.googleapis.com/compute/v1/projects/my_project/global/networks/my_network",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expectedSelfLink, getNetSelfLinkFromPath(tt.network))
+		})
+	}
+}
+
+func TestGetNetSelfLinkFromNetworkName(t *testing.T) {
+
+	for _, tt := range []struct {
+		name string
+
+		network          string
+		expectedSelfLink string
+	}{
+		{
+			name: "with a non existing network",
+
+			network:          "",
+			expectedSelfLink: "",
+		},
+		{
+			name: "with a network name",
+
+			network:          "my_network",
+			expectedSelfLink: "https://www.googleapis.com/compute/v1/projects/my_project/global/networks/my_network",
+		},
+		{
+			name: "with a network path",
+
+			network:          "projects/my_host_project/global/networks/my_network",
+			expectedSelfLink: "https://www.googleapis.com/compute/v1/projects/my_host_project/global/networks/my_network",
+		},
+		{
+			name: "with a network self link",
+
+			network:          "https://www.googleapis.com/compute/v1/projects/my_host_project/global/networks/my_network",
+			expectedSelfLink: "https://www.googleapis.com/compute/v1/projects/my_host_project/global/networks/my_network",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expectedSelfLink, getNetSelfLinkFromNetworkName("my_project", tt.network))
+		})
+	}
+}
+
+func TestGetResourceNameSegments(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+
+		resourceName     string
+		expectedProject  string
+		expectedLocation string
+	}{
+		{
+			name: "with a Cloud Run service",
+
+			resourceName:     "projects/my_project/locations/us-central1/services/my_service",
+			expectedProject:  "my_project",
+			expectedLocation: "us-central1",
+		},
+		{
+			name: "with a resource name without location",
+
+			resourceName:     "projects/my_project/global/networks/my_network",
+			expectedProject:  "my_project",
+			expectedLocation: "",
+		},
+		{
+			name: "with an empty resource name",
+
+			resourceName:     "",
+			expectedProject:  "",
+			expectedLocation: "",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expectedProject, getProjectFromResourceName(tt.resourceName))
+			assert.Equal(t, tt.expectedLocation, getLocationFromResourceName(tt.resourceName))
+		})
+	}
+}
+
 func TestWantRegion(t *testing.T) {
 
 	for _, tt := range []struct {
@@ -19,12 +19,14 @@ package gcp
 
 import (
 	"context"
+	"fmt"
 	"github.com/googleapis/gax-go/v2"
 	"strconv"
 
 	compute "cloud.google.com/go/compute/apiv1"
 	"cloud.google.com/go/compute/apiv1/computepb"
 	"google.golang.org/api/iterator"
+	"google.golang.org/api/vpcaccess/v1"
 
 	"github.com/elastic/assetbeat/input/internal"
 	stateless "github.com/elastic/beats/v7/filebeat/input/v2/input-stateless"
@@ -51,6 +53,10 @@ type listSubnetworkAPIClient struct {
 	AggregatedList func(ctx context.Context, req *computepb.AggregatedListSubnetworksRequest, opts ...gax.CallOption) AggregatedSubnetworkIterator
 }
 
+type getVPCConnectorAPIClient struct {
+	Get func(ctx context.Context, name string) (*vpcaccess.Connector, error)
+}
+
 type vpc struct {
 	ID      string
 	Name    string
@@ -175,3 +181,24 @@ func getAllSubnets(ctx context.Context, cfg config, subnetAssetCache *freelru.LR
 	return subnets, nil
 
 }
+
+// connector is in the form of projects/my_project/locations/us-central1/connectors/my_connector.
+// Resolved VPC IDs are stored in connectorVpcs, so that each connector is only retrieved once per collection.
+func getVpcIdFromConnector(ctx context.Context, connector string, connectorVpcs map[string]string, vpcAssetCache *freelru.LRU[string, *vpc], client getVPCConnectorAPIClient) (string, error) {
+	if id, ok := connectorVpcs[connector]; ok {
+		return id, nil
+	}
+	c, err := client.Get(ctx, connector)
+	if err != nil {
+		return "", fmt.Errorf("error retrieving VPC connector %s: %w", connector, err)
+	}
+	// the connector network is a plain name, living either in the project of the
+	// connector subnet (for Shared VPCs) or in the project of the connector itself
+	project := getProjectFromResourceName(connector)
+	if c.Subnet != nil && c.Subnet.ProjectId != "" {
+		project = c.Subnet.ProjectId
+	}
+	id := getVpcIdFromLink(getNetSelfLinkFromNetworkName(project, c.Network), vpcAssetCache)
+	connectorVpcs[connector] = id
+	return id, nil
+}
@@ -19,6 +19,7 @@ package gcp
 
 import (
 	"context"
+	"errors"
 	"github.com/elastic/assetbeat/input/internal"
 	"testing"
 
@@ -28,6 +29,7 @@ import (
 	"github.com/googleapis/gax-go/v2"
 	"github.com/stretchr/testify/assert"
 	"google.golang.org/api/iterator"
+	"google.golang.org/api/vpcaccess/v1"
 
 	"github.com/elastic/assetbeat/input/testutil"
 	"github.com/elastic/beats/v7/libbeat/beat"
@@ -538,3 +540,80 @@ func TestCollectSubnetAssets(t *testing.T) {
 		})
 	}
 }
+
+func TestGetVpcIdFromConnector(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+
+		connector  string
+		connectors map[string]*vpcaccess.Connector
+		expectedID string
+		expectErr  bool
+	}{
+		{
+			name: "with a connector in the network project",
+
+			connector: "projects/my_project/locations/us-central1/connectors/my_connector",
+			connectors: map[string]*vpcaccess.Connector{
+				"projects/my_project/locations/us-central1/connectors/my_connector": {Network: "my_network"},
+			},
+			expectedID: "1",
+		},
+		{
+			name: "with a connector on a Shared VPC subnet",
+
+			connector: "projects/my_service_project/locations/us-central1/connectors/my_connector",
+			connectors: map[string]*vpcaccess.Connector{
+				"projects/my_service_project/locations/us-central1/connectors/my_connector": {
+					Network: "my_network",
+					Subnet:  &vpcaccess.Subnet{Name: "my_subnet", ProjectId: "my_project"},
+				},
+			},
+			expectedID: "1",
+		},
+		{
+			name: "with a connector on an unknown network",
+
+			connector: "projects/my_project/locations/us-central1/connectors/my_connector",
+			connectors: map[string]*vpcaccess.Connector{
+				"projects/my_project/locations/us-central1/connectors/my_connector": {Network: "other_network"},
+			},
+			expectedID: "",
+		},
+		{
+			name: "with a missing connector",
+
+			connector: "projects/my_project/locations/us-central1/connectors/my_connector",
+			expectErr: true,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int
+			client := getVPCConnectorAPIClient{
+				Get: func(ctx context.Context, name string) (*vpcaccess.Connector, error) {
+					calls++
+					c, ok := tt.connectors[name]
+					if !ok {
+						return nil, errors.New("not found")
+					}
+					return c, nil
+				},
+			}
+			connectorVpcs := make(map[string]string)
+
+			id, err := getVpcIdFromConnector(context.Background(), tt.connector, connectorVpcs, getTestVpcCache(), client)
+			if tt.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedID, id)
+
+			// the connector is only retrieved once
+			id, err = getVpcIdFromConnector(context.Background(), tt.connector, connectorVpcs, getTestVpcCache(), client)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedID, id)
+			assert.Equal(t, 1, calls)
+		})
+	}
+}
@@ -129,6 +129,14 @@ func WithAssetMetadata(value mapstr.M) AssetOption {
 	}
 }
 
+// WithAssetDeleted marks the event as the tombstone of an asset that no longer exists.
+func WithAssetDeleted() AssetOption {
+	return func(e beat.Event) beat.Event {
+		e.Fields["asset.deleted"] = true
+		return e
+	}
+}
+
 func WithNodeData(name string, startTime *metav1.Time) AssetOption {
 	return func(e beat.Event) beat.Event {
 		e.Fields["kubernetes.node.name"] = name
@@ -128,6 +128,17 @@ func TestPublish(t *testing.T) {
 				"asset.metadata.foo": "bar",
 			}, Meta: mapstr.M{"index": GetDefaultIndexName()}},
 		},
+		{
+			name: "with deleted asset",
+			opts: []AssetOption{
+				WithAssetCloudProvider("aws"),
+				WithAssetDeleted(),
+			},
+			expectedEvent: beat.Event{Fields: mapstr.M{
+				"cloud.provider": "aws",
+				"asset.deleted":  true,
+			}, Meta: mapstr.M{"index": GetDefaultIndexName()}},
+		},
 		{
 			name: "with valid node data",
 			opts: []AssetOption{
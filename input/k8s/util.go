@@ -58,10 +58,12 @@ func newhttpFetcher() httpFetcher {
 }
 
 // getInstanceId returns the cloud instance id in case
-// the node runs in one of [aws, gcp] csp.
+// the node runs in one of [aws, gcp, azure] csp.
 // In case of aws the instance id is retrieved from providerId
 // which is in the form of aws:///region/instanceId for not fargate nodes.
 // In case of gcp it is retrieved by the annotation container.googleapis.com/instance_id
+// In case of azure it is the lowercase resource ID of the VM, retrieved from providerId
+// which is in the form of azure:///subscriptions/.../virtualMachineScaleSets/vmss/virtualMachines/0
 // In all other cases empty string is returned
 func getInstanceId(node *kubernetes.Node) string {
 	providerId := node.Spec.ProviderID
@@ -76,6 +78,8 @@ func getInstanceId(node *kubernetes.Node) string {
 	case "gcp":
 		annotations := node.GetAnnotations()
 		return annotations["container.googleapis.com/instance_id"]
+	case "azure":
+		return strings.ToLower(strings.TrimPrefix(providerId, "azure://"))
 	default:
 		return ""
 	}
@@ -99,6 +103,7 @@ func getNodeState(node *kubernetes.Node) string {
 // getCspFromProviderId return the cps for a given providerId string.
 // In case of aws providerId is in the form of aws:///region/instanceId
 // In case of gcp providerId is in the form of  gce://project/region/nodeName
+// In case of azure providerId is in the form of azure:///subscriptions/subscriptionId/resourceGroups/...
 func getCspFromProviderId(providerId string) string {
 	if strings.HasPrefix(providerId, "aws") {
 		return "aws"
@@ -106,6 +111,9 @@ func getCspFromProviderId(providerId string) string {
 	if strings.HasPrefix(providerId, "gce") {
 		return "gcp"
 	}
+	if strings.HasPrefix(providerId, "azure") {
+		return "azure"
+	}
 	return ""
 }
 
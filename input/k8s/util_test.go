@@ -129,6 +129,26 @@ func TestGetInstanceId(t *testing.T) {
 			},
 			output: "5445971517456914360",
 		},
+		{
+			name: "Azure node",
+			input: &v1.Node{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "aks-nodepool1-12345678-vmss000000",
+					UID:  "60988eed-1885-4b63-9fa4-780206969deb",
+				},
+				TypeMeta: metav1.TypeMeta{
+					Kind:       "Node",
+					APIVersion: "v1",
+				},
+				Status: v1.NodeStatus{
+					Addresses: []v1.NodeAddress{{Type: v1.NodeHostName, Address: "aks-nodepool1-12345678-vmss000000"}},
+				},
+				Spec: v1.NodeSpec{
+					ProviderID: "azure:///subscriptions/12cabcb4-86e8-404f-111111111111/resourceGroups/mc_test_aks1_westeurope/providers/Microsoft.Compute/virtualMachineScaleSets/aks-nodepool1-12345678-vmss/virtualMachines/0",
+				},
+			},
+			output: "/subscriptions/12cabcb4-86e8-404f-111111111111/resourcegroups/mc_test_aks1_westeurope/providers/microsoft.compute/virtualmachinescalesets/aks-nodepool1-12345678-vmss/virtualmachines/0",
+		},
 		{
 			name: "No CSP Node (kind)",
 			input: &v1.Node{
@@ -181,6 +201,11 @@ func TestGetCspFromProviderId(t *testing.T) {
 			input:  "gce://elastic-observability/us-central1-c/gke-michaliskatsoulis-te-default-pool-41126842-55kg",
 			output: "gcp",
 		},
+		{
+			name:   "Azure node",
+			input:  "azure:///subscriptions/12cabcb4-86e8-404f-111111111111/resourceGroups/mc_test_aks1_westeurope/providers/Microsoft.Compute/virtualMachineScaleSets/aks-nodepool1-12345678-vmss/virtualMachines/0",
+			output: "azure",
+		},
 		{
 			name:   "No CSP Node (kind)",
 			input:  "kind://docker/kind/kind-worker",
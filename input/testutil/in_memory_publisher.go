@@ -42,3 +42,11 @@ func (p *InMemoryPublisher) Publish(e beat.Event) {
 
 	p.Events = append(p.Events, e)
 }
+
+// PublishedEvents returns a copy of the events published so far, to read them while events are still being published.
+func (p *InMemoryPublisher) PublishedEvents() []beat.Event {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return append([]beat.Event(nil), p.Events...)
+}
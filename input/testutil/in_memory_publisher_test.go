@@ -39,4 +39,5 @@ func TestInMemoryPublisher(t *testing.T) {
 
 	p.Publish(event)
 	assert.Equal(t, 2, len(p.Events))
+	assert.Equal(t, p.Events, p.PublishedEvents())
 }
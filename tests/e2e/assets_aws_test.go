@@ -21,10 +21,16 @@ package e2e
 
 import (
 	"context"
+	"net/url"
+	"os"
 	"sync"
 	"testing"
 	"time"
 
+	awssdk "github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+
 	"github.com/elastic/assetbeat/input/testutil"
 	v2 "github.com/elastic/beats/v7/filebeat/input/v2"
 	stateless "github.com/elastic/beats/v7/filebeat/input/v2/input-stateless"
@@ -73,3 +79,55 @@ func TestAssetsAWS_Run_startsAndStopsTheInput(t *testing.T) {
 		// Waitgroup finished in time, nothing to do
 	}
 }
+
+// TestAssetsAWS_Run_consumesSQSEvents needs an SQS-compatible queue, such as an ElasticMQ one,
+// whose URL is set in ELASTICMQ_QUEUE_URL.
+func TestAssetsAWS_Run_consumesSQSEvents(t *testing.T) {
+	queueURL := os.Getenv("ELASTICMQ_QUEUE_URL")
+	if queueURL == "" {
+		t.Skip("ELASTICMQ_QUEUE_URL is not set")
+	}
+	u, err := url.Parse(queueURL)
+	assert.NoError(t, err)
+
+	sqsClient := sqs.New(sqs.Options{
+		Region:           "eu-west-1",
+		Credentials:      credentials.NewStaticCredentialsProvider("x", "x", ""),
+		EndpointResolver: sqs.EndpointResolverFromURL(u.Scheme + "://" + u.Host),
+	})
+	_, err = sqsClient.SendMessage(context.Background(), &sqs.SendMessageInput{
+		QueueUrl:    awssdk.String(queueURL),
+		MessageBody: awssdk.String(`{"source":"aws.ec2","detail-type":"EC2 Instance State-change Notification","account":"11111111111111","region":"eu-west-1","detail":{"instance-id":"i-1111111","state":"terminated"}}`),
+	})
+	assert.NoError(t, err)
+
+	publisher := testutil.NewInMemoryPublisher()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	inputCtx := v2.Context{
+		Logger:      logp.NewLogger("test"),
+		Cancelation: ctx,
+	}
+
+	input, err := aws.Plugin().Manager.(stateless.InputManager).Configure(config.MustNewConfigFrom(map[string]interface{}{
+		"regions":           []string{"eu-west-1"},
+		"access_key_id":     "x",
+		"secret_access_key": "x",
+		"asset_types":       []string{"aws.ec2.instance"},
+		"sqs_queue_url":     queueURL,
+	}))
+	assert.NoError(t, err)
+
+	go func() {
+		_ = input.Run(inputCtx, publisher)
+	}()
+
+	assert.Eventually(t, func() bool {
+		for _, e := range publisher.PublishedEvents() {
+			if e.Fields["asset.ean"] == "host:i-1111111" && e.Fields["asset.deleted"] == true {
+				return true
+			}
+		}
+		return false
+	}, 30*time.Second, 100*time.Millisecond)
+}